@@ -0,0 +1,163 @@
+// Package aio provides the asynchronous file I/O backed by io_uring on Linux.
+// It is intended for the code paths where the mapping is impractical, e.g. the huge cold files,
+// the data which is read by the ring may be wrapped by segment.New or transaction.Begin like the mapped memory.
+// The ErrNotSupported returns on other platforms and if io_uring is disabled or filtered out.
+package aio
+
+import (
+	"io"
+	"math"
+)
+
+// op is the operation of the request.
+type op uint8
+
+const (
+	// opRead reads the file into the buffer.
+	opRead op = iota
+	// opWrite writes the buffer to the file.
+	opWrite
+	// opFsync commits the file to the stable storage.
+	opFsync
+)
+
+// Request is the submitted asynchronous request.
+type Request struct {
+	// done specifies the channel which is closed when the request is completed.
+	done chan struct{}
+	// callback specifies the function which is called when the request is completed.
+	callback func(n int, err error)
+	// buf specifies the buffer of the request which is kept alive until the completion.
+	buf []byte
+	// n specifies the number of bytes transferred.
+	n int
+	// err specifies the error of the request.
+	err error
+}
+
+// Done returns the channel which is closed when the request is completed.
+func (req *Request) Done() <-chan struct{} {
+	return req.done
+}
+
+// Wait waits for the request to be completed and returns the number of bytes transferred and the error if any.
+func (req *Request) Wait() (int, error) {
+	<-req.done
+	return req.n, req.err
+}
+
+// complete completes the request with the given result.
+// The callback is called before the done channel is closed.
+func (req *Request) complete(n int, err error) {
+	req.n, req.err = n, err
+	req.buf = nil
+	if req.callback != nil {
+		req.callback(n, err)
+	}
+	close(req.done)
+}
+
+// ReadAt submits the request to read len(buf) bytes at the given offset from start of the given file.
+// Like pread, the request may complete with fewer bytes and the zero bytes are read at the end of the file.
+// The buffer must not be accessed until the request is completed.
+// The given callback, if it is not nil, is called by the completion goroutine, so it must not block.
+func (r *Ring) ReadAt(fd uintptr, buf []byte, offset int64, callback func(n int, err error)) (*Request, error) {
+	return r.submitRequest(opRead, fd, buf, offset, callback)
+}
+
+// WriteAt submits the request to write len(buf) bytes at the given offset from start of the given file.
+// Like pwrite, the request may complete with fewer bytes.
+// The buffer must not be modified until the request is completed.
+// The given callback, if it is not nil, is called by the completion goroutine, so it must not block.
+func (r *Ring) WriteAt(fd uintptr, buf []byte, offset int64, callback func(n int, err error)) (*Request, error) {
+	return r.submitRequest(opWrite, fd, buf, offset, callback)
+}
+
+// Fsync submits the request to commit the given file to the stable storage.
+// The given callback, if it is not nil, is called by the completion goroutine, so it must not block.
+func (r *Ring) Fsync(fd uintptr, callback func(n int, err error)) (*Request, error) {
+	return r.submitRequest(opFsync, fd, nil, 0, callback)
+}
+
+// submitRequest validates and submits the request.
+func (r *Ring) submitRequest(op op, fd uintptr, buf []byte, offset int64, callback func(n int, err error)) (*Request, error) {
+	if offset < 0 {
+		return nil, ErrBadOffset
+	}
+	if len(buf) > math.MaxInt32 {
+		return nil, ErrBadLength
+	}
+	req := &Request{done: make(chan struct{}), callback: callback, buf: buf}
+	if err := r.submit(req, op, fd, offset); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// File is the file which is accessed synchronously through the ring.
+type File struct {
+	// ring specifies the ring which performs the requests.
+	ring *Ring
+	// fd specifies the descriptor of the file.
+	fd uintptr
+}
+
+// File returns the file of the given descriptor which is accessed synchronously through this ring,
+// so the ring is usable where io.ReaderAt and io.WriterAt are expected like the mapping.
+// The descriptor is not owned by the file and it must stay open while the file is used.
+func (r *Ring) File(fd uintptr) *File {
+	return &File{ring: r, fd: fd}
+}
+
+// ReadAt reads len(buf) bytes at the given offset from start of the file.
+// The io.EOF error will be returned if fewer bytes are read.
+// ReadAt implements the io.ReaderAt interface.
+func (f *File) ReadAt(buf []byte, offset int64) (int, error) {
+	n := 0
+	for n < len(buf) {
+		req, err := f.ring.ReadAt(f.fd, buf[n:], offset+int64(n), nil)
+		if err != nil {
+			return n, err
+		}
+		k, err := req.Wait()
+		n += k
+		if err != nil {
+			return n, err
+		}
+		if k == 0 {
+			return n, io.EOF
+		}
+	}
+	return n, nil
+}
+
+// WriteAt writes len(buf) bytes at the given offset from start of the file.
+// WriteAt implements the io.WriterAt interface.
+func (f *File) WriteAt(buf []byte, offset int64) (int, error) {
+	n := 0
+	for n < len(buf) {
+		req, err := f.ring.WriteAt(f.fd, buf[n:], offset+int64(n), nil)
+		if err != nil {
+			return n, err
+		}
+		k, err := req.Wait()
+		n += k
+		if err != nil {
+			return n, err
+		}
+		if k == 0 {
+			return n, io.ErrShortWrite
+		}
+	}
+	return n, nil
+}
+
+// Sync commits the file to the stable storage.
+func (f *File) Sync() error {
+	req, err := f.ring.Fsync(f.fd, nil)
+	if err != nil {
+		return err
+	}
+	_, err = req.Wait()
+	return err
+}
@@ -0,0 +1,119 @@
+package aio
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// openTestRing opens and returns a new ring.
+func openTestRing(t *testing.T, entries uint32) *Ring {
+	r, err := NewRing(entries)
+	if err == ErrNotSupported {
+		t.Skip("io_uring is not supported")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// openTestFile opens and returns a new empty file.
+func openTestFile(t *testing.T) *os.File {
+	f, err := os.OpenFile(filepath.Join(t.TempDir(), "aio"), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+//------------------------------------------- TEST CASES ---------------------------------------------------------------
+
+// TestRequests tests the asynchronous requests.
+// CASE 1: The callbacks of all requests MUST be called when more requests than entries are submitted.
+// CASE 2: The data written asynchronously MUST be read back.
+// CASE 3: The failed request MUST complete with the error.
+// CASE 4: The ring MUST be closed once.
+func TestRequests(t *testing.T) {
+	r := openTestRing(t, 4)
+	f := openTestFile(t)
+	defer f.Close()
+	const count = 32
+	var wg sync.WaitGroup
+	wg.Add(count)
+	for i := 0; i < count; i++ {
+		buf := bytes.Repeat([]byte{byte(i)}, 16)
+		if _, err := r.WriteAt(f.Fd(), buf, int64(i*16), func(n int, err error) {
+			if err != nil || n != 16 {
+				t.Errorf("write must complete with %d bytes, %d bytes and %v found", 16, n, err)
+			}
+			wg.Done()
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+	req, err := r.Fsync(f.Fd(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := req.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 16)
+	req, err = r.ReadAt(f.Fd(), buf, 5*16, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-req.Done()
+	if n, err := req.Wait(); err != nil || n != 16 || !bytes.Equal(buf, bytes.Repeat([]byte{5}, 16)) {
+		t.Fatalf("read must return the written data, %d bytes %v and %v found", n, buf, err)
+	}
+	req, err = r.ReadAt(^uintptr(0)>>1, buf, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := req.Wait(); err == nil {
+		t.Fatal("request with the bad descriptor must fail")
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != ErrClosed {
+		t.Fatalf("ring must be closed once, %v found", err)
+	}
+	if _, err := r.ReadAt(f.Fd(), buf, 0, nil); err != ErrClosed {
+		t.Fatalf("request must not be submitted to the closed ring, %v found", err)
+	}
+}
+
+// TestFile tests the synchronous file.
+// CASE 1: The file MUST implement io.ReaderAt and io.WriterAt like the mapping.
+// CASE 2: The read beyond the end of the file MUST return io.EOF.
+func TestFile(t *testing.T) {
+	r := openTestRing(t, 8)
+	defer r.Close()
+	f := openTestFile(t)
+	defer f.Close()
+	var file interface {
+		io.ReaderAt
+		io.WriterAt
+	} = r.File(f.Fd())
+	if _, err := file.WriteAt([]byte("HELLO WORLD"), 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.File(f.Fd()).Sync(); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 16)
+	n, err := file.ReadAt(buf, 0)
+	if err != io.EOF {
+		t.Fatalf("read must return io.EOF, %v found", err)
+	}
+	if expected := "\x00\x00\x00HELLO WORLD"; string(buf[:n]) != expected {
+		t.Fatalf("data must be %q, %q found", expected, buf[:n])
+	}
+}
@@ -0,0 +1,15 @@
+package aio
+
+import "fmt"
+
+// ErrClosed is the error which returns when tries to access the closed ring.
+var ErrClosed = fmt.Errorf("aio: ring closed")
+
+// ErrBadLength is the error which returns when the given length is not valid.
+var ErrBadLength = fmt.Errorf("aio: bad length")
+
+// ErrBadOffset is the error which returns when the given offset is not valid.
+var ErrBadOffset = fmt.Errorf("aio: bad offset")
+
+// ErrNotSupported is the error which returns when the asynchronous I/O is not supported by the platform.
+var ErrNotSupported = fmt.Errorf("aio: operation not supported")
@@ -0,0 +1,424 @@
+package aio
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// ioringOffSQRing is the mmap offset of the submission queue ring.
+	ioringOffSQRing = 0
+	// ioringOffCQRing is the mmap offset of the completion queue ring.
+	ioringOffCQRing = 0x8000000
+	// ioringOffSQEs is the mmap offset of the submission queue entries.
+	ioringOffSQEs = 0x10000000
+	// ioringFeatSingleMmap is the feature flag of the rings which are mapped at once.
+	ioringFeatSingleMmap = 1 << 0
+	// ioringEnterGetEvents is the flag to wait for the completions.
+	ioringEnterGetEvents = 1 << 0
+
+	// ioringOpNop is the no-op operation code.
+	ioringOpNop = 0
+	// ioringOpReadv is the vectored read operation code.
+	ioringOpReadv = 1
+	// ioringOpWritev is the vectored write operation code.
+	ioringOpWritev = 2
+	// ioringOpFsync is the fsync operation code.
+	ioringOpFsync = 3
+
+	// closeUserData is the user data of the no-op request which wakes the completion goroutine up at the closing.
+	closeUserData = 0
+
+	// minRetryDelay is the first delay of the retry of io_uring_enter which fails with EAGAIN or EBUSY.
+	minRetryDelay = 10 * time.Microsecond
+	// maxRetryDelay is the maximum delay of the retry which is doubled after each failure.
+	maxRetryDelay = 10 * time.Millisecond
+	// maxRetries is the number of the retries after which EAGAIN or EBUSY is treated as the error.
+	maxRetries = 200
+)
+
+// backoff is the exponentially growing delay of the retries of io_uring_enter
+// while the kernel is short of the resources.
+type backoff struct {
+	// delay specifies the delay of the last retry.
+	delay time.Duration
+	// retries specifies the number of the retries.
+	retries int
+}
+
+// wait sleeps before the next retry and reports whether it is allowed.
+func (b *backoff) wait() bool {
+	if b.retries == maxRetries {
+		return false
+	}
+	b.retries++
+	switch {
+	case b.delay == 0:
+		b.delay = minRetryDelay
+	case b.delay < maxRetryDelay:
+		b.delay *= 2
+	}
+	time.Sleep(b.delay)
+	return true
+}
+
+// reset resets the delay after the successful call.
+func (b *backoff) reset() {
+	b.delay, b.retries = 0, 0
+}
+
+// ioSQRingOffsets is the struct io_sqring_offsets.
+type ioSQRingOffsets struct {
+	head, tail, ringMask, ringEntries, flags, dropped, array, resv1 uint32
+	userAddr                                                        uint64
+}
+
+// ioCQRingOffsets is the struct io_cqring_offsets.
+type ioCQRingOffsets struct {
+	head, tail, ringMask, ringEntries, overflow, cqes, flags, resv1 uint32
+	userAddr                                                        uint64
+}
+
+// ioUringParams is the struct io_uring_params.
+type ioUringParams struct {
+	sqEntries, cqEntries, flags, sqThreadCPU, sqThreadIdle, features, wqFd uint32
+	resv                                                                   [3]uint32
+	sqOff                                                                  ioSQRingOffsets
+	cqOff                                                                  ioCQRingOffsets
+}
+
+// ioUringSQE is the struct io_uring_sqe.
+type ioUringSQE struct {
+	opcode      uint8
+	flags       uint8
+	ioprio      uint16
+	fd          int32
+	off         uint64
+	addr        uint64
+	len         uint32
+	opFlags     uint32
+	userData    uint64
+	bufIndex    uint16
+	personality uint16
+	spliceFdIn  int32
+	addr3       uint64
+	pad         uint64
+}
+
+// ioUringCQE is the struct io_uring_cqe.
+type ioUringCQE struct {
+	userData uint64
+	res      int32
+	flags    uint32
+}
+
+// pending is the submitted request which is not completed yet.
+type pending struct {
+	// req specifies the request.
+	req *Request
+	// op specifies the operation of the request.
+	op op
+	// iov specifies the vector of the buffer which is referenced by the submission queue entry.
+	iov unix.Iovec
+}
+
+// Ring is the io_uring instance which performs the asynchronous requests.
+// The completions are reaped by the dedicated goroutine.
+type Ring struct {
+	// mu protects the submission queue and the pending requests.
+	mu sync.Mutex
+	// fd specifies the descriptor of the io_uring instance.
+	fd int
+	// sqRing specifies the mapped submission queue ring.
+	sqRing []byte
+	// cqRing specifies the mapped completion queue ring, it is the same as sqRing with the single mmap.
+	cqRing []byte
+	// sqes specifies the mapped submission queue entries.
+	sqes []ioUringSQE
+	// sqHead, sqTail, sqMask and sqArray specify the fields of the submission queue ring.
+	sqHead, sqTail, sqMask *uint32
+	sqArray                []uint32
+	// cqHead, cqTail, cqMask and cqes specify the fields of the completion queue ring.
+	cqHead, cqTail, cqMask *uint32
+	cqes                   []ioUringCQE
+	// slots limits the number of the pending requests, so the completion queue never overflows.
+	slots chan struct{}
+	// pending specifies the pending requests by the user data.
+	pending map[uint64]*pending
+	// next specifies the user data of the next request.
+	next uint64
+	// closed specifies whether the ring is closing.
+	closed bool
+	// err specifies the error which stopped the completion goroutine.
+	err error
+	// reaped is closed when the completion goroutine exits.
+	reaped chan struct{}
+}
+
+// NewRing creates and returns a new ring with the given number of the submission queue entries
+// which is rounded up to the power of two by the kernel. It is also the limit of the pending requests,
+// the submission blocks until one of them is completed.
+// The ErrNotSupported returns if io_uring is not available.
+func NewRing(entries uint32) (*Ring, error) {
+	if entries == 0 {
+		return nil, ErrBadLength
+	}
+	var params ioUringParams
+	fd, _, errno := unix.Syscall(unix.SYS_IO_URING_SETUP, uintptr(entries), uintptr(unsafe.Pointer(&params)), 0)
+	switch errno {
+	case 0:
+	case unix.ENOSYS, unix.EPERM, unix.EACCES:
+		// EPERM and EACCES return if io_uring is disabled by the sysctl or the seccomp filter.
+		return nil, ErrNotSupported
+	default:
+		return nil, os.NewSyscallError("io_uring_setup", errno)
+	}
+	r := &Ring{
+		fd:      int(fd),
+		slots:   make(chan struct{}, params.sqEntries),
+		pending: make(map[uint64]*pending),
+		next:    closeUserData + 1,
+		reaped:  make(chan struct{}),
+	}
+	if err := r.mapRings(&params); err != nil {
+		r.unmapRings()
+		return nil, err
+	}
+	go r.reap()
+	return r, nil
+}
+
+// mapRings maps the rings and the submission queue entries of the instance.
+func (r *Ring) mapRings(params *ioUringParams) error {
+	sqSize := int(params.sqOff.array + params.sqEntries*4)
+	cqSize := int(params.cqOff.cqes + params.cqEntries*uint32(unsafe.Sizeof(ioUringCQE{})))
+	single := params.features&ioringFeatSingleMmap != 0
+	if single && cqSize > sqSize {
+		sqSize = cqSize
+	}
+	prot, flags := unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED|unix.MAP_POPULATE
+	var err error
+	if r.sqRing, err = unix.Mmap(r.fd, ioringOffSQRing, sqSize, prot, flags); err != nil {
+		return os.NewSyscallError("mmap", err)
+	}
+	r.cqRing = r.sqRing
+	if !single {
+		if r.cqRing, err = unix.Mmap(r.fd, ioringOffCQRing, cqSize, prot, flags); err != nil {
+			r.cqRing = nil
+			return os.NewSyscallError("mmap", err)
+		}
+	}
+	sqeSize := int(params.sqEntries) * int(unsafe.Sizeof(ioUringSQE{}))
+	sqes, err := unix.Mmap(r.fd, ioringOffSQEs, sqeSize, prot, flags)
+	if err != nil {
+		return os.NewSyscallError("mmap", err)
+	}
+	r.sqes = unsafe.Slice((*ioUringSQE)(unsafe.Pointer(&sqes[0])), params.sqEntries)
+	sq, cq := &params.sqOff, &params.cqOff
+	r.sqHead = (*uint32)(unsafe.Pointer(&r.sqRing[sq.head]))
+	r.sqTail = (*uint32)(unsafe.Pointer(&r.sqRing[sq.tail]))
+	r.sqMask = (*uint32)(unsafe.Pointer(&r.sqRing[sq.ringMask]))
+	r.sqArray = unsafe.Slice((*uint32)(unsafe.Pointer(&r.sqRing[sq.array])), params.sqEntries)
+	r.cqHead = (*uint32)(unsafe.Pointer(&r.cqRing[cq.head]))
+	r.cqTail = (*uint32)(unsafe.Pointer(&r.cqRing[cq.tail]))
+	r.cqMask = (*uint32)(unsafe.Pointer(&r.cqRing[cq.ringMask]))
+	r.cqes = unsafe.Slice((*ioUringCQE)(unsafe.Pointer(&r.cqRing[cq.cqes])), params.cqEntries)
+	return nil
+}
+
+// unmapRings unmaps the rings and closes the instance.
+func (r *Ring) unmapRings() {
+	if r.sqes != nil {
+		_ = unix.Munmap(unsafe.Slice((*byte)(unsafe.Pointer(&r.sqes[0])), len(r.sqes)*int(unsafe.Sizeof(ioUringSQE{}))))
+	}
+	if r.cqRing != nil && &r.cqRing[0] != &r.sqRing[0] {
+		_ = unix.Munmap(r.cqRing)
+	}
+	if r.sqRing != nil {
+		_ = unix.Munmap(r.sqRing)
+	}
+	r.sqes, r.sqRing, r.cqRing = nil, nil, nil
+	_ = unix.Close(r.fd)
+}
+
+// submit submits the given request.
+func (r *Ring) submit(req *Request, op op, fd uintptr, offset int64) error {
+	r.slots <- struct{}{}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed || r.err != nil {
+		<-r.slots
+		if r.err != nil {
+			return r.err
+		}
+		return ErrClosed
+	}
+	p := &pending{req: req, op: op}
+	sqe := ioUringSQE{fd: int32(fd), off: uint64(offset), userData: r.next}
+	switch op {
+	case opRead, opWrite:
+		sqe.opcode = ioringOpReadv
+		if op == opWrite {
+			sqe.opcode = ioringOpWritev
+		}
+		if len(req.buf) > 0 {
+			p.iov.Base = &req.buf[0]
+		}
+		p.iov.SetLen(len(req.buf))
+		sqe.addr = uint64(uintptr(unsafe.Pointer(&p.iov)))
+		sqe.len = 1
+	case opFsync:
+		sqe.opcode = ioringOpFsync
+	}
+	if err := r.enqueue(&sqe); err != nil {
+		<-r.slots
+		return err
+	}
+	r.pending[r.next] = p
+	r.next++
+	return nil
+}
+
+// enqueue places the given entry into the submission queue and submits it.
+// The submission is retried with the growing delay while the kernel is short of the resources.
+// If the entry is not consumed by the kernel, it is withdrawn.
+func (r *Ring) enqueue(sqe *ioUringSQE) error {
+	tail := atomic.LoadUint32(r.sqTail)
+	index := tail & *r.sqMask
+	r.sqes[index] = *sqe
+	r.sqArray[index] = index
+	atomic.StoreUint32(r.sqTail, tail+1)
+	var retry backoff
+	for {
+		_, _, errno := unix.Syscall6(unix.SYS_IO_URING_ENTER, uintptr(r.fd), 1, 0, 0, 0, 0)
+		switch errno {
+		case 0:
+			return nil
+		case unix.EINTR:
+			if atomic.LoadUint32(r.sqHead) != tail {
+				return nil
+			}
+			continue
+		case unix.EAGAIN, unix.EBUSY:
+			if atomic.LoadUint32(r.sqHead) != tail {
+				return nil
+			}
+			if retry.wait() {
+				continue
+			}
+		}
+		if atomic.LoadUint32(r.sqHead) != tail {
+			return nil
+		}
+		atomic.StoreUint32(r.sqTail, tail)
+		return os.NewSyscallError("io_uring_enter", errno)
+	}
+}
+
+// reap waits for the completions and completes the pending requests
+// until the ring is closed and there are no pending requests.
+// The wait is retried with the growing delay while the kernel is short of the resources.
+func (r *Ring) reap() {
+	defer close(r.reaped)
+	closing := false
+	var retry backoff
+	for !closing || r.pendingCount() > 0 {
+		_, _, errno := unix.Syscall6(unix.SYS_IO_URING_ENTER, uintptr(r.fd), 0, 1, ioringEnterGetEvents, 0, 0)
+		switch errno {
+		case 0:
+			retry.reset()
+		case unix.EINTR:
+		case unix.EAGAIN, unix.EBUSY:
+			if !retry.wait() {
+				r.abort(os.NewSyscallError("io_uring_enter", errno))
+				return
+			}
+		default:
+			r.abort(os.NewSyscallError("io_uring_enter", errno))
+			return
+		}
+		head := atomic.LoadUint32(r.cqHead)
+		for tail := atomic.LoadUint32(r.cqTail); head != tail; head++ {
+			cqe := r.cqes[head&*r.cqMask]
+			if cqe.userData == closeUserData {
+				closing = true
+				continue
+			}
+			r.complete(cqe.userData, cqe.res)
+		}
+		atomic.StoreUint32(r.cqHead, head)
+	}
+}
+
+// pendingCount returns the number of the pending requests.
+func (r *Ring) pendingCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pending)
+}
+
+// complete completes the pending request of the given user data with the given result.
+func (r *Ring) complete(userData uint64, res int32) {
+	r.mu.Lock()
+	p := r.pending[userData]
+	delete(r.pending, userData)
+	r.mu.Unlock()
+	if p == nil {
+		return
+	}
+	<-r.slots
+	if res < 0 {
+		name := "read"
+		switch p.op {
+		case opWrite:
+			name = "write"
+		case opFsync:
+			name = "fsync"
+		}
+		p.req.complete(0, os.NewSyscallError(name, unix.Errno(-res)))
+		return
+	}
+	p.req.complete(int(res), nil)
+}
+
+// abort completes all pending requests with the given error and refuses the new ones.
+func (r *Ring) abort(err error) {
+	r.mu.Lock()
+	r.err = err
+	requests := r.pending
+	r.pending = make(map[uint64]*pending)
+	r.mu.Unlock()
+	for _, p := range requests {
+		<-r.slots
+		p.req.complete(0, err)
+	}
+}
+
+// Close waits for the pending requests to be completed and closes the ring.
+// Close implements the io.Closer interface.
+func (r *Ring) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return ErrClosed
+	}
+	r.closed = true
+	var err error
+	if r.err == nil {
+		// The no-op request wakes the completion goroutine up.
+		err = r.enqueue(&ioUringSQE{opcode: ioringOpNop, userData: closeUserData})
+	}
+	r.mu.Unlock()
+	if err != nil {
+		// The completion goroutine may still wait for the pending requests, so the rings are left mapped.
+		return err
+	}
+	<-r.reaped
+	r.unmapRings()
+	return nil
+}
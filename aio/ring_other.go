@@ -0,0 +1,23 @@
+//go:build !linux
+
+package aio
+
+// Ring is the ring of the asynchronous requests.
+// It is not supported on this platform.
+type Ring struct{}
+
+// NewRing returns ErrNotSupported since io_uring is available on Linux only.
+func NewRing(entries uint32) (*Ring, error) {
+	return nil, ErrNotSupported
+}
+
+// submit returns ErrNotSupported.
+func (r *Ring) submit(req *Request, op op, fd uintptr, offset int64) error {
+	return ErrNotSupported
+}
+
+// Close returns ErrNotSupported.
+// Close implements the io.Closer interface.
+func (r *Ring) Close() error {
+	return ErrNotSupported
+}
@@ -0,0 +1,390 @@
+// Biogen generates the zero-reflection typed accessors over segment.Segment for the annotated structs.
+//
+// The layout of the struct is the same as of segment.ReadStruct: the fields are packed without padding
+// and may be placed by the bio struct tags, see segment.TagName. For the struct T and its field F
+// the constants TFOffset and TSize and the functions
+//
+//	func GetTF(seg *segment.Segment, offset int64) (F's type, error)
+//	func SetTF(seg *segment.Segment, offset int64, v F's type) error
+//
+// are generated, where the offset is the offset of the record from start of the segment.
+// The fields must be exported and of the boolean, the sized number or the byte array types,
+// the fields which are excluded by the "-" tag may be unexported. The fields without the be or le option
+// are encoded in the byte order of the segment. The accessors read and write the fields
+// by Segment.ReadAt and Segment.WriteAt, so they are reported to the race detector as the reads and the writes.
+//
+// Usage:
+//
+//	//go:generate biogen -type Header,Entry
+//
+// The accessors are written to the file named after the first type with the _bio.go suffix
+// in the package directory unless the -output flag is given.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of the struct type names, required")
+	output := flag.String("output", "", "output file name, default <type>_bio.go")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: biogen -type T[,T...] [-output file] [directory]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *typeNames == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+	types := strings.Split(*typeNames, ",")
+	src, err := generateDir(dir, types)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	name := *output
+	if name == "" {
+		name = filepath.Join(dir, strings.ToLower(types[0])+"_bio.go")
+	}
+	if err := os.WriteFile(name, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// generateDir parses the Go files of the package in the given directory except the tests
+// and generates the accessors of the given types.
+func generateDir(dir string, types []string) ([]byte, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range names {
+		if strings.HasSuffix(name, "_test.go") || strings.HasSuffix(name, "_bio.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("biogen: no Go files in %s", dir)
+	}
+	return generate(files, types)
+}
+
+// field is the generated field.
+type field struct {
+	// name specifies the name of the field.
+	name string
+	// typ specifies the Go type of the field.
+	typ string
+	// offset specifies the offset of the field in bytes from start of the struct.
+	offset int64
+	// size specifies the number of bytes which encode the field.
+	size int64
+	// width specifies the number of bytes which encode the integer field or zero for the natural size.
+	width int64
+	// order specifies the byte order of the field: "be", "le" or empty for the byte order of the segment.
+	order string
+}
+
+// typeSizes specifies the sizes of the supported basic types in bytes.
+var typeSizes = map[string]int64{
+	"bool": 1, "int8": 1, "uint8": 1, "byte": 1,
+	"int16": 2, "uint16": 2,
+	"int32": 4, "uint32": 4, "float32": 4,
+	"int64": 8, "uint64": 8, "float64": 8, "complex64": 8,
+	"complex128": 16,
+}
+
+// generate generates the accessors of the given types from the given files of the same package.
+func generate(files []*ast.File, types []string) ([]byte, error) {
+	specs := make(map[string]*ast.StructType)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if st, ok := ts.Type.(*ast.StructType); ok {
+					specs[ts.Name.Name] = st
+				}
+			}
+		}
+	}
+	var body bytes.Buffer
+	imports := map[string]bool{"github.com/alexeymaximov/go-bio/segment": true}
+	for _, name := range types {
+		st, ok := specs[name]
+		if !ok {
+			return nil, fmt.Errorf("biogen: struct type %s not found", name)
+		}
+		fields, size, err := layout(name, st)
+		if err != nil {
+			return nil, err
+		}
+		writeType(&body, name, fields, size, imports)
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by biogen -type %s; DO NOT EDIT.\n\n", strings.Join(types, ","))
+	fmt.Fprintf(&buf, "package %s\n\nimport (\n", files[0].Name.Name)
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	// The third-party imports are grouped after the standard ones.
+	sort.Slice(paths, func(i, j int) bool {
+		iStd, jStd := !strings.Contains(paths[i], "."), !strings.Contains(paths[j], ".")
+		if iStd != jStd {
+			return iStd
+		}
+		return paths[i] < paths[j]
+	})
+	for i, path := range paths {
+		if i > 0 && strings.Contains(path, ".") && !strings.Contains(paths[i-1], ".") {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "\t%q\n", path)
+	}
+	buf.WriteString(")\n")
+	buf.Write(body.Bytes())
+	return format.Source(buf.Bytes())
+}
+
+// layout returns the fields and the size of the given struct type.
+func layout(name string, st *ast.StructType) ([]field, int64, error) {
+	var fields []field
+	var next, size int64
+	for _, f := range st.Fields.List {
+		var tag string
+		if f.Tag != nil {
+			unquoted, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, 0, err
+			}
+			tag = reflect.StructTag(unquoted).Get("bio")
+		}
+		if tag == "-" {
+			continue
+		}
+		typ, natural, err := fieldType(f.Type)
+		if err != nil {
+			return nil, 0, fmt.Errorf("biogen: %s: %v", name, err)
+		}
+		if len(f.Names) == 0 {
+			return nil, 0, fmt.Errorf("biogen: %s: embedded field is not supported", name)
+		}
+		for _, ident := range f.Names {
+			if ident.Name != "_" && !ident.IsExported() {
+				return nil, 0, fmt.Errorf("biogen: %s.%s: unexported field is not supported", name, ident.Name)
+			}
+			fd := field{name: ident.Name, typ: typ, offset: next, size: natural}
+			reserved := natural
+			if tag != "" {
+				for _, option := range strings.Split(tag, ",") {
+					key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+					switch key {
+					default:
+						return nil, 0, fmt.Errorf("biogen: %s.%s: bad tag option %q", name, ident.Name, option)
+					case "be", "le":
+						fd.order = key
+					case "off", "len":
+						n, err := strconv.ParseInt(value, 10, 64)
+						if err != nil || n < 0 {
+							return nil, 0, fmt.Errorf("biogen: %s.%s: bad tag option %q", name, ident.Name, option)
+						}
+						if key == "off" {
+							fd.offset = n
+							continue
+						}
+						switch {
+						case isInteger(typ):
+							if n < 1 || n > 8 {
+								return nil, 0, fmt.Errorf("biogen: %s.%s: bad integer length %d", name, ident.Name, n)
+							}
+							fd.width, fd.size, reserved = n, n, n
+						case n < natural:
+							return nil, 0, fmt.Errorf("biogen: %s.%s: length %d is less than %d", name, ident.Name, n, natural)
+						default:
+							reserved = n
+						}
+					}
+				}
+			}
+			if fd.width == natural {
+				fd.width = 0
+			}
+			next = fd.offset + reserved
+			if next > size {
+				size = next
+			}
+			if ident.Name != "_" {
+				fields = append(fields, fd)
+			}
+		}
+	}
+	return fields, size, nil
+}
+
+// fieldType returns the Go type and the size of the given field type expression.
+func fieldType(expr ast.Expr) (string, int64, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if size, ok := typeSizes[t.Name]; ok {
+			return t.Name, size, nil
+		}
+	case *ast.ArrayType:
+		elem, ok := t.Elt.(*ast.Ident)
+		lit, isLit := t.Len.(*ast.BasicLit)
+		if ok && isLit && lit.Kind == token.INT && (elem.Name == "byte" || elem.Name == "uint8") {
+			n, err := strconv.ParseInt(lit.Value, 0, 64)
+			if err == nil {
+				return fmt.Sprintf("[%d]%s", n, elem.Name), n, nil
+			}
+		}
+	}
+	var buf bytes.Buffer
+	_ = format.Node(&buf, token.NewFileSet(), expr)
+	return "", 0, fmt.Errorf("type %s is not supported", buf.String())
+}
+
+// isInteger returns whether the given Go type is the integer one.
+func isInteger(typ string) bool {
+	return strings.HasPrefix(typ, "int") || strings.HasPrefix(typ, "uint") || typ == "byte"
+}
+
+// writeType writes the constants and the accessors of the given struct type.
+func writeType(w *bytes.Buffer, name string, fields []field, size int64, imports map[string]bool) {
+	fmt.Fprintf(w, "\n// Offsets of the %s fields and its size in bytes.\nconst (\n", name)
+	for _, f := range fields {
+		fmt.Fprintf(w, "\t%s%sOffset = %d\n", name, f.name, f.offset)
+	}
+	fmt.Fprintf(w, "\t%sSize = %d\n)\n", name, size)
+	for _, f := range fields {
+		order := "seg.ByteOrder()"
+		switch f.order {
+		case "be":
+			order = "binary.BigEndian"
+			imports["encoding/binary"] = true
+		case "le":
+			order = "binary.LittleEndian"
+			imports["encoding/binary"] = true
+		}
+		if strings.HasPrefix(f.typ, "float") || strings.HasPrefix(f.typ, "complex") {
+			imports["math"] = true
+		}
+		fn := name + f.name
+		fmt.Fprintf(w, "\n// Get%s returns the %s field of %s at the given offset from the given segment.\n", fn, f.name, name)
+		fmt.Fprintf(w, "func Get%s(seg *segment.Segment, offset int64) (%s, error) {\n", fn, f.typ)
+		fmt.Fprintf(w, "\tvar v %s\n", f.typ)
+		fmt.Fprintf(w, "\tvar buf [%d]byte\n\tb := buf[:]\n", f.size)
+		fmt.Fprintf(w, "\tif _, err := seg.ReadAt(b, offset+%sOffset); err != nil {\n\t\treturn v, err\n\t}\n", fn)
+		w.WriteString(decoder(f, order))
+		fmt.Fprintf(w, "\treturn v, nil\n}\n")
+		fmt.Fprintf(w, "\n// Set%s writes the %s field of %s at the given offset to the given segment.\n", fn, f.name, name)
+		fmt.Fprintf(w, "func Set%s(seg *segment.Segment, offset int64, v %s) error {\n", fn, f.typ)
+		fmt.Fprintf(w, "\tvar buf [%d]byte\n\tb := buf[:]\n", f.size)
+		w.WriteString(encoder(f, order))
+		fmt.Fprintf(w, "\t_, err := seg.WriteAt(b, offset+%sOffset)\n\treturn err\n}\n", fn)
+	}
+}
+
+// littleEndian is the statement which reports whether the byte order of the segment is the little-endian one
+// for the integers which are encoded in the inherited byte order with the len option.
+const littleEndian = "\tlittle := seg.ByteOrder().Uint16([]byte{1, 0}) == 1\n"
+
+// decoder returns the statements which decode the byte slice b into the value v of the given field.
+func decoder(f field, order string) string {
+	bits := strconv.FormatInt(8*f.size, 10)
+	switch {
+	case f.typ == "bool":
+		return "\tv = b[0] != 0\n"
+	case strings.HasPrefix(f.typ, "["):
+		return "\tcopy(v[:], b)\n"
+	case f.width != 0:
+		loop := "\tfor _, c := range b {\n\t\tu = u<<8 | uint64(c)\n\t}\n"
+		switch f.order {
+		case "le":
+			loop = "\tfor i := len(b) - 1; i >= 0; i-- {\n\t\tu = u<<8 | uint64(b[i])\n\t}\n"
+		case "":
+			loop = littleEndian + "\tfor i := range b {\n\t\tc := b[i]\n\t\tif little {\n\t\t\tc = b[len(b)-1-i]\n\t\t}\n" +
+				"\t\tu = u<<8 | uint64(c)\n\t}\n"
+		}
+		s := "\tvar u uint64\n" + loop
+		if strings.HasPrefix(f.typ, "int") {
+			shift := strconv.FormatInt(64-8*f.width, 10)
+			return s + fmt.Sprintf("\tv = %s(int64(u<<%s) >> %s)\n", f.typ, shift, shift)
+		}
+		return s + fmt.Sprintf("\tv = %s(u)\n", f.typ)
+	case f.typ == "uint8" || f.typ == "byte":
+		return "\tv = b[0]\n"
+	case f.size == 1:
+		return fmt.Sprintf("\tv = %s(b[0])\n", f.typ)
+	case f.typ == "float32" || f.typ == "float64":
+		return fmt.Sprintf("\tv = math.Float%sfrombits(%s.Uint%s(b))\n", bits, order, bits)
+	case f.typ == "complex64" || f.typ == "complex128":
+		half := strconv.FormatInt(4*f.size, 10)
+		return fmt.Sprintf("\tv = complex(math.Float%sfrombits(%s.Uint%s(b)), math.Float%sfrombits(%s.Uint%s(b[%d:])))\n",
+			half, order, half, half, order, half, f.size/2)
+	}
+	if f.typ == "uint"+bits {
+		return fmt.Sprintf("\tv = %s.Uint%s(b)\n", order, bits)
+	}
+	return fmt.Sprintf("\tv = %s(%s.Uint%s(b))\n", f.typ, order, bits)
+}
+
+// encoder returns the statements which encode the value v of the given field into the byte slice b.
+func encoder(f field, order string) string {
+	bits := strconv.FormatInt(8*f.size, 10)
+	switch {
+	case f.typ == "bool":
+		return "\tb[0] = 0\n\tif v {\n\t\tb[0] = 1\n\t}\n"
+	case strings.HasPrefix(f.typ, "["):
+		return "\tcopy(b, v[:])\n"
+	case f.width != 0:
+		switch f.order {
+		case "le":
+			return "\tu := uint64(v)\n\tfor i := range b {\n\t\tb[i] = byte(u)\n\t\tu >>= 8\n\t}\n"
+		case "":
+			return littleEndian + "\tu := uint64(v)\n\tfor i := len(b) - 1; i >= 0; i-- {\n" +
+				"\t\tif little {\n\t\t\tb[len(b)-1-i] = byte(u)\n\t\t} else {\n\t\t\tb[i] = byte(u)\n\t\t}\n\t\tu >>= 8\n\t}\n"
+		}
+		return "\tu := uint64(v)\n\tfor i := len(b) - 1; i >= 0; i-- {\n\t\tb[i] = byte(u)\n\t\tu >>= 8\n\t}\n"
+	case f.size == 1:
+		return "\tb[0] = byte(v)\n"
+	case f.typ == "float32" || f.typ == "float64":
+		return fmt.Sprintf("\t%s.PutUint%s(b, math.Float%sbits(v))\n", order, bits, bits)
+	case f.typ == "complex64" || f.typ == "complex128":
+		half := strconv.FormatInt(4*f.size, 10)
+		return fmt.Sprintf("\t%s.PutUint%s(b, math.Float%sbits(float%s(real(v))))\n", order, half, half, half) +
+			fmt.Sprintf("\t%s.PutUint%s(b[%d:], math.Float%sbits(float%s(imag(v))))\n", order, half, f.size/2, half, half)
+	}
+	if f.typ == "uint"+bits {
+		return fmt.Sprintf("\t%s.PutUint%s(b, v)\n", order, bits)
+	}
+	return fmt.Sprintf("\t%s.PutUint%s(b, uint%s(v))\n", order, bits, bits)
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// parseTestFile parses and returns the given Go source.
+func parseTestFile(t *testing.T, src string) []*ast.File {
+	file, err := parser.ParseFile(token.NewFileSet(), "test.go", src, parser.SkipObjectResolution)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []*ast.File{file}
+}
+
+//------------------------------------------- TEST CASES ---------------------------------------------------------------
+
+// TestGenerate tests the accessors generation.
+// CASE 1: The offsets and the size MUST be laid out like by segment.ReadStruct.
+// CASE 2: The accessors MUST be generated for every field except the excluded and the blank ones.
+// CASE 3: The struct with the unsupported or the unexported field MUST be rejected.
+func TestGenerate(t *testing.T) {
+	files := parseTestFile(t, `package records
+
+type Header struct {
+	Magic  [4]byte `+"`bio:\"off=0\"`"+`
+	_      uint16
+	Length int32   `+"`bio:\"off=8,len=3,be\"`"+`
+	Scale  float32 `+"`bio:\"le\"`"+`
+	name   string  `+"`bio:\"-\"`"+`
+}
+`)
+	src, err := generate(files, []string{"Header"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"HeaderMagicOffset  = 0",
+		"HeaderLengthOffset = 8",
+		"HeaderScaleOffset  = 11",
+		"HeaderSize         = 15",
+		"func GetHeaderLength(seg *segment.Segment, offset int64) (int32, error)",
+		"func SetHeaderScale(seg *segment.Segment, offset int64, v float32) error",
+		"binary.LittleEndian.PutUint32(b, math.Float32bits(v))",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Fatalf("generated source must contain %q:\n%s", expected, src)
+		}
+	}
+	if strings.Contains(string(src), "Header_") || strings.Contains(string(src), "Headername") {
+		t.Fatalf("generated source must not contain blank and excluded fields:\n%s", src)
+	}
+	for _, src := range []string{
+		"package records\n\ntype Bad struct {\n\tNames []string\n}\n",
+		"package records\n\ntype Bad struct {\n\tcount uint32\n}\n",
+	} {
+		if _, err := generate(parseTestFile(t, src), []string{"Bad"}); err == nil {
+			t.Fatalf("struct must be rejected:\n%s", src)
+		}
+	}
+}
+
+// testRecords is the source of the package which declares the test struct.
+const testRecords = `package records
+
+type Header struct {
+	Magic  [4]byte    ` + "`bio:\"off=0\"`" + `
+	_      uint16
+	Length int32      ` + "`bio:\"off=8,len=3\"`" + `
+	Count  uint64     ` + "`bio:\"len=5,le\"`" + `
+	Scale  float32    ` + "`bio:\"le\"`" + `
+	Point  complex64  ` + "`bio:\"be\"`" + `
+	Ready  bool
+	Code   [2]byte    ` + "`bio:\"len=4\"`" + `
+	Total  uint16
+	name   string     ` + "`bio:\"-\"`" + `
+}
+`
+
+// testRoundTrip is the source of the test of the generated accessors against segment.ReadStruct and WriteStruct.
+const testRoundTrip = `package records
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/alexeymaximov/go-bio/segment"
+)
+
+func TestRoundTrip(t *testing.T) {
+	expected := Header{
+		Magic: [4]byte{'B', 'I', 'O', '!'}, Length: -5, Count: 1<<33 + 7, Scale: 1.5,
+		Point: complex(2, -3), Ready: true, Code: [2]byte{'O', 'K'}, Total: 513,
+	}
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		seg := segment.New(0, make([]byte, 3+HeaderSize))
+		seg.SetByteOrder(order)
+		if _, err := seg.WriteStruct(3, &expected); err != nil {
+			t.Fatal(err)
+		}
+		var actual Header
+		var err error
+		get := func(f func() error) {
+			if e := f(); e != nil && err == nil {
+				err = e
+			}
+		}
+		get(func() (err error) { actual.Magic, err = GetHeaderMagic(seg, 3); return })
+		get(func() (err error) { actual.Length, err = GetHeaderLength(seg, 3); return })
+		get(func() (err error) { actual.Count, err = GetHeaderCount(seg, 3); return })
+		get(func() (err error) { actual.Scale, err = GetHeaderScale(seg, 3); return })
+		get(func() (err error) { actual.Point, err = GetHeaderPoint(seg, 3); return })
+		get(func() (err error) { actual.Ready, err = GetHeaderReady(seg, 3); return })
+		get(func() (err error) { actual.Code, err = GetHeaderCode(seg, 3); return })
+		get(func() (err error) { actual.Total, err = GetHeaderTotal(seg, 3); return })
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != expected {
+			t.Fatalf("%v: record must be %+v, %+v found", order, expected, actual)
+		}
+		seg = segment.New(0, make([]byte, 3+HeaderSize))
+		seg.SetByteOrder(order)
+		for _, set := range []error{
+			SetHeaderMagic(seg, 3, expected.Magic), SetHeaderLength(seg, 3, expected.Length),
+			SetHeaderCount(seg, 3, expected.Count), SetHeaderScale(seg, 3, expected.Scale),
+			SetHeaderPoint(seg, 3, expected.Point), SetHeaderReady(seg, 3, expected.Ready),
+			SetHeaderCode(seg, 3, expected.Code), SetHeaderTotal(seg, 3, expected.Total),
+		} {
+			if set != nil {
+				t.Fatal(set)
+			}
+		}
+		actual = Header{}
+		if _, err := seg.ReadStruct(3, &actual); err != nil {
+			t.Fatal(err)
+		}
+		if actual != expected {
+			t.Fatalf("%v: record must be %+v, %+v found", order, expected, actual)
+		}
+	}
+	if _, err := GetHeaderTotal(segment.New(0, make([]byte, HeaderSize-1)), 0); err != segment.ErrOutOfBounds {
+		t.Fatalf("expected ErrOutOfBounds, [%v] error found", err)
+	}
+}
+`
+
+// TestCompile tests the generated source by the compiler.
+// CASE: The generated accessors MUST compile and agree with segment.ReadStruct and segment.WriteStruct
+// in both byte orders.
+func TestCompile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compilation is skipped in the short mode")
+	}
+	goTool := filepath.Join(runtime.GOROOT(), "bin", "go")
+	if _, err := os.Stat(goTool); err != nil {
+		t.Skip(err)
+	}
+	src, err := generate(parseTestFile(t, testRecords), []string{"Header"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The package is placed inside the module, so it imports the segment package of this tree.
+	dir, err := os.MkdirTemp(".", "records")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, data := range map[string]string{
+		"records.go":      testRecords,
+		"header_bio.go":   string(src),
+		"records_test.go": testRoundTrip,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command(goTool, "test", "-count=1", "./"+filepath.Base(dir))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v:\n%s\n%s", err, output, src)
+	}
+}
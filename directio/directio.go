@@ -0,0 +1,239 @@
+// Package directio provides the file which bypasses the page cache of the operating system
+// with O_DIRECT on Linux, F_NOCACHE on Darwin and FILE_FLAG_NO_BUFFERING on Windows.
+// It is the escape hatch for the huge scans which pollute the page cache at the expense of the rest of the system,
+// and it shares the io.ReaderAt and io.WriterAt interfaces with the mapping.
+package directio
+
+import (
+	"io"
+	"os"
+	"sync"
+	"unsafe"
+)
+
+// Alignment is the alignment in bytes of the offsets, the lengths and the buffer addresses of the direct I/O.
+// It covers the logical block sizes of the common disks.
+const Alignment = 4096
+
+// bounceSize is the length in bytes of the aligned buffer which is used for the unaligned requests.
+const bounceSize = 1 << 20
+
+// bouncePool is the pool of the aligned buffers which are used for the unaligned requests.
+var bouncePool = sync.Pool{
+	New: func() any {
+		buf := AlignedBuffer(bounceSize)
+		return &buf
+	},
+}
+
+// AlignedBuffer returns a new zeroed buffer of the given length which address is aligned by Alignment,
+// so it may be used for the direct I/O without the intermediate copy.
+func AlignedBuffer(length int) []byte {
+	buf := make([]byte, length+Alignment)
+	shift := 0
+	if rem := uintptr(unsafe.Pointer(&buf[0])) % Alignment; rem != 0 {
+		shift = int(Alignment - rem)
+	}
+	return buf[shift : shift+length : shift+length]
+}
+
+// aligned returns whether the given buffer and offset may be used for the direct I/O as is.
+func aligned(buf []byte, offset int64) bool {
+	return len(buf) > 0 && uintptr(unsafe.Pointer(&buf[0]))%Alignment == 0 && len(buf)%Alignment == 0 && offset%Alignment == 0
+}
+
+// File is a file which is read and written bypassing the page cache.
+// The aligned requests, see Alignment and AlignedBuffer, are passed to the operating system as is,
+// the unaligned ones are read or read-modified-written through the aligned intermediate buffer.
+// The concurrent unaligned writes of the same blocks are not safe.
+type File struct {
+	// file specifies the opened file.
+	file *os.File
+}
+
+// Open opens the named file for the direct I/O with the given flags and permissions like os.OpenFile.
+// The ErrNotSupported returns if the platform or the file system does not support the direct I/O, e.g. tmpfs.
+func Open(name string, flag int, perm os.FileMode) (*File, error) {
+	f, err := openFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &File{file: f}, nil
+}
+
+// Name returns the name of the file as presented to Open.
+func (f *File) Name() string {
+	if f.file == nil {
+		return ""
+	}
+	return f.file.Name()
+}
+
+// Size returns the size of the file in bytes.
+func (f *File) Size() (int64, error) {
+	if f.file == nil {
+		return 0, ErrClosed
+	}
+	info, err := f.file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// ReadAt reads len(buf) bytes at the given offset from start of the file.
+// The io.EOF error will be returned if fewer bytes are read.
+// ReadAt implements the io.ReaderAt interface.
+func (f *File) ReadAt(buf []byte, offset int64) (int, error) {
+	if f.file == nil {
+		return 0, ErrClosed
+	}
+	if offset < 0 {
+		return 0, ErrBadOffset
+	}
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	if aligned(buf, offset) {
+		return f.readFull(buf, offset)
+	}
+	bounce := bouncePool.Get().(*[]byte)
+	defer bouncePool.Put(bounce)
+	n := 0
+	for n < len(buf) {
+		low := (offset + int64(n)) &^ (Alignment - 1)
+		skip := int(offset + int64(n) - low)
+		// Only the blocks which contain the rest of the requested data are read.
+		length := bounceSize
+		if rest := skip + len(buf) - n; rest < bounceSize {
+			length = (rest + Alignment - 1) &^ (Alignment - 1)
+		}
+		k, err := f.readFull((*bounce)[:length], low)
+		k -= skip
+		if k < 0 {
+			k = 0
+		}
+		k = copy(buf[n:], (*bounce)[skip:skip+k])
+		n += k
+		if err != nil {
+			if err == io.EOF && n == len(buf) {
+				return n, nil
+			}
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// readFull reads the given aligned buffer at the given aligned offset until it is full or the end of the file.
+func (f *File) readFull(buf []byte, offset int64) (int, error) {
+	n := 0
+	for n < len(buf) {
+		k, err := pread(f.file, buf[n:], offset+int64(n))
+		n += k
+		if err != nil {
+			return n, err
+		}
+		if k == 0 || (n < len(buf) && n%Alignment != 0) {
+			// The end of the file is reached, the next offset may be unaligned.
+			return n, io.EOF
+		}
+	}
+	return n, nil
+}
+
+// WriteAt writes len(buf) bytes at the given offset from start of the file.
+// The partial blocks are read, modified and written entirely, the file is not extended beyond the written data.
+// WriteAt implements the io.WriterAt interface.
+func (f *File) WriteAt(buf []byte, offset int64) (int, error) {
+	if f.file == nil {
+		return 0, ErrClosed
+	}
+	if offset < 0 {
+		return 0, ErrBadOffset
+	}
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	if aligned(buf, offset) {
+		return f.writeFull(buf, offset)
+	}
+	size, err := f.Size()
+	if err != nil {
+		return 0, err
+	}
+	bounce := bouncePool.Get().(*[]byte)
+	defer bouncePool.Put(bounce)
+	n := 0
+	var end int64
+	for n < len(buf) {
+		low := (offset + int64(n)) &^ (Alignment - 1)
+		skip := int(offset + int64(n) - low)
+		k := len(buf) - n
+		if k > bounceSize-skip {
+			k = bounceSize - skip
+		}
+		length := (skip + k + Alignment - 1) &^ (Alignment - 1)
+		block := (*bounce)[:length]
+		if skip != 0 || k%Alignment != 0 {
+			// The partial blocks keep the data around the written one.
+			r, err := f.readFull(block, low)
+			if err != nil && err != io.EOF {
+				return n, err
+			}
+			for i := r; i < length; i++ {
+				block[i] = 0
+			}
+		}
+		copy(block[skip:], buf[n:n+k])
+		if _, err := f.writeFull(block, low); err != nil {
+			return n, err
+		}
+		n += k
+		end = low + int64(length)
+	}
+	if limit := offset + int64(n); end > size && end > limit {
+		if limit < size {
+			limit = size
+		}
+		if err := f.file.Truncate(limit); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// writeFull writes the given aligned buffer at the given aligned offset entirely.
+func (f *File) writeFull(buf []byte, offset int64) (int, error) {
+	n := 0
+	for n < len(buf) {
+		k, err := pwrite(f.file, buf[n:], offset+int64(n))
+		n += k
+		if err != nil {
+			return n, err
+		}
+		if k == 0 {
+			return n, io.ErrShortWrite
+		}
+	}
+	return n, nil
+}
+
+// Sync commits the file metadata to the stable storage, the data is written bypassing the page cache already.
+func (f *File) Sync() error {
+	if f.file == nil {
+		return ErrClosed
+	}
+	return f.file.Sync()
+}
+
+// Close closes the file.
+// Close implements the io.Closer interface.
+func (f *File) Close() error {
+	if f.file == nil {
+		return ErrClosed
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
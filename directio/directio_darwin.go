@@ -0,0 +1,20 @@
+package directio
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// openFile opens the named file and disables its caching with F_NOCACHE.
+func openFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	f, err := os.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := unix.FcntlInt(f.Fd(), unix.F_NOCACHE, 1); err != nil {
+		_ = f.Close()
+		return nil, os.NewSyscallError("fcntl", err)
+	}
+	return f, nil
+}
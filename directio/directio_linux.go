@@ -0,0 +1,17 @@
+package directio
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// openFile opens the named file with O_DIRECT.
+func openFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	f, err := os.OpenFile(name, flag|unix.O_DIRECT, perm)
+	if errors.Is(err, unix.EINVAL) {
+		return nil, ErrNotSupported
+	}
+	return f, err
+}
@@ -0,0 +1,20 @@
+//go:build !darwin && !linux && !windows
+
+package directio
+
+import "os"
+
+// openFile returns ErrNotSupported since the page cache can not be bypassed on this platform.
+func openFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	return nil, ErrNotSupported
+}
+
+// pread returns ErrNotSupported.
+func pread(f *os.File, buf []byte, offset int64) (int, error) {
+	return 0, ErrNotSupported
+}
+
+// pwrite returns ErrNotSupported.
+func pwrite(f *os.File, buf []byte, offset int64) (int, error) {
+	return 0, ErrNotSupported
+}
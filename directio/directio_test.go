@@ -0,0 +1,101 @@
+package directio
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// openTestFile opens and returns a new empty file for the direct I/O.
+func openTestFile(t *testing.T) *File {
+	f, err := Open(filepath.Join(t.TempDir(), "direct"), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err == ErrNotSupported {
+		t.Skip("direct I/O is not supported")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+//------------------------------------------- TEST CASES ---------------------------------------------------------------
+
+// TestAligned tests the aligned requests.
+// CASE 1: The buffer returned by AlignedBuffer MUST be aligned.
+// CASE 2: The data written by the aligned request MUST be read back.
+// CASE 3: The read beyond the end of the file MUST return io.EOF.
+func TestAligned(t *testing.T) {
+	f := openTestFile(t)
+	defer f.Close()
+	buf := AlignedBuffer(2 * Alignment)
+	if !aligned(buf, 0) {
+		t.Fatal("buffer must be aligned")
+	}
+	for i := range buf {
+		buf[i] = byte(i)
+	}
+	if _, err := f.WriteAt(buf, Alignment); err != nil {
+		t.Fatal(err)
+	}
+	out := AlignedBuffer(2 * Alignment)
+	if _, err := f.ReadAt(out, Alignment); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, buf) {
+		t.Fatal("read data must be equal to the written one")
+	}
+	if n, err := f.ReadAt(out, 2*Alignment); err != io.EOF || n != Alignment {
+		t.Fatalf("read must return %d bytes and io.EOF, %d bytes and %v found", Alignment, n, err)
+	}
+}
+
+// TestUnaligned tests the unaligned requests.
+// CASE 1: The data around the unaligned write MUST be retained.
+// CASE 2: The file MUST NOT be extended beyond the written data.
+// CASE 3: The unaligned read MUST return the written data.
+// CASE 4: The short unaligned read which crosses the block boundary MUST return the written data.
+func TestUnaligned(t *testing.T) {
+	f := openTestFile(t)
+	defer f.Close()
+	if _, err := f.WriteAt([]byte("HELLO WORLD"), 100); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte("there"), 106); err != nil {
+		t.Fatal(err)
+	}
+	size, err := f.Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 111 {
+		t.Fatalf("size must be %d, %d found", 111, size)
+	}
+	data := make([]byte, 20)
+	n, err := f.ReadAt(data, 98)
+	if err != io.EOF {
+		t.Fatalf("read must return io.EOF, %v found", err)
+	}
+	if expected := "\x00\x00HELLO there"; string(data[:n]) != expected {
+		t.Fatalf("data must be %q, %q found", expected, data[:n])
+	}
+	big := bytes.Repeat([]byte("0123456789"), bounceSize/5)
+	if _, err := f.WriteAt(big, 7); err != nil {
+		t.Fatal(err)
+	}
+	out := make([]byte, len(big))
+	if _, err := f.ReadAt(out, 7); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, big) {
+		t.Fatal("read data must be equal to the written one")
+	}
+	short := make([]byte, 3)
+	if _, err := f.ReadAt(short, 2*Alignment-1); err != nil {
+		t.Fatal(err)
+	}
+	if at := 2*Alignment - 1 - 7; !bytes.Equal(short, big[at:at+3]) {
+		t.Fatalf("data must be %q, %q found", big[at:at+3], short)
+	}
+}
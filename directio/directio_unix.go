@@ -0,0 +1,37 @@
+//go:build darwin || linux
+
+package directio
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// pread reads into the given buffer at the given offset by a single system call.
+func pread(f *os.File, buf []byte, offset int64) (int, error) {
+	for {
+		n, err := unix.Pread(int(f.Fd()), buf, offset)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return 0, os.NewSyscallError("pread", err)
+		}
+		return n, nil
+	}
+}
+
+// pwrite writes the given buffer at the given offset by a single system call.
+func pwrite(f *os.File, buf []byte, offset int64) (int, error) {
+	for {
+		n, err := unix.Pwrite(int(f.Fd()), buf, offset)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return 0, os.NewSyscallError("pwrite", err)
+		}
+		return n, nil
+	}
+}
@@ -0,0 +1,74 @@
+package directio
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// openFile opens the named file with FILE_FLAG_NO_BUFFERING and FILE_FLAG_WRITE_THROUGH.
+// The flags are translated like by os.OpenFile.
+func openFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	path, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	var access uint32
+	switch flag & (os.O_RDONLY | os.O_WRONLY | os.O_RDWR) {
+	case os.O_RDONLY:
+		access = windows.GENERIC_READ
+	case os.O_WRONLY:
+		access = windows.GENERIC_WRITE
+	case os.O_RDWR:
+		access = windows.GENERIC_READ | windows.GENERIC_WRITE
+	}
+	var disposition uint32
+	switch {
+	case flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL:
+		disposition = windows.CREATE_NEW
+	case flag&(os.O_CREATE|os.O_TRUNC) == os.O_CREATE|os.O_TRUNC:
+		disposition = windows.CREATE_ALWAYS
+	case flag&os.O_CREATE == os.O_CREATE:
+		disposition = windows.OPEN_ALWAYS
+	case flag&os.O_TRUNC == os.O_TRUNC:
+		disposition = windows.TRUNCATE_EXISTING
+	default:
+		disposition = windows.OPEN_EXISTING
+	}
+	attrs := uint32(windows.FILE_ATTRIBUTE_NORMAL)
+	if perm&0200 == 0 {
+		attrs = windows.FILE_ATTRIBUTE_READONLY
+	}
+	h, err := windows.CreateFile(
+		path, access, windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE, nil,
+		disposition, attrs|windows.FILE_FLAG_NO_BUFFERING|windows.FILE_FLAG_WRITE_THROUGH, 0,
+	)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	return os.NewFile(uintptr(h), name), nil
+}
+
+// pread reads into the given buffer at the given offset by a single system call.
+func pread(f *os.File, buf []byte, offset int64) (int, error) {
+	var n uint32
+	ov := windows.Overlapped{Offset: uint32(offset), OffsetHigh: uint32(offset >> 32)}
+	err := windows.ReadFile(windows.Handle(f.Fd()), buf, &n, &ov)
+	if err == windows.ERROR_HANDLE_EOF {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, os.NewSyscallError("ReadFile", err)
+	}
+	return int(n), nil
+}
+
+// pwrite writes the given buffer at the given offset by a single system call.
+func pwrite(f *os.File, buf []byte, offset int64) (int, error) {
+	var n uint32
+	ov := windows.Overlapped{Offset: uint32(offset), OffsetHigh: uint32(offset >> 32)}
+	if err := windows.WriteFile(windows.Handle(f.Fd()), buf, &n, &ov); err != nil {
+		return 0, os.NewSyscallError("WriteFile", err)
+	}
+	return int(n), nil
+}
@@ -0,0 +1,12 @@
+package directio
+
+import "fmt"
+
+// ErrClosed is the error which returns when tries to access the closed file.
+var ErrClosed = fmt.Errorf("directio: file closed")
+
+// ErrBadOffset is the error which returns when the given offset is not valid.
+var ErrBadOffset = fmt.Errorf("directio: bad offset")
+
+// ErrNotSupported is the error which returns when the direct I/O is not supported by the platform or the file system.
+var ErrNotSupported = fmt.Errorf("directio: operation not supported")
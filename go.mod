@@ -1,3 +1,5 @@
 module github.com/alexeymaximov/go-bio
 
-go 1.13
+go 1.20
+
+require golang.org/x/sys v0.30.0
@@ -0,0 +1,9 @@
+package httpfs
+
+import "fmt"
+
+// ErrIsDir is the error which returns when tries to read or seek the directory.
+var ErrIsDir = fmt.Errorf("httpfs: is a directory")
+
+// ErrNotDir is the error which returns when tries to list the entries of the file.
+var ErrNotDir = fmt.Errorf("httpfs: not a directory")
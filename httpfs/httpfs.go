@@ -0,0 +1,241 @@
+// Package httpfs provides the adapter which exposes the mappings as http.FileSystem,
+// so the mapped assets and data files may be served by net/http with the range requests
+// directly from the mapped memory.
+package httpfs
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alexeymaximov/go-bio/mmap"
+)
+
+// FileSystem is a set of the mappings which are exposed as the files by their names.
+// The directories are implied by the names of the files. The mappings are owned by the caller
+// and must not be closed until they are removed from the file system and all their opened files are closed.
+// The file system is safe for concurrent use.
+type FileSystem struct {
+	// mu protects entries.
+	mu sync.RWMutex
+	// entries specifies the mappings by the cleaned names of the files.
+	entries map[string]entry
+}
+
+// entry is a mapping which is exposed as the file.
+type entry struct {
+	mapping *mmap.Mapping
+	modTime time.Time
+}
+
+// New returns a new empty file system.
+func New() *FileSystem {
+	return &FileSystem{entries: make(map[string]entry)}
+}
+
+// Add exposes the given mapping as the file with the given name and modification time,
+// it replaces the previous mapping with the same name if any.
+func (fsys *FileSystem) Add(name string, m *mmap.Mapping, modTime time.Time) {
+	fsys.mu.Lock()
+	defer fsys.mu.Unlock()
+	fsys.entries[cleanName(name)] = entry{mapping: m, modTime: modTime}
+}
+
+// Remove removes the file with the given name, the files which are already opened remain valid.
+func (fsys *FileSystem) Remove(name string) {
+	fsys.mu.Lock()
+	defer fsys.mu.Unlock()
+	delete(fsys.entries, cleanName(name))
+}
+
+// Open opens the file or the implied directory with the given name.
+// The fs.ErrNotExist is wrapped by the returned error if there is no such file or directory.
+// Open implements the http.FileSystem interface.
+func (fsys *FileSystem) Open(name string) (http.File, error) {
+	name = cleanName(name)
+	fsys.mu.RLock()
+	defer fsys.mu.RUnlock()
+	if e, ok := fsys.entries[name]; ok {
+		return NewFile(e.mapping, path.Base(name), e.modTime), nil
+	}
+	prefix := name
+	if prefix != "/" {
+		prefix += "/"
+	}
+	children := make(map[string]fs.FileInfo)
+	for entryName, e := range fsys.entries {
+		if !strings.HasPrefix(entryName, prefix) {
+			continue
+		}
+		rest := entryName[len(prefix):]
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			children[rest[:i]] = &fileInfo{name: rest[:i], dir: true}
+		} else if _, ok := children[rest]; !ok {
+			children[rest] = &fileInfo{name: rest, size: int64(e.mapping.Length()), modTime: e.modTime}
+		}
+	}
+	if len(children) == 0 && name != "/" {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	d := &dir{info: fileInfo{name: path.Base(name), dir: true}}
+	for _, info := range children {
+		d.entries = append(d.entries, info)
+	}
+	sort.Slice(d.entries, func(i, j int) bool {
+		return d.entries[i].Name() < d.entries[j].Name()
+	})
+	return d, nil
+}
+
+// cleanName returns the given name of the file as the absolute slash-separated path.
+func cleanName(name string) string {
+	return path.Clean("/" + name)
+}
+
+// File is a mapping which is exposed as the file with its own position.
+// The file is not safe for concurrent use.
+type File struct {
+	// cursor specifies the cursor of the mapping.
+	cursor *mmap.Cursor
+	// info specifies the information of the file.
+	info fileInfo
+}
+
+// NewFile returns a new file which exposes the given mapping with the given name and modification time.
+// The mapping is owned by the caller, it is not closed together with the file.
+func NewFile(m *mmap.Mapping, name string, modTime time.Time) *File {
+	return &File{
+		cursor: m.NewCursor(),
+		info:   fileInfo{name: name, size: int64(m.Length()), modTime: modTime},
+	}
+}
+
+// Read reads up to len(buf) bytes from the current position and advances it.
+// Read implements the io.Reader interface.
+func (f *File) Read(buf []byte) (int, error) {
+	return f.cursor.Read(buf)
+}
+
+// WriteTo writes the file from the current position to the end into the given writer directly
+// from the mapped memory. WriteTo implements the io.WriterTo interface.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	return f.cursor.WriteTo(w)
+}
+
+// Seek sets the position for the next Read.
+// Seek implements the io.Seeker interface.
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	return f.cursor.Seek(offset, whence)
+}
+
+// Readdir returns ErrNotDir since the file is not a directory.
+func (f *File) Readdir(count int) ([]fs.FileInfo, error) {
+	return nil, ErrNotDir
+}
+
+// Stat returns the information of the file.
+func (f *File) Stat() (fs.FileInfo, error) {
+	info := f.info
+	return &info, nil
+}
+
+// Close closes the file, the mapping remains open.
+// Close implements the io.Closer interface.
+func (f *File) Close() error {
+	return nil
+}
+
+// dir is an implied directory of the file system.
+type dir struct {
+	// info specifies the information of the directory.
+	info fileInfo
+	// entries specifies the entries of the directory sorted by name.
+	entries []fs.FileInfo
+	// position specifies the number of the entries which are already listed.
+	position int
+}
+
+// Read returns ErrIsDir since the directory can not be read.
+func (d *dir) Read(buf []byte) (int, error) {
+	return 0, ErrIsDir
+}
+
+// Seek returns ErrIsDir since the directory can not be read.
+func (d *dir) Seek(offset int64, whence int) (int64, error) {
+	return 0, ErrIsDir
+}
+
+// Readdir returns up to the given number of the next entries of the directory or all remaining ones
+// if the number is not positive, see os.File.Readdir.
+func (d *dir) Readdir(count int) ([]fs.FileInfo, error) {
+	rest := d.entries[d.position:]
+	if count <= 0 {
+		d.position = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(rest) {
+		count = len(rest)
+	}
+	d.position += count
+	return rest[:count], nil
+}
+
+// Stat returns the information of the directory.
+func (d *dir) Stat() (fs.FileInfo, error) {
+	info := d.info
+	return &info, nil
+}
+
+// Close does nothing.
+func (d *dir) Close() error {
+	return nil
+}
+
+// fileInfo is the information of the file or the directory.
+type fileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+// Name returns the base name of the file.
+func (i *fileInfo) Name() string {
+	return i.name
+}
+
+// Size returns the length of the file in bytes.
+func (i *fileInfo) Size() int64 {
+	return i.size
+}
+
+// Mode returns the read-only file mode bits.
+func (i *fileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+// ModTime returns the modification time of the file.
+func (i *fileInfo) ModTime() time.Time {
+	return i.modTime
+}
+
+// IsDir returns whether it is the directory.
+func (i *fileInfo) IsDir() bool {
+	return i.dir
+}
+
+// Sys returns nil.
+func (i *fileInfo) Sys() any {
+	return nil
+}
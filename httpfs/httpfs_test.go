@@ -0,0 +1,92 @@
+package httpfs
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alexeymaximov/go-bio/mmap"
+)
+
+// testData is the non-zero test data.
+var testData = []byte{'H', 'E', 'L', 'L', 'O'}
+
+// openTestFileSystem opens and returns a new file system with the single file of the test data.
+func openTestFileSystem(t *testing.T) (*FileSystem, *mmap.Mapping) {
+	m, err := mmap.OpenAnonymous(uintptr(len(testData)), mmap.ModeReadWrite, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	fsys := New()
+	fsys.Add("/assets/hello.txt", m, time.Unix(1_000_000_000, 0))
+	return fsys, m
+}
+
+//------------------------------------------- TEST CASES ---------------------------------------------------------------
+
+// TestServe tests the serving of the file system by net/http.
+// CASE 1: The range request MUST return the requested part of the file.
+// CASE 2: The missing file MUST be not found.
+func TestServe(t *testing.T) {
+	fsys, m := openTestFileSystem(t)
+	defer m.Close()
+	server := httptest.NewServer(http.FileServer(fsys))
+	defer server.Close()
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/assets/hello.txt", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Range", "bytes=1-3")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusPartialContent || string(body) != string(testData[1:4]) {
+		t.Fatalf("expected %d status and %q, %d status and %q found", http.StatusPartialContent, testData[1:4], resp.StatusCode, body)
+	}
+	if lm := resp.Header.Get("Last-Modified"); lm != time.Unix(1_000_000_000, 0).UTC().Format(http.TimeFormat) {
+		t.Fatalf("unexpected modification time %q", lm)
+	}
+	resp, err = http.Get(server.URL + "/assets/missing.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected %d status, %d status found", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+// TestReaddir tests the implied directories.
+// CASE: The directory MUST list its files and subdirectories.
+func TestReaddir(t *testing.T) {
+	fsys, m := openTestFileSystem(t)
+	defer m.Close()
+	fsys.Add("/assets/css/site.css", m, time.Time{})
+	d, err := fsys.Open("/assets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	infos, err := d.Readdir(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 || infos[0].Name() != "css" || !infos[0].IsDir() || infos[1].Name() != "hello.txt" || infos[1].Size() != int64(len(testData)) {
+		t.Fatalf("unexpected entries %v", infos)
+	}
+	fsys.Remove("/assets/css/site.css")
+	if _, err := fsys.Open("/assets/css"); err == nil {
+		t.Fatal("removed directory must not exist")
+	}
+}
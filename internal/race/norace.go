@@ -0,0 +1,20 @@
+//go:build !race
+
+// Package race provides the race detector annotations for the memory
+// which is not visible to the race detector, such as the mapped memory.
+package race
+
+// Enabled specifies whether the race detector is enabled.
+const Enabled = false
+
+// Register registers the given memory range for the annotations.
+func Register(address, length uintptr) {}
+
+// Unregister unregisters the memory range which starts from the given address.
+func Unregister(address uintptr) {}
+
+// ReadRange annotates the read access to the given memory range.
+func ReadRange(address, length uintptr) {}
+
+// WriteRange annotates the write access to the given memory range.
+func WriteRange(address, length uintptr) {}
@@ -0,0 +1,178 @@
+//go:build race
+
+// Package race provides the race detector annotations for the memory
+// which is not visible to the race detector, such as the mapped memory.
+// The race detector tracks only the Go heap and data segments,
+// so every registered memory range gets a heap allocated shadow
+// and each annotated access to that range is reported against the shadow.
+// The shadow is allocated lazily by chunks at the first access to the part of the range,
+// each byte of the chunk tracks a single byte of the range, so the accesses to the neighbouring fields
+// are not reported as the races and the untouched parts of the range cost nothing.
+// Once the chunks of all ranges reach ShadowBudget bytes, every further chunk is tracked by a single shadow byte,
+// so the accesses to the distinct bytes of such chunk may be reported as the races.
+package race
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Enabled specifies whether the race detector is enabled.
+const Enabled = true
+
+// ChunkSize is the number of bytes of the registered memory range covered by a single lazily allocated shadow chunk.
+const ChunkSize = 64 << 10
+
+// ShadowBudget is the maximum total size of the shadow chunks of all registered memory ranges in bytes.
+const ShadowBudget = 1 << 30
+
+// shadow is a heap allocated shadow of the registered memory range.
+type shadow struct {
+	// address specifies the start address of the registered memory range.
+	address uintptr
+	// length specifies the length of the registered memory range in bytes.
+	length uintptr
+	// chunks specifies the lazily allocated shadow chunks of the registered memory range.
+	chunks []atomic.Pointer[[ChunkSize]byte]
+	// coarse specifies the single shadow byte per chunk which is used if the chunk exceeds the budget.
+	coarse []byte
+}
+
+var (
+	// mutex protects shadows.
+	mutex sync.RWMutex
+	// shadows specifies the shadows of all registered memory ranges sorted by the start address.
+	shadows []*shadow
+	// allocated specifies the total size of the allocated shadow chunks in bytes.
+	allocated atomic.Int64
+)
+
+// Register registers the given memory range for the annotations.
+func Register(address, length uintptr) {
+	if length == 0 {
+		return
+	}
+	n := (length + ChunkSize - 1) / ChunkSize
+	s := &shadow{
+		address: address,
+		length:  length,
+		chunks:  make([]atomic.Pointer[[ChunkSize]byte], n),
+		coarse:  make([]byte, n),
+	}
+	mutex.Lock()
+	i := search(address)
+	shadows = append(shadows, nil)
+	copy(shadows[i+1:], shadows[i:])
+	shadows[i] = s
+	mutex.Unlock()
+}
+
+// Unregister unregisters the memory range which starts from the given address.
+func Unregister(address uintptr) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	i := search(address)
+	if i == len(shadows) || shadows[i].address != address {
+		return
+	}
+	s := shadows[i]
+	shadows = append(shadows[:i], shadows[i+1:]...)
+	s.release()
+}
+
+// search returns the index of the first shadow which range ends after the given address.
+// The registered ranges do not overlap, so their ends are sorted as their starts.
+func search(address uintptr) int {
+	return sort.Search(len(shadows), func(i int) bool {
+		return shadows[i].address+shadows[i].length > address
+	})
+}
+
+// find returns the shadow of the registered memory range which contains the given address or nil.
+func find(address uintptr) *shadow {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	if i := search(address); i < len(shadows) && address >= shadows[i].address {
+		return shadows[i]
+	}
+	return nil
+}
+
+// chunk returns the shadow chunk of the given index allocating it at the first access
+// or nil if it exceeds the budget. The synchronization of the chunks is hidden from the race detector,
+// so it does not order the annotated accesses of the different goroutines.
+func (s *shadow) chunk(i uintptr) *[ChunkSize]byte {
+	runtime.RaceDisable()
+	defer runtime.RaceEnable()
+	if c := s.chunks[i].Load(); c != nil {
+		return c
+	}
+	if allocated.Add(ChunkSize) > ShadowBudget {
+		allocated.Add(-ChunkSize)
+		return nil
+	}
+	c := new([ChunkSize]byte)
+	if !s.chunks[i].CompareAndSwap(nil, c) {
+		allocated.Add(-ChunkSize)
+		return s.chunks[i].Load()
+	}
+	return c
+}
+
+// release returns the allocated shadow chunks to the budget.
+func (s *shadow) release() {
+	runtime.RaceDisable()
+	defer runtime.RaceEnable()
+	for i := range s.chunks {
+		if s.chunks[i].Swap(nil) != nil {
+			allocated.Add(-ChunkSize)
+		}
+	}
+}
+
+// annotate reports the access to the given memory range against its shadow if it is registered.
+// The range is clipped by the registered bounds.
+func annotate(address, length uintptr, write bool) {
+	if length == 0 {
+		return
+	}
+	s := find(address)
+	if s == nil {
+		return
+	}
+	low := address - s.address
+	high := s.length
+	if length < high-low {
+		high = low + length
+	}
+	for low < high {
+		i := low / ChunkSize
+		end := (i + 1) * ChunkSize
+		if end > high {
+			end = high
+		}
+		p, n := unsafe.Pointer(&s.coarse[i]), 1
+		if c := s.chunk(i); c != nil {
+			p, n = unsafe.Pointer(&c[low%ChunkSize]), int(end-low)
+		}
+		if write {
+			runtime.RaceWriteRange(p, n)
+		} else {
+			runtime.RaceReadRange(p, n)
+		}
+		low = end
+	}
+}
+
+// ReadRange annotates the read access to the given memory range.
+func ReadRange(address, length uintptr) {
+	annotate(address, length, false)
+}
+
+// WriteRange annotates the write access to the given memory range.
+func WriteRange(address, length uintptr) {
+	annotate(address, length, true)
+}
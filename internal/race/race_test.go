@@ -0,0 +1,60 @@
+//go:build race
+
+package race
+
+import "testing"
+
+//------------------------------------------- TEST CASES ---------------------------------------------------------------
+
+// TestFind tests the lookup of the registered memory ranges.
+// CASE 1: The shadow of the range which contains the given address MUST be found regardless of the registration order.
+// CASE 2: The shadow MUST NOT be found for the address outside of the registered ranges.
+// CASE 3: The shadow of the unregistered memory range MUST NOT be found.
+func TestFind(t *testing.T) {
+	const address, length = uintptr(1 << 40), uintptr(4 * ChunkSize)
+	Register(address+2*length, length)
+	Register(address, length)
+	defer Unregister(address + 2*length)
+	if s := find(address + length - 1); s == nil || s.address != address {
+		t.Fatalf("shadow of range at %#x must be found, %v found", address, s)
+	}
+	if s := find(address + 2*length); s == nil || s.address != address+2*length {
+		t.Fatalf("shadow of range at %#x must be found, %v found", address+2*length, s)
+	}
+	if s := find(address + length); s != nil {
+		t.Fatalf("shadow must not be found, range at %#x found", s.address)
+	}
+	Unregister(address)
+	if s := find(address); s != nil {
+		t.Fatalf("shadow must not be found, range at %#x found", s.address)
+	}
+}
+
+// TestLazyShadow tests the lazy allocation of the shadow chunks.
+// CASE 1: The shadow chunks MUST NOT be allocated until the range is accessed.
+// CASE 2: Only the chunks which contain the accessed bytes MUST be allocated.
+// CASE 3: The allocated chunks MUST be returned to the budget when the range is unregistered.
+func TestLazyShadow(t *testing.T) {
+	const address, length = uintptr(1 << 41), uintptr(8 * ChunkSize)
+	before := allocated.Load()
+	Register(address, length)
+	s := find(address)
+	if allocated.Load() != before {
+		t.Fatalf("allocated shadow must be %d bytes, %d found", before, allocated.Load())
+	}
+	ReadRange(address+ChunkSize-1, 2)
+	WriteRange(address+5*ChunkSize+1, 1)
+	for i := range s.chunks {
+		expected := i == 0 || i == 1 || i == 5
+		if (s.chunks[i].Load() != nil) != expected {
+			t.Fatalf("chunk %d must be allocated: %t", i, expected)
+		}
+	}
+	if allocated.Load() != before+3*ChunkSize {
+		t.Fatalf("allocated shadow must be %d bytes, %d found", before+3*ChunkSize, allocated.Load())
+	}
+	Unregister(address)
+	if allocated.Load() != before {
+		t.Fatalf("allocated shadow must be %d bytes, %d found", before, allocated.Load())
+	}
+}
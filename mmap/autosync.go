@@ -0,0 +1,62 @@
+package mmap
+
+import (
+	"sync"
+	"time"
+)
+
+// autoSyncer is a background goroutine which periodically synchronizes the mapped memory with the underlying file.
+type autoSyncer struct {
+	// mu protects the mapping from closing and mapping again during the synchronization.
+	mu sync.Mutex
+	// stop specifies the channel which is closed to stop the goroutine.
+	stop chan struct{}
+	// done specifies the channel which is closed when the goroutine is stopped.
+	done chan struct{}
+}
+
+// WithAutoSync starts the background goroutine which synchronizes the mapped memory
+// with the underlying file every given interval until the mapping is closed.
+// Only the written pages are synchronized if the dirty tracking is enabled by WithDirtyTracking.
+// It applies to the shared file mappings only, the errors of the background synchronization
+// are reported to the metrics receiver and the tracer, see SetMetrics and SetTracer.
+// The mapping is referenced by the goroutine, so it must be closed explicitly.
+func WithAutoSync(interval time.Duration) Option {
+	return func(o *options) {
+		o.autoSync = interval
+	}
+}
+
+// startAutoSync starts the background synchronization of this mapping every given interval.
+func (m *Mapping) startAutoSync(interval time.Duration) {
+	s := &autoSyncer{stop: make(chan struct{}), done: make(chan struct{})}
+	m.autoSync = s
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.mu.Lock()
+				if m.dirty != nil {
+					_ = m.syncDirty()
+				} else {
+					_ = m.sync(m.alignedMemory)
+				}
+				s.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// stopAutoSync stops the background synchronization of this mapping if any and waits for it.
+func (m *Mapping) stopAutoSync() {
+	if s := m.autoSync; s != nil {
+		close(s.stop)
+		<-s.done
+		m.autoSync = nil
+	}
+}
@@ -0,0 +1,73 @@
+package mmap
+
+import "runtime"
+
+// OpenCode opens and returns a new anonymous mapping of the given length to load the generated machine code
+// following the W^X policy: the mapped memory is writable but not executable until SealCode,
+// after which it is executable but not writable, it is never writable and executable simultaneously.
+func OpenCode(length uintptr) (*Mapping, error) {
+	if runtime.GOOS != "windows" {
+		return OpenAnonymous(length, ModeReadWrite, 0)
+	}
+	// The section object must allow the execution to make the view executable later,
+	// so the view is mapped executable and its execution is revoked before the code is written.
+	m, err := OpenAnonymous(length, ModeReadWrite, FlagExecutable)
+	if err != nil {
+		return nil, err
+	}
+	m.executable = false
+	m.flags &^= FlagExecutable
+	if err := m.protectMemory(m.alignedMemory, true); err != nil {
+		_ = m.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// SealCode flushes the instruction cache and flips the mapped memory from writable to executable at once,
+// so the machine code which is written into the mapping opened by OpenCode may be called.
+// It returns the address of the mapped memory which is compatible with the function pointer.
+// WriteAt, Begin and other writing methods return ErrReadOnly after it, the sealing can not be undone.
+// The ErrNotSupported returns on platforms which instruction cache can not be flushed and for the emulated mapping.
+func (m *Mapping) SealCode() (uintptr, error) {
+	if m.memory == nil {
+		return 0, ErrClosed
+	}
+	if !m.writable {
+		return 0, ErrReadOnly
+	}
+	if err := m.Sync(); err != nil {
+		return 0, err
+	}
+	if !flushInstructionCache(m.alignedMemory) {
+		return 0, ErrNotSupported
+	}
+	m.executable = true
+	if err := m.protectMemory(m.alignedMemory, false); err != nil {
+		m.executable = false
+		return 0, err
+	}
+	m.writable = false
+	m.flags |= FlagExecutable
+	return m.address, nil
+}
+
+// LoadCode opens a new mapping by OpenCode, copies the given machine code into it and seals it by SealCode.
+// It returns the mapping which must be closed when the code is not used anymore
+// and the address of the code which is compatible with the function pointer.
+func LoadCode(code []byte) (*Mapping, uintptr, error) {
+	m, err := OpenCode(uintptr(len(code)))
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err := m.WriteAt(code, 0); err != nil {
+		_ = m.Close()
+		return nil, 0, err
+	}
+	address, err := m.SealCode()
+	if err != nil {
+		_ = m.Close()
+		return nil, 0, err
+	}
+	return m, address, nil
+}
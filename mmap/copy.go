@@ -0,0 +1,170 @@
+package mmap
+
+import (
+	"bytes"
+
+	"github.com/alexeymaximov/go-bio/internal/race"
+)
+
+// Copy copies the given length of bytes starting from the given source offset of the source mapping
+// to the given destination offset of the destination mapping with a single memmove.
+// The ranges may overlap within the same mapping.
+// If any of the ranges is out of the bounds of its mapping the ErrOutOfBounds error will be returned.
+func Copy(dst *Mapping, dstOffset int64, src *Mapping, srcOffset int64, length uintptr) error {
+	if dst.memory == nil || src.memory == nil {
+		return ErrClosed
+	}
+	if !dst.writable {
+		return ErrReadOnly
+	}
+	if length > uintptr(MaxInt) {
+		return outOfBounds()
+	}
+	if err := src.access(srcOffset, int(length)); err != nil {
+		return err
+	}
+	if err := dst.access(dstOffset, int(length)); err != nil {
+		return err
+	}
+	if err := src.populate(srcOffset, length); err != nil {
+		return err
+	}
+	if err := dst.populate(dstOffset, length); err != nil {
+		return err
+	}
+	race.ReadRange(src.address+uintptr(srcOffset), length)
+	race.WriteRange(dst.address+uintptr(dstOffset), length)
+	copy(dst.memory[dstOffset:dstOffset+int64(length)], src.memory[srcOffset:srcOffset+int64(length)])
+	dst.markDirty(dstOffset, length)
+	return nil
+}
+
+// ZeroRange sets to zero the given length of bytes starting from the given offset
+// without allocating a zero buffer.
+// If the given range is out of the available bounds the ErrOutOfBounds error will be returned.
+func (m *Mapping) ZeroRange(offset int64, length uintptr) error {
+	b, err := m.writableRange(offset, length)
+	if err != nil {
+		return err
+	}
+	for i := range b {
+		b[i] = 0
+	}
+	m.markDirty(offset, length)
+	return nil
+}
+
+// FillRange sets to the given byte the given length of bytes starting from the given offset
+// without allocating a filled buffer.
+// If the given range is out of the available bounds the ErrOutOfBounds error will be returned.
+func (m *Mapping) FillRange(offset int64, length uintptr, value byte) error {
+	b, err := m.writableRange(offset, length)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	b[0] = value
+	for n := 1; n < len(b); n *= 2 {
+		copy(b[n:], b[:n])
+	}
+	m.markDirty(offset, length)
+	return nil
+}
+
+// writableRange checks the given range to be writable and returns the mapped memory which it contains.
+// The caller marks the range as written by markDirty after it is written.
+func (m *Mapping) writableRange(offset int64, length uintptr) ([]byte, error) {
+	if m.memory == nil {
+		return nil, ErrClosed
+	}
+	if !m.writable {
+		return nil, ErrReadOnly
+	}
+	if length > uintptr(MaxInt) {
+		return nil, outOfBounds()
+	}
+	if err := m.access(offset, int(length)); err != nil {
+		return nil, err
+	}
+	if err := m.populate(offset, length); err != nil {
+		return nil, err
+	}
+	race.WriteRange(m.address+uintptr(offset), length)
+	return m.memory[offset : offset+int64(length)], nil
+}
+
+// CompareRange compares the mapped memory starting from the given offset with the given buffer without copying
+// and returns the index of the first mismatching byte relative to the given offset or -1 if they are equal.
+// If the given offset is out of the available bounds or there are not enough bytes to compare
+// the ErrOutOfBounds error will be returned.
+func (m *Mapping) CompareRange(offset int64, buf []byte) (int64, error) {
+	if m.memory == nil {
+		return 0, ErrClosed
+	}
+	if err := m.access(offset, len(buf)); err != nil {
+		return 0, err
+	}
+	if err := m.populate(offset, uintptr(len(buf))); err != nil {
+		return 0, err
+	}
+	race.ReadRange(m.address+uintptr(offset), uintptr(len(buf)))
+	return mismatch(m.memory[offset:offset+int64(len(buf))], buf), nil
+}
+
+// EqualRange returns true if the mapped memory starting from the given offset is equal to the given buffer.
+func (m *Mapping) EqualRange(offset int64, buf []byte) (bool, error) {
+	i, err := m.CompareRange(offset, buf)
+	return i < 0, err
+}
+
+// Compare compares the given length of bytes starting from the given offsets of the given mappings without copying
+// and returns the index of the first mismatching byte relative to the given offsets or -1 if they are equal.
+// If any of the ranges is out of the bounds of its mapping the ErrOutOfBounds error will be returned.
+func Compare(a *Mapping, aOffset int64, b *Mapping, bOffset int64, length uintptr) (int64, error) {
+	if a.memory == nil || b.memory == nil {
+		return 0, ErrClosed
+	}
+	if length > uintptr(MaxInt) {
+		return 0, outOfBounds()
+	}
+	if err := a.access(aOffset, int(length)); err != nil {
+		return 0, err
+	}
+	if err := b.access(bOffset, int(length)); err != nil {
+		return 0, err
+	}
+	if err := a.populate(aOffset, length); err != nil {
+		return 0, err
+	}
+	if err := b.populate(bOffset, length); err != nil {
+		return 0, err
+	}
+	race.ReadRange(a.address+uintptr(aOffset), length)
+	race.ReadRange(b.address+uintptr(bOffset), length)
+	return mismatch(a.memory[aOffset:aOffset+int64(length)], b.memory[bOffset:bOffset+int64(length)]), nil
+}
+
+// mismatchChunk is the length of the chunks which are compared at once before looking for the mismatching byte.
+const mismatchChunk = 4096
+
+// mismatch returns the index of the first mismatching byte of the given slices of the same length
+// or -1 if they are equal.
+func mismatch(a, b []byte) int64 {
+	for low := 0; low < len(a); low += mismatchChunk {
+		high := low + mismatchChunk
+		if high > len(a) {
+			high = len(a)
+		}
+		if bytes.Equal(a[low:high], b[low:high]) {
+			continue
+		}
+		for i := low; i < high; i++ {
+			if a[i] != b[i] {
+				return int64(i)
+			}
+		}
+	}
+	return -1
+}
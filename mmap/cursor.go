@@ -0,0 +1,177 @@
+package mmap
+
+import (
+	"io"
+	"math"
+
+	"github.com/alexeymaximov/go-bio/internal/race"
+)
+
+// Cursor is a stream-oriented view of the mapped memory with its own position.
+// It allows to feed the mapping to encoding/binary, bufio, gob and other stream-oriented APIs.
+// The cursor is not safe for concurrent use.
+type Cursor struct {
+	// mapping specifies the mapping which this cursor belongs to.
+	mapping *Mapping
+	// position specifies the current position of this cursor from start of the mapped memory.
+	position int64
+}
+
+// NewCursor returns a new cursor positioned at start of the mapped memory.
+func (m *Mapping) NewCursor() *Cursor {
+	return &Cursor{mapping: m}
+}
+
+// Position returns the current position of this cursor from start of the mapped memory.
+func (c *Cursor) Position() int64 {
+	return c.position
+}
+
+// Read reads up to len(buf) bytes from the current position and advances it.
+// The io.EOF error will be returned if the position is at or beyond the end of the mapped memory.
+// Read implements the io.Reader interface.
+func (c *Cursor) Read(buf []byte) (int, error) {
+	m := c.mapping
+	if m.memory == nil {
+		return 0, ErrClosed
+	}
+	if c.position >= int64(len(m.memory)) {
+		return 0, io.EOF
+	}
+	if err := m.populate(c.position, c.span(len(buf))); err != nil {
+		return 0, err
+	}
+	n := copy(buf, m.memory[c.position:])
+	race.ReadRange(m.address+uintptr(c.position), uintptr(n))
+	c.position += int64(n)
+	return n, nil
+}
+
+// ReadByte reads and returns the byte at the current position and advances it.
+// ReadByte implements the io.ByteReader interface.
+func (c *Cursor) ReadByte() (byte, error) {
+	m := c.mapping
+	if m.memory == nil {
+		return 0, ErrClosed
+	}
+	if c.position >= int64(len(m.memory)) {
+		return 0, io.EOF
+	}
+	if err := m.populate(c.position, 1); err != nil {
+		return 0, err
+	}
+	race.ReadRange(m.address+uintptr(c.position), 1)
+	b := m.memory[c.position]
+	c.position++
+	return b, nil
+}
+
+// Write writes len(buf) bytes at the current position and advances it.
+// The mapped memory can not grow, so if there are not enough space to write all given bytes
+// the fitting part is written and the ErrOutOfBounds error will be returned.
+// Write implements the io.Writer interface.
+func (c *Cursor) Write(buf []byte) (int, error) {
+	m := c.mapping
+	if m.memory == nil {
+		return 0, ErrClosed
+	}
+	if !m.writable {
+		return 0, ErrReadOnly
+	}
+	if c.position >= int64(len(m.memory)) {
+		if len(buf) == 0 {
+			return 0, nil
+		}
+		return 0, outOfBounds()
+	}
+	if err := m.populate(c.position, c.span(len(buf))); err != nil {
+		return 0, err
+	}
+	n := copy(m.memory[c.position:], buf)
+	race.WriteRange(m.address+uintptr(c.position), uintptr(n))
+	m.markDirty(c.position, uintptr(n))
+	c.position += int64(n)
+	if n < len(buf) {
+		return n, outOfBounds()
+	}
+	return n, nil
+}
+
+// WriteTo writes the mapped memory from the current position to the end into the given writer directly
+// without an intermediate buffer and advances the position, so io.Copy from the cursor avoids copying twice.
+// WriteTo implements the io.WriterTo interface.
+func (c *Cursor) WriteTo(w io.Writer) (int64, error) {
+	m := c.mapping
+	if m.memory == nil {
+		return 0, ErrClosed
+	}
+	if c.position >= int64(len(m.memory)) {
+		return 0, nil
+	}
+	if err := m.populate(c.position, uintptr(int64(len(m.memory))-c.position)); err != nil {
+		return 0, err
+	}
+	race.ReadRange(m.address+uintptr(c.position), uintptr(int64(len(m.memory))-c.position))
+	n, err := w.Write(m.memory[c.position:])
+	c.position += int64(n)
+	return int64(n), err
+}
+
+// ReadFrom reads data from the given reader directly into the mapped memory from the current position
+// until io.EOF or the mapped memory is full and advances the position, so io.Copy into the cursor avoids copying twice.
+// ReadFrom implements the io.ReaderFrom interface.
+func (c *Cursor) ReadFrom(r io.Reader) (int64, error) {
+	m := c.mapping
+	if m.memory == nil {
+		return 0, ErrClosed
+	}
+	if !m.writable {
+		return 0, ErrReadOnly
+	}
+	if c.position >= int64(len(m.memory)) {
+		return 0, nil
+	}
+	if err := m.populate(c.position, uintptr(int64(len(m.memory))-c.position)); err != nil {
+		return 0, err
+	}
+	race.WriteRange(m.address+uintptr(c.position), uintptr(int64(len(m.memory))-c.position))
+	n, err := readFull(r, m.memory[c.position:])
+	m.markDirty(c.position, uintptr(n))
+	c.position += n
+	return n, err
+}
+
+// span returns the number of bytes up to the given one which are available from the current position.
+func (c *Cursor) span(n int) uintptr {
+	if rest := int64(len(c.mapping.memory)) - c.position; int64(n) > rest {
+		return uintptr(rest)
+	}
+	return uintptr(n)
+}
+
+// Seek sets the position for the next Read or Write to the given offset,
+// interpreted according to whence: io.SeekStart, io.SeekCurrent or io.SeekEnd.
+// The position may be set beyond the end of the mapped memory,
+// but the ErrBadOffset error will be returned for the negative one.
+// Seek implements the io.Seeker interface.
+func (c *Cursor) Seek(offset int64, whence int) (int64, error) {
+	m := c.mapping
+	if m.memory == nil {
+		return 0, ErrClosed
+	}
+	var base int64
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		base = c.position
+	case io.SeekEnd:
+		base = int64(len(m.memory))
+	default:
+		return 0, ErrBadOffset
+	}
+	if (offset > 0 && base > math.MaxInt64-offset) || base+offset < 0 {
+		return 0, ErrBadOffset
+	}
+	c.position = base + offset
+	return c.position, nil
+}
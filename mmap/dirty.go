@@ -0,0 +1,190 @@
+package mmap
+
+import (
+	"os"
+	"sync/atomic"
+)
+
+// WithDirtyTracking enables tracking of the mapped memory pages which are written by the methods of the mapping,
+// its regions and cursors, so SyncDirty synchronizes only them instead of the whole mapped memory.
+// The writes through the mapped memory, the segment, the transactions or the pointers obtained from it are not tracked,
+// use MarkDirty to report them or WithWriteProtectTracking to track them too. The tracking costs one bit per memory page.
+func WithDirtyTracking() Option {
+	return func(o *options) {
+		o.dirtyTracking = true
+	}
+}
+
+// WithWriteProtectTracking enables the dirty tracking like WithDirtyTracking
+// where the direct writes through the mapped memory, the segment, the transactions and the pointers are tracked too.
+// The pages are write-protected by the userfaultfd and the kernel clears the protection of the page at its first write
+// without any fault handler, so SyncDirty finds the written pages and protects them again.
+// Each first write to the page after the synchronization costs the page fault.
+// Only the anonymous mappings and the files placed on tmpfs or hugetlbfs may be write-protected.
+// Open returns ErrNotSupported if the kernel is older than Linux 6.7 (UFFD_FEATURE_WP_ASYNC),
+// the userfaultfd is not permitted, the file can not be write-protected or the platform is not Linux.
+// The option has no effect on the read-only mappings.
+func WithWriteProtectTracking() Option {
+	return func(o *options) {
+		o.dirtyTracking = true
+		o.writeProtect = true
+	}
+}
+
+// MarkDirty marks the mapped memory pages which contain a part of the range starting from the given offset
+// and ends after the given length as written, e.g. after the direct writes through the segment.
+// It does nothing if the dirty tracking is not enabled by WithDirtyTracking.
+func (m *Mapping) MarkDirty(offset int64, length uintptr) error {
+	if m.memory == nil {
+		return ErrClosed
+	}
+	if length > uintptr(MaxInt) {
+		return outOfBounds()
+	}
+	if err := m.access(offset, int(length)); err != nil {
+		return err
+	}
+	m.markDirty(offset, length)
+	return nil
+}
+
+// SyncDirty synchronizes only the written mapped memory pages with the underlying file
+// merging the adjacent ones into the single extents.
+// The whole mapped memory is synchronized if the dirty tracking is not enabled by WithDirtyTracking.
+// The pages which fail to be synchronized remain written.
+func (m *Mapping) SyncDirty() error {
+	if m.memory == nil {
+		return ErrClosed
+	}
+	if !m.writable {
+		return ErrReadOnly
+	}
+	if m.dirty == nil {
+		return m.sync(m.alignedMemory)
+	}
+	return m.syncDirty()
+}
+
+// syncDirty synchronizes the written mapped memory pages with the underlying file.
+func (m *Mapping) syncDirty() error {
+	var errs []error
+	if m.wp != nil {
+		if err := m.collectWriteProtected(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	pageSize := os.Getpagesize()
+	low, high := -1, -1
+	flush := func() {
+		if low < 0 {
+			return
+		}
+		b := m.alignedMemory[low*pageSize:]
+		if n := (high - low) * pageSize; n < len(b) {
+			b = b[:n]
+		}
+		if err := m.sync(b); err != nil {
+			m.markPages(low, high)
+			errs = append(errs, err)
+		}
+		low, high = -1, -1
+	}
+	for i := range m.dirty {
+		word := atomic.SwapUint64(&m.dirty[i], 0)
+		for bit := 0; bit < 64; bit++ {
+			if word&(1<<bit) == 0 {
+				continue
+			}
+			page := i*64 + bit
+			if page != high {
+				flush()
+				low = page
+			}
+			high = page + 1
+		}
+	}
+	flush()
+	return joinErrors(errs)
+}
+
+// markDirty marks the mapped memory pages which contain a part of the given range as written
+// if the dirty tracking is enabled. The given range must be valid.
+func (m *Mapping) markDirty(offset int64, length uintptr) {
+	if m.dirty == nil || length == 0 {
+		return
+	}
+	pageSize := uintptr(os.Getpagesize())
+	low := m.address - addressOf(m.alignedMemory) + uintptr(offset)
+	m.markPages(int(low/pageSize), int((low+length-1)/pageSize)+1)
+}
+
+// markPages marks the mapped memory pages of the given range of indices as written.
+func (m *Mapping) markPages(low, high int) {
+	for page := low; page < high; {
+		i, bit := page/64, page%64
+		n := 64 - bit
+		if n > high-page {
+			n = high - page
+		}
+		mask := ^uint64(0) >> (64 - n) << bit
+		for {
+			old := atomic.LoadUint64(&m.dirty[i])
+			if old&mask == mask || atomic.CompareAndSwapUint64(&m.dirty[i], old, old|mask) {
+				break
+			}
+		}
+		page += n
+	}
+}
+
+// ResetSoftDirty clears the soft-dirty bits of the memory pages, so the pages which are written after it
+// are reported by Mapping.SoftDirty regardless of how they are written, including the direct writes.
+// The bits are cleared for all pages of the process, so the callers should agree on the moment of the reset.
+// The soft-dirty bits are available on Linux with CONFIG_MEM_SOFT_DIRTY only, ErrNotSupported returns otherwise.
+// Since the kernel without it accepts the reset but never sets the bits, the support is probed at the first reset
+// by writing the scratch page.
+func ResetSoftDirty() error {
+	return resetSoftDirty()
+}
+
+// SoftDirty reports which mapped memory pages that contain a part of the range
+// starting from the given offset and ends after the given length are written since the last ResetSoftDirty.
+// The first element of the result corresponds to the page which contains the given offset.
+// The soft-dirty bits are available on Linux only, ErrNotSupported returns otherwise
+// or if ResetSoftDirty has found that they are not supported by the kernel.
+func (m *Mapping) SoftDirty(offset int64, length uintptr) ([]bool, error) {
+	if m.memory == nil {
+		return nil, ErrClosed
+	}
+	b, err := m.pages(offset, length)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return []bool{}, nil
+	}
+	return m.softDirtyMemory(b)
+}
+
+// MarkSoftDirty marks the mapped memory pages which are written since the last ResetSoftDirty as written,
+// so SyncDirty synchronizes the pages which are written directly without MarkDirty.
+// It does nothing if the dirty tracking is not enabled by WithDirtyTracking.
+// The ErrNotSupported returns if the soft-dirty bits are not supported, see SoftDirty.
+func (m *Mapping) MarkSoftDirty() error {
+	if m.memory == nil {
+		return ErrClosed
+	}
+	if m.dirty == nil {
+		return nil
+	}
+	dirty, err := m.softDirtyMemory(m.alignedMemory)
+	if err != nil {
+		return err
+	}
+	for i, d := range dirty {
+		if d {
+			m.markPages(i, i+1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package mmap
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// dupCloexec duplicates the given file descriptor with the close-on-exec flag set.
+// There is no F_DUPFD_CLOEXEC, so the duplication is guarded against the concurrent fork.
+func dupCloexec(fd int) (int, error) {
+	syscall.ForkLock.RLock()
+	defer syscall.ForkLock.RUnlock()
+	nfd, err := unix.Dup(fd)
+	if err != nil {
+		return -1, err
+	}
+	unix.CloseOnExec(nfd)
+	return nfd, nil
+}
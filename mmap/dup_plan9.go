@@ -0,0 +1,8 @@
+package mmap
+
+import "syscall"
+
+// dup duplicates the given file descriptor.
+func dup(fd int) (int, error) {
+	return syscall.Dup(fd, -1)
+}
@@ -0,0 +1,10 @@
+//go:build unix && !aix
+
+package mmap
+
+import "golang.org/x/sys/unix"
+
+// dupCloexec duplicates the given file descriptor with the close-on-exec flag set.
+func dupCloexec(fd int) (int, error) {
+	return unix.FcntlInt(uintptr(fd), unix.F_DUPFD_CLOEXEC, 0)
+}
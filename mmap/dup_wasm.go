@@ -0,0 +1,9 @@
+//go:build js || wasip1
+
+package mmap
+
+// dup returns the given file descriptor as is
+// since the duplication is not supported on this platform.
+func dup(fd int) (int, error) {
+	return fd, nil
+}
@@ -1,6 +1,19 @@
 package mmap
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrAddressUnavailable is an error which returns when the mapped memory can not be placed at the given address.
+var ErrAddressUnavailable = fmt.Errorf("mmap: address unavailable")
+
+// ErrBadAddress is an error which returns when the given address is not valid.
+var ErrBadAddress = fmt.Errorf("mmap: bad address")
+
+// ErrBadAdvice is an error which returns when the given advice is not valid.
+var ErrBadAdvice = fmt.Errorf("mmap: bad advice")
 
 // ErrBadOffset is an error which returns when the given length is not valid.
 var ErrBadLength = fmt.Errorf("mmap: bad length")
@@ -8,20 +21,43 @@ var ErrBadLength = fmt.Errorf("mmap: bad length")
 // ErrBadMode is an error which returns when the given mapping mode is not valid.
 var ErrBadMode = fmt.Errorf("mmap: bad mode")
 
+// ErrBadName is an error which returns when the given name of the shared memory is not valid.
+var ErrBadName = fmt.Errorf("mmap: bad name")
+
 // ErrBadOffset is an error which returns when the given offset is not valid.
 var ErrBadOffset = fmt.Errorf("mmap: bad offset")
 
 // ErrClosed is the error which returns when tries to access the closed mapping.
 var ErrClosed = fmt.Errorf("mmap: mapping closed")
 
+// ErrFileExists is an error which returns when the file which must be created already exists.
+// It matches os.ErrExist.
+var ErrFileExists = fmt.Errorf("mmap: %w", os.ErrExist)
+
+// ErrFileNotExist is an error which returns when the file which must exist does not exist.
+// It matches os.ErrNotExist.
+var ErrFileNotExist = fmt.Errorf("mmap: %w", os.ErrNotExist)
+
 // ErrLocked is the error which returns when the mapping memory pages were already locked.
 var ErrLocked = fmt.Errorf("mmap: mapping already locked")
 
 // ErrNotLocked is the error which returns when the mapping memory pages are not locked.
 var ErrNotLocked = fmt.Errorf("mmap: mapping is not locked")
 
+// ErrNotSupported is the error which returns when the operation is not supported on the current platform.
+var ErrNotSupported = fmt.Errorf("mmap: operation not supported")
+
 // ErrOutOfBounds is the error which returns when tries to accessing the offset which is out of the available bounds.
 var ErrOutOfBounds = fmt.Errorf("mmap: out of bounds")
 
 // ErrReadOnly is the error which returns when tries to execute a write operation on the read-only mapping.
 var ErrReadOnly = fmt.Errorf("mmap: mapping is read only")
+
+// joinErrors returns the single given error as is, so it may be compared with the predefined errors,
+// or all given errors joined by errors.Join, so none of them is lost.
+func joinErrors(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
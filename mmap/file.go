@@ -1,17 +1,137 @@
 package mmap
 
-import "os"
+import (
+	"math"
+	"os"
+)
 
 // OpenFile prepares a file, calls the initializer if file was just created
+// or the validator given by WithValidator if file already exists
 // and returns a new mapping of the prepared file into the memory.
-func OpenFile(name string, perm os.FileMode, size uintptr, flags Flag, init func(m *Mapping) error) (*Mapping, error) {
+func OpenFile(name string, perm os.FileMode, size uintptr, flags Flag, init func(m *Mapping) error, opts ...Option) (*Mapping, error) {
+	return OpenFileMode(name, perm, size, ModeReadWrite, flags, init, opts...)
+}
+
+// OpenFileMode is like OpenFile but maps the file in the given mode.
+// In ModeReadWrite the file is created if it does not exist and truncated to the given size.
+// In ModeReadOnly and ModeWriteCopy the file must exist and it is never changed,
+// so the initializer is never called and the permissions are ignored.
+// If the given size is zero, the whole file is mapped in such modes.
+func OpenFileMode(
+	name string, perm os.FileMode, size uintptr, mode Mode, flags Flag, init func(m *Mapping) error, opts ...Option,
+) (*Mapping, error) {
+	if uint64(size) > math.MaxInt64 {
+		return nil, ErrBadLength
+	}
+	return openFile(name, os.O_CREATE, perm, 0, size, mode, flags, init, func(f *os.File) error {
+		return f.Truncate(int64(size))
+	}, opts)
+}
+
+// CreateFile is like OpenFile but creates a new file exclusively.
+// The ErrFileExists returns if the file already exists, e.g. it is created concurrently by another process.
+// The initializer is always called on success.
+func CreateFile(name string, perm os.FileMode, size uintptr, flags Flag, init func(m *Mapping) error, opts ...Option) (*Mapping, error) {
+	if uint64(size) > math.MaxInt64 {
+		return nil, ErrBadLength
+	}
+	return openFile(name, os.O_CREATE|os.O_EXCL, perm, 0, size, ModeReadWrite, flags, init, func(f *os.File) error {
+		return f.Truncate(int64(size))
+	}, opts)
+}
+
+// OpenExistingFile is like OpenFileMode but never creates a new file.
+// The ErrFileNotExist returns if the file does not exist.
+// In ModeReadWrite the file is truncated to the given size if it is not zero.
+// If the given size is zero, the whole file is mapped.
+func OpenExistingFile(name string, size uintptr, mode Mode, flags Flag, opts ...Option) (*Mapping, error) {
+	if uint64(size) > math.MaxInt64 {
+		return nil, ErrBadLength
+	}
+	return openFile(name, 0, 0, 0, size, mode, flags, nil, func(f *os.File) error {
+		if size == 0 {
+			return nil
+		}
+		return f.Truncate(int64(size))
+	}, opts)
+}
+
+// OpenFileRegion is like OpenFileMode but maps only the region of the file
+// starting from the given offset and ends after the given length.
+// In ModeReadWrite the file is extended to at least the end of the region, it is never shrunk.
+// In ModeReadOnly and ModeWriteCopy the region must be within the file.
+// If the given length is zero, the file is mapped from the given offset to its end in such modes.
+// The offset needs not be aligned, the underlying view starts from its preceding multiple of Granularity.
+func OpenFileRegion(
+	name string, perm os.FileMode, offset int64, length uintptr, mode Mode, flags Flag,
+	init func(m *Mapping) error, opts ...Option,
+) (*Mapping, error) {
+	if offset < 0 {
+		return nil, ErrBadOffset
+	}
+	if uint64(length) > uint64(math.MaxInt64-offset) {
+		return nil, ErrBadLength
+	}
+	return openFile(name, os.O_CREATE, perm, offset, length, mode, flags, init, extendFile(offset, length), opts)
+}
+
+// extendFile returns the truncation function which extends the file
+// to at least the end of the region starting from the given offset and ends after the given length.
+func extendFile(offset int64, length uintptr) func(f *os.File) error {
+	return func(f *os.File) error {
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if size := offset + int64(length); info.Size() < size {
+			return f.Truncate(size)
+		}
+		return nil
+	}
+}
+
+// openFile prepares a file opened with the given creation flags (os.O_CREATE and os.O_EXCL)
+// using the given truncation function in ModeReadWrite, calls the initializer if file was just created
+// and returns a new mapping of the given region of the prepared file into the memory.
+func openFile(
+	name string, flag int, perm os.FileMode, offset int64, length uintptr, mode Mode, flags Flag,
+	init func(m *Mapping) error, truncate func(f *os.File) error, opts []Option,
+) (*Mapping, error) {
+	if mode < ModeReadOnly || mode > ModeWriteCopy {
+		return nil, ErrBadMode
+	}
+	validate := newOptions(opts).validate
+	if mode != ModeReadWrite {
+		m, err := mapUnchangedFile(name, offset, length, mode, flags, opts)
+		if flag&os.O_CREATE == 0 && os.IsNotExist(err) {
+			return nil, ErrFileNotExist
+		}
+		if err != nil {
+			return nil, err
+		}
+		if validate != nil {
+			if err := validate(m); err != nil {
+				_ = m.Close()
+				return nil, err
+			}
+		}
+		return m, nil
+	}
 	m, created, err := func() (*Mapping, bool, error) {
-		created := false
-		if _, err := os.Stat(name); err != nil && os.IsNotExist(err) {
-			created = true
+		created := flag&os.O_EXCL != 0
+		if flag&os.O_CREATE != 0 && !created {
+			if _, err := os.Stat(name); err != nil && os.IsNotExist(err) {
+				created = true
+			}
 		}
-		f, err := os.OpenFile(name, os.O_CREATE|os.O_RDWR, perm)
+		f, err := os.OpenFile(name, flag|os.O_RDWR, perm)
 		if err != nil {
+			if flag&os.O_EXCL != 0 && os.IsExist(err) {
+				return nil, false, ErrFileExists
+			}
+			if flag&os.O_CREATE == 0 && os.IsNotExist(err) {
+				return nil, false, ErrFileNotExist
+			}
 			return nil, false, err
 		}
 		defer func() {
@@ -26,11 +146,11 @@ func OpenFile(name string, perm os.FileMode, size uintptr, flags Flag, init func
 				_ = os.Remove(name)
 			}
 		}
-		if err := f.Truncate(int64(size)); err != nil {
+		if err := truncate(f); err != nil {
 			onFailure()
 			return nil, false, err
 		}
-		m, err := Open(f.Fd(), 0, size, ModeReadWrite, flags)
+		m, err := Open(f.Fd(), offset, length, ModeReadWrite, flags, append(opts[:len(opts):len(opts)], withPath(name))...)
 		if err != nil {
 			onFailure()
 			return nil, false, err
@@ -47,5 +167,29 @@ func OpenFile(name string, perm os.FileMode, size uintptr, flags Flag, init func
 			return nil, err
 		}
 	}
+	if !created && validate != nil {
+		if err := validate(m); err != nil {
+			_ = m.Close()
+			return nil, err
+		}
+	}
 	return m, nil
 }
+
+// mapUnchangedFile returns a new mapping of the given region of the existing file into the memory
+// in the given mode which does not change the file.
+func mapUnchangedFile(name string, offset int64, length uintptr, mode Mode, flags Flag, opts []Option) (*Mapping, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if offset > info.Size() || uint64(length) > uint64(info.Size()-offset) {
+		return nil, ErrBadLength
+	}
+	return Open(f.Fd(), offset, length, mode, flags, append(opts[:len(opts):len(opts)], withPath(name))...)
+}
@@ -0,0 +1,10 @@
+//go:build js || wasip1
+
+package mmap
+
+import "syscall"
+
+// ftruncate changes the size of the file with the given descriptor.
+func ftruncate(fd int, size int64) error {
+	return syscall.Ftruncate(fd, size)
+}
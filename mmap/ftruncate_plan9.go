@@ -0,0 +1,16 @@
+package mmap
+
+import "syscall"
+
+// ftruncate changes the size of the file with the given descriptor.
+func ftruncate(fd int, size int64) error {
+	var d syscall.Dir
+	d.Null()
+	d.Length = size
+	var buf [syscall.STATFIXLEN]byte
+	n, err := d.Marshal(buf[:])
+	if err != nil {
+		return err
+	}
+	return syscall.Fwstat(fd, buf[:n])
+}
@@ -0,0 +1,57 @@
+package mmap
+
+// GrowableMapping is a mapping which grows automatically when the data is written beyond its end.
+// The underlying file is extended and mapped again, so the mapped memory, the address
+// and the segment which are obtained before the growth must not be used after it.
+type GrowableMapping struct {
+	*Mapping
+	// growthFactor specifies the minimal ratio of the new length to the current one on the growth.
+	growthFactor float64
+}
+
+// Growable returns a new growable wrapper of the given writable mapping.
+// On the growth the length of the mapping is multiplied by the given growth factor at least,
+// the factor which is less than 1 means that the mapping grows exactly to the end of the written data.
+// The underlying file remains extended when the mapping is closed, use Truncate to cut it.
+func Growable(m *Mapping, growthFactor float64) *GrowableMapping {
+	if growthFactor < 1 {
+		growthFactor = 1
+	}
+	return &GrowableMapping{Mapping: m, growthFactor: growthFactor}
+}
+
+// WriteAt writes len(buf) bytes at the given offset from start of the mapped memory into the mapped memory
+// growing the mapping if there are not enough space to write all given bytes.
+// WriteAt implements the io.WriterAt interface.
+func (g *GrowableMapping) WriteAt(buf []byte, offset int64) (int, error) {
+	if g.memory == nil {
+		return 0, ErrClosed
+	}
+	if offset >= 0 && uint64(len(buf)) <= uint64(MaxInt)-uint64(offset) {
+		if end := uintptr(offset) + uintptr(len(buf)); end > g.Length() {
+			if err := g.Grow(end); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return g.Mapping.WriteAt(buf, offset)
+}
+
+// Grow makes the length of the mapping at least the given length
+// extending the underlying file and mapping it again if it is necessary.
+func (g *GrowableMapping) Grow(length uintptr) error {
+	if g.memory == nil {
+		return ErrClosed
+	}
+	current := g.Length()
+	if length <= current {
+		return nil
+	}
+	target := length
+	if grown := float64(current) * g.growthFactor; grown >= float64(MaxInt) {
+		target = uintptr(MaxInt)
+	} else if uintptr(grown) > target {
+		target = uintptr(grown)
+	}
+	return g.resize(target)
+}
@@ -0,0 +1,16 @@
+//go:build darwin || linux
+
+package mmap
+
+// flushInstructionCache makes the instructions which are written into the given memory visible
+// to the instruction fetch, the data and instruction caches are not coherent on ARM64.
+func flushInstructionCache(b []byte) bool {
+	if len(b) > 0 {
+		flushICache(addressOf(b), uintptr(len(b)))
+	}
+	return true
+}
+
+// flushICache cleans the data cache and invalidates the instruction cache lines
+// which contain the memory of the given length starting from the given address.
+func flushICache(address, length uintptr)
@@ -0,0 +1,37 @@
+//go:build darwin || linux
+
+#include "textflag.h"
+
+// func flushICache(address, length uintptr)
+TEXT ·flushICache(SB), NOSPLIT, $0-16
+	MOVD address+0(FP), R0
+	MOVD length+8(FP), R1
+	ADD R0, R1, R1
+	// The cache line sizes are encoded by CTR_EL0 as log2 of the number of words.
+	MRS CTR_EL0, R2
+	MOVD $4, R4
+	UBFX $16, R2, $4, R3
+	LSL R3, R4, R3
+	AND $15, R2, R5
+	LSL R5, R4, R5
+	// Clean the data cache lines to the point of unification.
+	SUB $1, R3, R6
+	BIC R6, R0, R6
+dloop:
+	DC CVAU, R6
+	ADD R3, R6, R6
+	CMP R1, R6
+	BLO dloop
+	DSB $11
+	// Invalidate the instruction cache lines to the point of unification.
+	SUB $1, R5, R6
+	BIC R6, R0, R6
+iloop:
+	// IC IVAU, R6 which is not known by the assembler.
+	WORD $0xd50b7526
+	ADD R5, R6, R6
+	CMP R1, R6
+	BLO iloop
+	DSB $11
+	ISB $15
+	RET
@@ -0,0 +1,8 @@
+//go:build (386 || amd64 || s390x) && (darwin || linux)
+
+package mmap
+
+// flushInstructionCache does nothing since the data and instruction caches are coherent on this architecture.
+func flushInstructionCache(b []byte) bool {
+	return true
+}
@@ -0,0 +1,9 @@
+//go:build !windows && !((386 || amd64 || arm64 || s390x) && (darwin || linux))
+
+package mmap
+
+// flushInstructionCache returns false since the instruction cache can not be flushed on this platform
+// or the mapped memory is emulated and can not be executed.
+func flushInstructionCache(b []byte) bool {
+	return false
+}
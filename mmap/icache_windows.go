@@ -0,0 +1,13 @@
+package mmap
+
+import "golang.org/x/sys/windows"
+
+// flushInstructionCache makes the instructions which are written into the given memory visible
+// to the instruction fetch by FlushInstructionCache.
+func flushInstructionCache(b []byte) bool {
+	if len(b) == 0 {
+		return true
+	}
+	r, _, _ := procFlushInstructionCache.Call(uintptr(windows.CurrentProcess()), addressOf(b), uintptr(len(b)))
+	return r != 0
+}
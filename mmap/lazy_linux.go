@@ -0,0 +1,112 @@
+//go:build linux && (386 || amd64 || arm || arm64 || loong64 || riscv64 || s390x)
+
+package mmap
+
+import (
+	"errors"
+	"os"
+	"sync/atomic"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// lazyPager is the userfaultfd object which supplies the missing pages of the lazy mapping, see OpenLazy.
+// The access to the missing page raises SIGBUS (UFFD_FEATURE_SIGBUS) instead of waiting for the handler,
+// so the page faults never block the thread which holds the processor of the Go runtime.
+type lazyPager struct {
+	// fd specifies the userfaultfd descriptor.
+	fd int
+	// fill specifies the function which supplies the contents of the pages.
+	fill func(offset int64, page []byte) error
+	// populated specifies the bitmap of the supplied mapped memory pages.
+	populated []uint64
+}
+
+// OpenLazy opens and returns a new anonymous mapping which pages are supplied by the given function
+// on first access, e.g. fetched from the object storage or decompressed, so the mapping is a demand-paged virtual file.
+// The function is called with the offset of the page from start of the mapped memory
+// and the zeroed buffer of the memory page size to fill, the bytes beyond the end of the mapped memory are ignored.
+// It is called from the goroutine which accesses the page, possibly concurrently for the distinct pages
+// and rarely twice for the same page, the error it returns is returned by the accessing method.
+//
+// The pages are supplied by the methods of the mapping, its regions and cursors before they access the memory
+// and by Populate. The direct access through the mapped memory, the segment, the transactions or the pointers
+// to the page which is not supplied yet raises SIGBUS, which crashes the program
+// unless debug.SetPanicOnFault is enabled, so Populate the range before such access.
+// The pages which are discarded by Evict or AdviceDontNeed are supplied again at the next access.
+// The Linux 4.14 or newer is required, the ErrNotSupported returns on other platforms
+// or if the userfaultfd is not available.
+func OpenLazy(length uintptr, mode Mode, fill func(offset int64, page []byte) error) (*Mapping, error) {
+	m, err := OpenAnonymous(length, mode, 0)
+	if err != nil {
+		return nil, err
+	}
+	fd, err := openUserfaultfd(m.alignedMemory, uffdFeatureSigbus, uffdioRegisterModeMissing)
+	if err != nil {
+		_ = m.Close()
+		return nil, err
+	}
+	m.lazy = &lazyPager{fd: fd, fill: fill, populated: make([]uint64, (pageCount(m.alignedMemory)+63)/64)}
+	return m, nil
+}
+
+// populate supplies the missing mapped memory pages from the given low one to the given high one exclusively.
+func (p *lazyPager) populate(m *Mapping, low, high int) error {
+	pageSize := os.Getpagesize()
+	var page []byte
+	for i := low; i < high; i++ {
+		if atomic.LoadUint64(&p.populated[i/64])&(1<<(i%64)) != 0 {
+			continue
+		}
+		if page == nil {
+			page = make([]byte, pageSize)
+		} else {
+			for j := range page {
+				page[j] = 0
+			}
+		}
+		if err := p.fill(int64(i*pageSize), page); err != nil {
+			return err
+		}
+		arg := uffdioCopyArg{
+			dst: uint64(addressOf(m.alignedMemory[i*pageSize:])),
+			src: uint64(uintptr(unsafe.Pointer(&page[0]))),
+			len: uint64(pageSize),
+		}
+		// EEXIST means the page is already supplied by the concurrent access.
+		if err := uffdIoctl(p.fd, uffdioCopy, unsafe.Pointer(&arg)); err != nil && !errors.Is(err, unix.EEXIST) {
+			return err
+		}
+		p.mark(i, true)
+	}
+	return nil
+}
+
+// forget marks the mapped memory pages from the given low one to the given high one exclusively as missing
+// after they are discarded.
+func (p *lazyPager) forget(low, high int) {
+	for i := low; i < high; i++ {
+		p.mark(i, false)
+	}
+}
+
+// mark marks the given mapped memory page as supplied or missing.
+func (p *lazyPager) mark(page int, populated bool) {
+	word, mask := &p.populated[page/64], uint64(1)<<(page%64)
+	for {
+		old := atomic.LoadUint64(word)
+		next := old &^ mask
+		if populated {
+			next = old | mask
+		}
+		if old == next || atomic.CompareAndSwapUint64(word, old, next) {
+			return
+		}
+	}
+}
+
+// stop closes the userfaultfd object.
+func (p *lazyPager) stop() {
+	_ = unix.Close(p.fd)
+}
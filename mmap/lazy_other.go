@@ -0,0 +1,22 @@
+//go:build !linux || !(386 || amd64 || arm || arm64 || loong64 || riscv64 || s390x)
+
+package mmap
+
+// lazyPager is the stub of the userfaultfd object which is available on Linux only.
+type lazyPager struct{}
+
+// OpenLazy returns ErrNotSupported since the userfaultfd is available on Linux only.
+func OpenLazy(length uintptr, mode Mode, fill func(offset int64, page []byte) error) (*Mapping, error) {
+	return nil, ErrNotSupported
+}
+
+// populate does nothing since the lazy mapping is never opened.
+func (p *lazyPager) populate(m *Mapping, low, high int) error {
+	return nil
+}
+
+// forget does nothing since the lazy mapping is never opened.
+func (p *lazyPager) forget(low, high int) {}
+
+// stop does nothing since the lazy mapping is never opened.
+func (p *lazyPager) stop() {}
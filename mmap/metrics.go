@@ -0,0 +1,43 @@
+package mmap
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Metrics is a receiver of the mapping metrics, e.g. the exporter to Prometheus.
+// Its methods are called synchronously from any goroutine, so they must be fast and concurrency-safe.
+type Metrics interface {
+	// Opened is called when a new mapping of the given length in bytes is opened.
+	Opened(length uintptr)
+	// Closed is called when the mapping of the given length in bytes is closed.
+	Closed(length uintptr)
+	// Synced is called when the given length in bytes of the mapped memory
+	// is synchronized with the underlying file for the given duration with the given error if any.
+	Synced(length uintptr, duration time.Duration, err error)
+	// LockFailed is called when the locking of the mapped memory fails with the given error.
+	LockFailed(err error)
+	// OutOfBounds is called when the access out of the mapped memory bounds is rejected.
+	OutOfBounds()
+}
+
+// metricsHolder holds the current metrics receiver.
+type metricsHolder struct {
+	metrics Metrics
+}
+
+// currentMetrics specifies the current metrics receiver of all mappings.
+var currentMetrics atomic.Value
+
+// SetMetrics sets the metrics receiver of all mappings, the nil one disables the metrics.
+// The metrics are collected for the mappings which are opened after the call as well as for the existing ones,
+// so the receiver should be set before any mapping is opened to keep the counts of them consistent.
+func SetMetrics(metrics Metrics) {
+	currentMetrics.Store(metricsHolder{metrics})
+}
+
+// loadMetrics returns the current metrics receiver or nil if the metrics are disabled.
+func loadMetrics() Metrics {
+	h, _ := currentMetrics.Load().(metricsHolder)
+	return h.metrics
+}
@@ -2,8 +2,15 @@
 package mmap
 
 import (
+	"io"
 	"math"
+	"os"
+	"runtime"
+	"sync/atomic"
+	"time"
+	"unsafe"
 
+	"github.com/alexeymaximov/go-bio/internal/race"
 	"github.com/alexeymaximov/go-bio/segment"
 	"github.com/alexeymaximov/go-bio/transaction"
 )
@@ -11,6 +18,9 @@ import (
 // MaxInt is the maximum platform dependent signed integer.
 const MaxInt = int(^uint(0) >> 1)
 
+// anonymousFd is the file descriptor which specifies the mapping not backed by any file.
+const anonymousFd = ^uintptr(0)
+
 // Mode is a mapping mode.
 type Mode int
 
@@ -38,6 +48,59 @@ type Flag int
 const (
 	// Mapped memory pages may be executed.
 	FlagExecutable Flag = 1 << iota
+
+	// Mapped memory should be backed by huge (large) pages to reduce the TLB pressure.
+	// It is a best effort, the regular pages are used if huge pages are not available.
+	// On Linux the anonymous mapping is backed by the reserved huge pages (MAP_HUGETLB)
+	// if possible, otherwise transparent huge pages are advised.
+	// On Windows only the anonymous mapping may be backed by large pages,
+	// it requires the SeLockMemoryPrivilege privilege which is enabled automatically if it is granted to the user.
+	FlagHugePages
+
+	// Mapped memory must be placed exactly at the address given by WithAddress.
+	// Existing mappings are never replaced, ErrAddressUnavailable returns instead.
+	FlagFixed
+
+	// Mapped memory is not inherited by the child processes created by fork,
+	// so huge mappings are not duplicated when helper processes are spawned.
+	// It has no effect on Windows where there is no fork.
+	FlagDontFork
+
+	// Mapped memory is inherited by the child processes created by fork filled with zeros,
+	// so the secret data is not leaked into them.
+	// On Linux it is applicable to the private anonymous mappings only.
+	// It has no effect on Windows where there is no fork, ErrNotSupported returns on Darwin.
+	FlagWipeOnFork
+
+	// Mapped memory is the persistent memory (DAX) of the underlying file which is placed on NVDIMM.
+	// The mapping is created with MAP_SYNC, so the file metadata is always consistent
+	// and Sync flushes the CPU caches in the user space instead of the system call when it is possible.
+	// It is applicable to the shared file mappings on Linux only, ErrNotSupported returns otherwise.
+	FlagPersistentMemory
+)
+
+// Advice is a hint about the expected access pattern of the mapped memory.
+type Advice int
+
+const (
+	// No special treatment.
+	AdviceNormal Advice = iota
+
+	// Expect the access in the sequential order.
+	// Mapped memory pages may be aggressively read ahead and freed soon after the access.
+	AdviceSequential
+
+	// Expect the access in the random order.
+	// Read ahead of mapped memory pages may be less useful than normally.
+	AdviceRandom
+
+	// Expect the access in the near future.
+	// Mapped memory pages may be read ahead.
+	AdviceWillNeed
+
+	// Do not expect the access in the near future.
+	// Mapped memory pages may be freed.
+	AdviceDontNeed
 )
 
 // generic is a cross-platform parts of a mapping.
@@ -52,6 +115,188 @@ type generic struct {
 	memory []byte
 	// segment specifies the lazily initialized data segment on top of the mapped memory.
 	segment *segment.Segment
+	// alignedMemory specifies the byte slice which wraps the mapped memory
+	// aligned by the memory page size.
+	alignedMemory []byte
+	// locked specifies whether the mapped memory is locked.
+	locked bool
+	// persistent specifies whether the mapped memory is the persistent memory.
+	persistent bool
+	// raiseLockLimit specifies whether the locked memory limit of the process may be raised on locking.
+	raiseLockLimit bool
+	// file specifies the mapped file which is closed together with the mapping if any.
+	file *os.File
+	// path specifies the path of the mapped file if it is known.
+	path string
+	// dirty specifies the bitmap of the written mapped memory pages if the dirty tracking is enabled.
+	dirty []uint64
+	// lazy specifies the supplier of the missing pages of the lazy mapping if any, see OpenLazy.
+	lazy *lazyPager
+	// wp specifies the write protection of the mapped memory pages if the direct writes are tracked,
+	// see WithWriteProtectTracking.
+	wp *writeProtector
+	// autoSync specifies the background synchronization of the mapped memory if any.
+	autoSync *autoSyncer
+	// watcher specifies the watcher of the external modifications of the mapped file if any, see Watch.
+	watcher *watcher
+	// fileOffset specifies the offset of the mapped memory from start of the file.
+	fileOffset int64
+	// mode specifies the mapping mode.
+	mode Mode
+	// flags specifies the mapping flags.
+	flags Flag
+	// options specifies the mapping options which are used to map the file again.
+	options options
+}
+
+// Open opens and returns a new mapping of the given file into the memory.
+// The given file descriptor will be duplicated. It means that
+// if the parent file will be closed the mapping will still be valid.
+// Actual offset and length may be different than the given
+// by the reason of aligning to the memory page size.
+// If the given length is zero, the file or the block device is mapped from the given offset to its end.
+func Open(fd uintptr, offset int64, length uintptr, mode Mode, flags Flag, opts ...Option) (*Mapping, error) {
+	tracer := loadTracer()
+	if tracer == nil {
+		return open(fd, offset, length, mode, flags, opts)
+	}
+	start := time.Now()
+	m, err := open(fd, offset, length, mode, flags, opts)
+	e := Event{Op: OpOpen, Length: length, Start: start, Duration: time.Since(start), Err: err}
+	if m != nil {
+		e.Mapping, e.Length = m, m.Length()
+	}
+	tracer.Trace(e)
+	return m, err
+}
+
+// open opens and returns a new mapping of the given file into the memory, see Open.
+func open(fd uintptr, offset int64, length uintptr, mode Mode, flags Flag, opts []Option) (*Mapping, error) {
+
+	// Using int64 (off_t) for the offset and uintptr (size_t) for the length
+	// by the reason of the compatibility.
+	if offset < 0 {
+		return nil, ErrBadOffset
+	}
+	if length > uintptr(MaxInt) {
+		return nil, ErrBadLength
+	}
+	if mode < ModeReadOnly || mode > ModeWriteCopy {
+		return nil, ErrBadMode
+	}
+	if length == 0 && fd != anonymousFd {
+		size, err := fileSize(fd)
+		if err != nil {
+			return nil, err
+		}
+		if offset >= size || uint64(size-offset) > uint64(MaxInt) {
+			return nil, ErrBadLength
+		}
+		length = uintptr(size - offset)
+	}
+
+	o := newOptions(opts)
+	m := &Mapping{}
+	m.writable = mode > ModeReadOnly
+	m.executable = flags&FlagExecutable != 0
+	m.persistent = flags&FlagPersistentMemory != 0
+	m.raiseLockLimit = o.raiseLockLimit
+	m.path = o.path
+	m.fileOffset = offset
+	m.mode = mode
+	m.flags = flags
+
+	// The mapping address range must be aligned by the allocation granularity.
+	granularity := int64(allocationGranularity())
+	innerOffset := offset % granularity
+	if uint64(length) > uint64(MaxInt)-uint64(innerOffset) {
+		return nil, ErrBadLength
+	}
+	if o.address != 0 {
+		if o.address < uintptr(innerOffset) || (o.address-uintptr(innerOffset))%uintptr(granularity) != 0 {
+			return nil, ErrBadAddress
+		}
+		o.address -= uintptr(innerOffset)
+	} else if flags&FlagFixed != 0 {
+		return nil, ErrBadAddress
+	}
+	alignedMemory, err := m.mapMemory(fd, offset-innerOffset, uintptr(innerOffset)+length, mode, flags, o)
+	if err != nil {
+		return nil, err
+	}
+	m.alignedMemory = alignedMemory
+	if flags&FlagFixed != 0 && addressOf(alignedMemory) != o.address {
+		_ = m.unmapMemory()
+		return nil, ErrAddressUnavailable
+	}
+	if o.writeProtect && m.writable {
+		if err := m.startWriteProtect(); err != nil {
+			_ = m.unmapMemory()
+			return nil, err
+		}
+	}
+	m.options = *o
+	m.options.address = 0
+	m.memory = alignedMemory[innerOffset : uintptr(innerOffset)+length]
+	m.address = addressOf(m.alignedMemory) + uintptr(innerOffset)
+	race.Register(m.address, length)
+	register(m)
+	if o.dirtyTracking && m.writable {
+		m.dirty = make([]uint64, (pageCount(m.alignedMemory)+63)/64)
+	}
+	if metrics := loadMetrics(); metrics != nil {
+		metrics.Opened(length)
+	}
+
+	if o.autoSync > 0 && mode == ModeReadWrite && fd != anonymousFd {
+		m.startAutoSync(o.autoSync)
+	}
+	m.setFinalizer()
+	return m, nil
+}
+
+// setFinalizer sets the finalizer which is called by the garbage collector for the unreachable mapping.
+// The mapping is closed unless the other behaviour is given by its options.
+func (m *Mapping) setFinalizer() {
+	switch leak := m.options.leak; {
+	case m.options.noFinalizer:
+	case leak != nil:
+		runtime.SetFinalizer(m, leak)
+	default:
+		runtime.SetFinalizer(m, (*Mapping).Close)
+	}
+}
+
+// OpenAnonymous opens and returns a new mapping which is not backed by any file.
+// The mapped memory is initialized with zeros. It is backed by the system paging file on Windows.
+// Synchronization of such mapping does nothing.
+func OpenAnonymous(length uintptr, mode Mode, flags Flag, opts ...Option) (*Mapping, error) {
+	if length == 0 {
+		return nil, ErrBadLength
+	}
+	return Open(anonymousFd, 0, length, mode, flags, opts...)
+}
+
+// OpenFD opens and returns a new mapping of the given file into the memory.
+// It is like Open but keeps the file alive during the opening.
+// The file is closed together with the mapping if WithCloseFile is given,
+// otherwise the file may be closed at any moment since its descriptor is duplicated.
+// The file is never closed if the opening fails.
+func OpenFD(f *os.File, offset int64, length uintptr, mode Mode, flags Flag, opts ...Option) (*Mapping, error) {
+	m, err := Open(f.Fd(), offset, length, mode, flags, append(opts[:len(opts):len(opts)], withPath(f.Name()))...)
+	runtime.KeepAlive(f)
+	if err != nil {
+		return nil, err
+	}
+	if newOptions(opts).closeFile {
+		m.file = f
+	}
+	return m, nil
+}
+
+// addressOf returns the address of the first byte of the given slice.
+func addressOf(b []byte) uintptr {
+	return *(*uintptr)(unsafe.Pointer(&b))
 }
 
 // Writable returns true if the mapped memory pages may be written.
@@ -74,6 +319,47 @@ func (m *Mapping) Length() uintptr {
 	return uintptr(len(m.memory))
 }
 
+// Fd returns the descriptor of the mapped file which is duplicated by the mapping,
+// so the caller may issue its own fcntl locks, fallocate or other calls against the same file.
+// It is the file handle on Windows. The descriptor is owned by the mapping and is valid until it is closed.
+// The ^uintptr(0) returns for the anonymous or closed mapping.
+func (m *Mapping) Fd() uintptr {
+	if m.memory == nil {
+		return anonymousFd
+	}
+	return m.descriptor()
+}
+
+// Offset returns the offset of the mapped memory from start of the file as it was given on opening.
+func (m *Mapping) Offset() int64 {
+	return m.fileOffset
+}
+
+// AlignedOffset returns the offset of the mapped memory from start of the file
+// aligned by Granularity, i.e. the offset of the underlying view.
+func (m *Mapping) AlignedOffset() int64 {
+	return m.fileOffset - int64(m.address-addressOf(m.alignedMemory))
+}
+
+// AlignedLength returns the length of the mapped memory in bytes
+// including the part which precedes the given offset on opening, i.e. the length of the underlying view.
+func (m *Mapping) AlignedLength() uintptr {
+	return uintptr(len(m.alignedMemory))
+}
+
+// Granularity returns the alignment of the file offset and the address of the underlying view of the mapping:
+// the allocation granularity (usually 64 KiB) on Windows and the memory page size on other platforms.
+// The mapping offset needs not be aligned, the view starts from the preceding multiple of the granularity.
+func Granularity() int {
+	return allocationGranularity()
+}
+
+// PageSize returns the memory page size which the mapped memory is protected, locked, advised and synchronized by.
+// The underlying view is aligned by Granularity which is larger than the page size on Windows.
+func (m *Mapping) PageSize() int {
+	return os.Getpagesize()
+}
+
 // Memory returns the byte slice which wraps the mapped memory.
 func (m *Mapping) Memory() []byte {
 	return m.memory
@@ -91,11 +377,81 @@ func (m *Mapping) Segment() *segment.Segment {
 // and returns ErrOutOfBounds error at the access violation.
 func (m *Mapping) access(offset int64, length int) error {
 	if offset < 0 || offset > math.MaxInt64-int64(length) || offset+int64(length) > int64(len(m.memory)) {
-		return ErrOutOfBounds
+		return outOfBounds()
 	}
 	return nil
 }
 
+// outOfBounds reports the access violation to the metrics receiver and returns ErrOutOfBounds error.
+func outOfBounds() error {
+	if metrics := loadMetrics(); metrics != nil {
+		metrics.OutOfBounds()
+	}
+	return ErrOutOfBounds
+}
+
+// pages checks given offset and length to match the available bounds
+// and returns the mapped memory pages which contain the given range
+// or ErrOutOfBounds error at the access violation.
+func (m *Mapping) pages(offset int64, length uintptr) ([]byte, error) {
+	if length > uintptr(MaxInt) {
+		return nil, outOfBounds()
+	}
+	if err := m.access(offset, int(length)); err != nil {
+		return nil, err
+	}
+	pageSize := uintptr(os.Getpagesize())
+	low := m.address - addressOf(m.alignedMemory) + uintptr(offset)
+	high := low + length
+	low -= low % pageSize
+	if rem := high % pageSize; rem != 0 {
+		high += pageSize - rem
+	}
+	if high > uintptr(len(m.alignedMemory)) {
+		high = uintptr(len(m.alignedMemory))
+	}
+	return m.alignedMemory[low:high], nil
+}
+
+// populate supplies the missing pages of the lazy mapping which contain a part of the range
+// starting from the given offset and ends after the given length which is within the available bounds.
+// It does nothing for other mappings.
+func (m *Mapping) populate(offset int64, length uintptr) error {
+	if m.lazy == nil || length == 0 {
+		return nil
+	}
+	pageSize := os.Getpagesize()
+	low := int(m.address-addressOf(m.alignedMemory)) + int(offset)
+	high := low + int(length)
+	return m.lazy.populate(m, low/pageSize, (high+pageSize-1)/pageSize)
+}
+
+// forget marks the given discarded pages of the lazy mapping as missing. It does nothing for other mappings.
+func (m *Mapping) forget(b []byte) {
+	if m.lazy == nil || len(b) == 0 {
+		return
+	}
+	low := int(addressOf(b)-addressOf(m.alignedMemory)) / os.Getpagesize()
+	m.lazy.forget(low, low+pageCount(b))
+}
+
+// Populate supplies the missing pages of the lazy mapping which contain a part of the range
+// starting from the given offset and ends after the given length, see OpenLazy,
+// so the range may be accessed directly through the mapped memory, the segment or the transactions.
+// It does nothing for other mappings.
+func (m *Mapping) Populate(offset int64, length uintptr) error {
+	if m.memory == nil {
+		return ErrClosed
+	}
+	if length > uintptr(MaxInt) {
+		return outOfBounds()
+	}
+	if err := m.access(offset, int(length)); err != nil {
+		return err
+	}
+	return m.populate(offset, length)
+}
+
 // ReadAt reads len(buf) bytes at the given offset from start of the mapped memory from the mapped memory.
 // If the given offset is out of the available bounds or there are not enough bytes to read
 // the ErrOutOfBounds error will be returned. Otherwise len(buf) will be returned with no errors.
@@ -107,6 +463,10 @@ func (m *Mapping) ReadAt(buf []byte, offset int64) (int, error) {
 	if err := m.access(offset, len(buf)); err != nil {
 		return 0, err
 	}
+	if err := m.populate(offset, uintptr(len(buf))); err != nil {
+		return 0, err
+	}
+	race.ReadRange(m.address+uintptr(offset), uintptr(len(buf)))
 	return copy(buf, m.memory[offset:]), nil
 }
 
@@ -124,10 +484,62 @@ func (m *Mapping) WriteAt(buf []byte, offset int64) (int, error) {
 	if err := m.access(offset, len(buf)); err != nil {
 		return 0, err
 	}
-	return copy(m.memory[offset:], buf), nil
+	if err := m.populate(offset, uintptr(len(buf))); err != nil {
+		return 0, err
+	}
+	race.WriteRange(m.address+uintptr(offset), uintptr(len(buf)))
+	n := copy(m.memory[offset:], buf)
+	m.markDirty(offset, uintptr(n))
+	return n, nil
+}
+
+// WriteTo writes the whole mapped memory into the given writer directly
+// without an intermediate buffer and returns the number of bytes written.
+// WriteTo implements the io.WriterTo interface.
+func (m *Mapping) WriteTo(w io.Writer) (int64, error) {
+	if m.memory == nil {
+		return 0, ErrClosed
+	}
+	if err := m.populate(0, uintptr(len(m.memory))); err != nil {
+		return 0, err
+	}
+	race.ReadRange(m.address, uintptr(len(m.memory)))
+	n, err := w.Write(m.memory)
+	return int64(n), err
+}
+
+// ReadFrom reads data from the given reader directly into the mapped memory
+// until io.EOF or the mapped memory is full and returns the number of bytes read.
+// The mapped memory can not grow, so the rest of data is left unread in the reader.
+// ReadFrom implements the io.ReaderFrom interface.
+func (m *Mapping) ReadFrom(r io.Reader) (int64, error) {
+	if m.memory == nil {
+		return 0, ErrClosed
+	}
+	if !m.writable {
+		return 0, ErrReadOnly
+	}
+	if err := m.populate(0, uintptr(len(m.memory))); err != nil {
+		return 0, err
+	}
+	race.WriteRange(m.address, uintptr(len(m.memory)))
+	n, err := readFull(r, m.memory)
+	m.markDirty(0, uintptr(n))
+	return n, err
+}
+
+// readFull reads data from the given reader into the given buffer until io.EOF or the buffer is full.
+func readFull(r io.Reader, buf []byte) (int64, error) {
+	n, err := io.ReadFull(r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return int64(n), err
 }
 
 // Begin starts and returns a new transaction.
+// The commit of the transaction writes the mapped memory directly, so it is not tracked by WithDirtyTracking,
+// use MarkDirty after it. The pages of the lazy mapping are supplied before the transaction starts.
 func (m *Mapping) Begin(offset int64, length uintptr) (*transaction.Tx, error) {
 	if m.memory == nil {
 		return nil, ErrClosed
@@ -135,5 +547,396 @@ func (m *Mapping) Begin(offset int64, length uintptr) (*transaction.Tx, error) {
 	if !m.writable {
 		return nil, ErrReadOnly
 	}
+	if length <= uintptr(MaxInt) && m.access(offset, int(length)) == nil {
+		if err := m.populate(offset, length); err != nil {
+			return nil, err
+		}
+	}
 	return transaction.Begin(m.memory, offset, length)
 }
+
+// Lock locks the mapped memory pages.
+// All pages that contain a part of the mapping address range
+// are guaranteed to be resident in RAM when the call returns successfully.
+// The pages are guaranteed to stay in RAM until later unlocked.
+// It may need to increase process memory limits for operation success.
+// See rlimit on Linux for details, or use WithRaiseLockLimit.
+// On Windows the working set of the process is raised automatically if it is too small.
+func (m *Mapping) Lock() error {
+	if m.memory == nil {
+		return ErrClosed
+	}
+	if m.locked {
+		return ErrLocked
+	}
+	if err := m.populate(0, uintptr(len(m.memory))); err != nil {
+		return err
+	}
+	if err := m.lock(m.alignedMemory); err != nil {
+		return err
+	}
+	m.locked = true
+	return nil
+}
+
+// Unlock unlocks the previously locked mapped memory pages.
+func (m *Mapping) Unlock() error {
+	if m.memory == nil {
+		return ErrClosed
+	}
+	if !m.locked {
+		return ErrNotLocked
+	}
+	if err := m.unlockMemory(m.alignedMemory); err != nil {
+		return err
+	}
+	m.locked = false
+	return nil
+}
+
+// LockRange locks the mapped memory pages which contain a part of the range
+// starting from the given offset and ends after the given length.
+// It allows to pin only the hot regions of the large mapping in RAM.
+// Locked ranges are not tracked by the mapping: Lock and Unlock
+// still treat the whole mapped memory as a single unit.
+// All locked pages are unlocked automatically when the mapping is closed.
+func (m *Mapping) LockRange(offset int64, length uintptr) error {
+	if m.memory == nil {
+		return ErrClosed
+	}
+	b, err := m.pages(offset, length)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	if err := m.populate(offset, length); err != nil {
+		return err
+	}
+	return m.lock(b)
+}
+
+// lock locks the given mapped memory pages reporting the failure to the metrics receiver
+// and the operation to the tracer.
+func (m *Mapping) lock(b []byte) error {
+	tracer := loadTracer()
+	var start time.Time
+	if tracer != nil {
+		start = time.Now()
+	}
+	err := m.lockMemory(b)
+	if tracer != nil {
+		tracer.Trace(Event{Op: OpLock, Mapping: m, Length: uintptr(len(b)), Start: start, Duration: time.Since(start), Err: err})
+	}
+	if err != nil {
+		if metrics := loadMetrics(); metrics != nil {
+			metrics.LockFailed(err)
+		}
+	}
+	return err
+}
+
+// UnlockRange unlocks the previously locked mapped memory pages which contain a part of the range
+// starting from the given offset and ends after the given length.
+func (m *Mapping) UnlockRange(offset int64, length uintptr) error {
+	if m.memory == nil {
+		return ErrClosed
+	}
+	b, err := m.pages(offset, length)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	return m.unlockMemory(b)
+}
+
+// Residency reports which mapped memory pages that contain a part of the range
+// starting from the given offset and ends after the given length are resident in RAM.
+// The first element of the result corresponds to the page which contains the given offset.
+// The result is just a snapshot, pages may be loaded or evicted at any moment.
+// The ErrNotSupported returns on the unix-like platforms other than Darwin and Linux.
+func (m *Mapping) Residency(offset int64, length uintptr) ([]bool, error) {
+	if m.memory == nil {
+		return nil, ErrClosed
+	}
+	b, err := m.pages(offset, length)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return []bool{}, nil
+	}
+	return m.residentMemory(b)
+}
+
+// pageCount returns the number of memory pages which contain the given mapped memory.
+func pageCount(b []byte) int {
+	pageSize := os.Getpagesize()
+	return (len(b) + pageSize - 1) / pageSize
+}
+
+// Sync synchronizes the mapped memory with the underlying file.
+func (m *Mapping) Sync() error {
+	if m.memory == nil {
+		return ErrClosed
+	}
+	if !m.writable {
+		return ErrReadOnly
+	}
+	if m.wp != nil {
+		if err := m.writeProtect(0, pageCount(m.alignedMemory)); err != nil {
+			return err
+		}
+	}
+	for i := range m.dirty {
+		atomic.StoreUint64(&m.dirty[i], 0)
+	}
+	if err := m.sync(m.alignedMemory); err != nil {
+		if m.dirty != nil {
+			m.markPages(0, pageCount(m.alignedMemory))
+		}
+		return err
+	}
+	return nil
+}
+
+// Seal synchronizes the mapped memory with the underlying file and protects it from writing,
+// so accidental writes through the mapped memory after the initialization phase fault loudly
+// instead of silently corrupting the file. WriteAt, Begin and other writing methods return ErrReadOnly after it.
+// The emulated mapped memory is protected by the methods only. The sealing can not be undone.
+func (m *Mapping) Seal() error {
+	if m.memory == nil {
+		return ErrClosed
+	}
+	if !m.writable {
+		return nil
+	}
+	if err := m.Sync(); err != nil {
+		return err
+	}
+	if err := m.protectMemory(m.alignedMemory, false); err != nil {
+		return err
+	}
+	m.writable = false
+	return nil
+}
+
+// sync synchronizes the given mapped memory pages with the underlying file
+// flushing the CPU caches directly if the mapped memory is the persistent memory.
+func (m *Mapping) sync(b []byte) error {
+	metrics, tracer := loadMetrics(), loadTracer()
+	if metrics == nil && tracer == nil {
+		return m.syncCache(b)
+	}
+	start := time.Now()
+	err := m.syncCache(b)
+	duration := time.Since(start)
+	if metrics != nil {
+		metrics.Synced(uintptr(len(b)), duration, err)
+	}
+	if tracer != nil {
+		tracer.Trace(Event{Op: OpSync, Mapping: m, Length: uintptr(len(b)), Start: start, Duration: duration, Err: err})
+	}
+	return err
+}
+
+// syncCache synchronizes the given mapped memory pages with the underlying file
+// or flushes the CPU caches if it is possible.
+func (m *Mapping) syncCache(b []byte) error {
+	if m.persistent && flushCache(b) {
+		return nil
+	}
+	if n := m.options.syncParallelism; n > 1 && len(b) >= 2*syncChunk {
+		return m.syncParallel(b, n)
+	}
+	return m.syncMemory(b)
+}
+
+// Close closes this mapping and frees all resources associated with it.
+// Mapped memory will be synchronized with the underlying file and unlocked automatically.
+// The mapped file is closed too if the mapping is opened by OpenFD with WithCloseFile.
+// All steps are executed even if some of them fail, all errors will be returned joined.
+// Close implements the io.Closer interface.
+func (m *Mapping) Close() error {
+	tracer := loadTracer()
+	if tracer == nil || m.memory == nil {
+		return m.close()
+	}
+	length, start := m.Length(), time.Now()
+	err := m.close()
+	tracer.Trace(Event{Op: OpClose, Mapping: m, Length: length, Start: start, Duration: time.Since(start), Err: err})
+	return err
+}
+
+// close closes this mapping and frees all resources associated with it, see Close.
+func (m *Mapping) close() error {
+	if m.memory == nil {
+		return ErrClosed
+	}
+	var errs []error
+	m.stopAutoSync()
+	m.stopWatch()
+
+	// Maybe unnecessary.
+	if m.writable {
+		if err := m.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if m.locked {
+		if err := m.Unlock(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if m.wp != nil {
+		m.wp.stop()
+	}
+	if m.lazy != nil {
+		m.lazy.stop()
+	}
+	race.Unregister(m.address)
+	unregister(m.address)
+	if metrics := loadMetrics(); metrics != nil {
+		metrics.Closed(m.Length())
+	}
+	if err := m.unmapMemory(); err != nil {
+		errs = append(errs, err)
+	}
+	if m.file != nil {
+		if err := m.file.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	*m = Mapping{}
+	runtime.SetFinalizer(m, nil)
+	return joinErrors(errs)
+}
+
+// Advise gives the hint about the expected access pattern of the mapped memory
+// starting from the given offset and ends after the given length.
+// The hint applies to all pages that contain a part of the given range.
+// It is a best effort, the operating system may ignore the hint.
+// On Windows only AdviceWillNeed and AdviceDontNeed hints have an effect.
+func (m *Mapping) Advise(advice Advice, offset int64, length uintptr) error {
+	if m.memory == nil {
+		return ErrClosed
+	}
+	if advice < AdviceNormal || advice > AdviceDontNeed {
+		return ErrBadAdvice
+	}
+	b, err := m.pages(offset, length)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	if err := m.adviseMemory(b, advice); err != nil {
+		return err
+	}
+	if advice == AdviceDontNeed {
+		m.forget(b)
+	}
+	return nil
+}
+
+// Evict discards the mapped memory pages which contain a part of the range
+// starting from the given offset and ends after the given length, returning them to the operating system
+// without closing the mapping. Modified pages of the writable mapping are synchronized with the underlying file first,
+// the next access to the evicted pages reloads them from the file.
+// On Linux modified pages of the anonymous or copy-on-write mapping are lost, they are filled with zeros
+// or reloaded from the file respectively. On the other platforms the eviction is a hint which keeps the data:
+// Windows removes the pages from the working set, so the modified private pages are paged out and read back,
+// Darwin, the BSDs and Solaris may reclaim the pages lazily preserving their contents
+// and the emulated mapping of plan9, js/wasm and wasip1 is not evicted at all.
+// The ErrLocked returns if the whole mapped memory is locked.
+func (m *Mapping) Evict(offset int64, length uintptr) error {
+	if m.memory == nil {
+		return ErrClosed
+	}
+	if m.locked {
+		return ErrLocked
+	}
+	b, err := m.pages(offset, length)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	if m.writable {
+		if err := m.sync(b); err != nil {
+			return err
+		}
+	}
+	if err := m.adviseMemory(b, AdviceDontNeed); err != nil {
+		return err
+	}
+	m.forget(b)
+	return nil
+}
+
+// PunchHole deallocates the disk space of the mapped file region which starts from the given offset
+// from start of the mapped memory and ends after the given length, so the dead regions of the file,
+// e.g. the compacted log or the expired records, return their space without rewriting the file.
+// The region reads back as zeros afterwards and the file size is not changed.
+// The file system may deallocate only the whole blocks and zero the partial ones.
+// The mapping must be opened in ModeReadWrite, the ErrReadOnly returns otherwise.
+// The ErrNotSupported returns for the anonymous mapping or if the file system does not support it.
+func (m *Mapping) PunchHole(offset int64, length uintptr) error {
+	if m.memory == nil {
+		return ErrClosed
+	}
+	if m.mode != ModeReadWrite {
+		return ErrReadOnly
+	}
+	if length > uintptr(MaxInt) {
+		return outOfBounds()
+	}
+	if err := m.access(offset, int(length)); err != nil {
+		return err
+	}
+	if length == 0 {
+		return nil
+	}
+	race.WriteRange(m.address+uintptr(offset), length)
+	return m.punchHole(m.fileOffset+offset, int64(length))
+}
+
+// Prefetch warms the mapped memory pages which contain a part of the range
+// starting from the given offset and ends after the given length ahead of the random access.
+// The pages are requested asynchronously with AdviceWillNeed which starts the read-ahead
+// on Linux and Darwin and uses PrefetchVirtualMemory on Windows.
+// If wait is true, Prefetch also touches every page and returns when all of them are resident in RAM.
+func (m *Mapping) Prefetch(offset int64, length uintptr, wait bool) error {
+	if m.memory == nil {
+		return ErrClosed
+	}
+	b, err := m.pages(offset, length)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	if err := m.adviseMemory(b, AdviceWillNeed); err != nil {
+		return err
+	}
+	if wait {
+		touchPages(b)
+	}
+	return nil
+}
+
+// touchPages reads a single byte of every given mapped memory page to fault it in.
+func touchPages(b []byte) {
+	var sum byte
+	for i, pageSize := 0, os.Getpagesize(); i < len(b); i += pageSize {
+		sum += b[i]
+	}
+	runtime.KeepAlive(sum)
+}
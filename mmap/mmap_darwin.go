@@ -0,0 +1,125 @@
+package mmap
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// mapFixedNoReplace is zero since there is no such mmap flag,
+// the address hint is checked after the mapping.
+const mapFixedNoReplace = 0
+
+// mapPersistent is zero since the persistent memory is not supported.
+const mapPersistent = 0
+
+// mapHugeMemory does nothing and returns false since huge pages are not supported.
+func mapHugeMemory(hint unsafe.Pointer, length uintptr, prot, flags int) ([]byte, bool) {
+	return nil, false
+}
+
+// adviseHugePages does nothing since huge pages are not supported.
+func adviseHugePages(b []byte) {}
+
+// vmInheritNone is the VM_INHERIT_NONE inheritance attribute which is not provided by the unix package.
+const vmInheritNone = 2
+
+// adviseFork sets the given mapped memory to be not inherited in the child processes if FlagDontFork is set.
+// FlagWipeOnFork is not supported.
+func adviseFork(b []byte, flags Flag) error {
+	if flags&FlagWipeOnFork != 0 {
+		return ErrNotSupported
+	}
+	if flags&FlagDontFork != 0 {
+		// There is no wrapper of minherit in the unix package.
+		_, _, err := unix.Syscall(unix.SYS_MINHERIT, uintptr(unsafe.Pointer(&b[0])), uintptr(len(b)), vmInheritNone)
+		if err != 0 {
+			return os.NewSyscallError("minherit", err)
+		}
+	}
+	return nil
+}
+
+// shmOpen wraps the system call for shm_open which has no wrapper in the unix package.
+func shmOpen(name string, flag int, perm uint32) (int, error) {
+	path, err := unix.BytePtrFromString("/" + name)
+	if err != nil {
+		return -1, err
+	}
+	fd, _, errno := unix.Syscall(
+		unix.SYS_SHM_OPEN, uintptr(unsafe.Pointer(path)), uintptr(flag|unix.O_CLOEXEC), uintptr(perm),
+	)
+	if errno != 0 {
+		return -1, errno
+	}
+	return int(fd), nil
+}
+
+// shmUnlink wraps the system call for shm_unlink which has no wrapper in the unix package.
+func shmUnlink(name string) error {
+	path, err := unix.BytePtrFromString("/" + name)
+	if err != nil {
+		return err
+	}
+	_, _, errno := unix.Syscall(unix.SYS_SHM_UNLINK, uintptr(unsafe.Pointer(path)), 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// Block device requests which are not provided by the unix package.
+const (
+	dkiocGetBlockSize  = 0x40046418
+	dkiocGetBlockCount = 0x40086419
+)
+
+// deviceSize returns the size of the given block device.
+// The block size is the 32-bit value which is read into the low half of the integer on the little-endian Darwin.
+func deviceSize(fd int) (int64, error) {
+	blockSize, err := unix.IoctlGetInt(fd, dkiocGetBlockSize)
+	if err != nil {
+		return 0, err
+	}
+	blockCount, err := unix.IoctlGetInt(fd, dkiocGetBlockCount)
+	if err != nil {
+		return 0, err
+	}
+	return int64(uint64(blockCount) * uint64(uint32(blockSize))), nil
+}
+
+// mincore wraps the system call for mincore which has no wrapper in the unix package.
+func mincore(b []byte, vec []byte) error {
+	_, _, err := unix.Syscall(
+		unix.SYS_MINCORE,
+		uintptr(unsafe.Pointer(&b[0])), uintptr(len(b)), uintptr(unsafe.Pointer(&vec[0])),
+	)
+	if err != 0 {
+		return err
+	}
+	return nil
+}
+
+// punchHole deallocates the given region of the file with the given descriptor keeping its size.
+// The file system requires the region to be aligned by its block size.
+// The fpunchhole_t argument of F_PUNCHHOLE has the same layout as the beginning of fstore_t
+// with the zero position mode as the reserved field, so it is passed by FcntlFstore.
+func punchHole(fd int, offset, length int64) error {
+	err := unix.FcntlFstore(uintptr(fd), unix.F_PUNCHHOLE, &unix.Fstore_t{Offset: offset, Length: length})
+	if err == unix.ENOTSUP {
+		return ErrNotSupported
+	}
+	return os.NewSyscallError("fcntl", err)
+}
+
+// adviseFile tunes the read-ahead of the file with the given descriptor for the given access pattern.
+// The read-ahead is enabled or disabled for the whole file, the region is ignored.
+func adviseFile(fd int, offset, length int64, advice Advice) error {
+	enable := 1
+	if advice == AdviceRandom {
+		enable = 0
+	}
+	_, err := unix.FcntlInt(uintptr(fd), unix.F_RDAHEAD, enable)
+	return os.NewSyscallError("fcntl", err)
+}
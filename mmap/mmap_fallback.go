@@ -0,0 +1,179 @@
+//go:build plan9 || js || wasip1
+
+package mmap
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// Mapping is an emulated mapping of the file into the memory.
+// The emulation is used on platforms which do not provide the memory mapping, such as plan9, js/wasm and wasip1.
+// The mapped memory is a heap allocated buffer which is read from the file at the opening
+// and written back to the file at the synchronization. It is neither protected from writing
+// nor shared with other processes. On platforms which can not duplicate the file descriptor,
+// such as js/wasm and wasip1, the given file descriptor must stay open until the mapping is closed.
+type Mapping struct {
+	generic
+	// fd specifies the descriptor of the mapped file.
+	fd int
+	// dup specifies whether fd is the duplicated descriptor owned by this mapping.
+	dup bool
+	// offset specifies the aligned offset of the mapped memory from start of the file.
+	offset int64
+	// private specifies whether updates to the mapped memory are not carried through to the file.
+	private bool
+}
+
+// fileSize returns the size of the given file.
+// The current offset of the file is restored.
+func fileSize(fd uintptr) (int64, error) {
+	current, err := syscall.Seek(int(fd), 0, io.SeekCurrent)
+	if err != nil {
+		return 0, os.NewSyscallError("seek", err)
+	}
+	size, err := syscall.Seek(int(fd), 0, io.SeekEnd)
+	if err != nil {
+		return 0, os.NewSyscallError("seek", err)
+	}
+	if _, err := syscall.Seek(int(fd), current, io.SeekStart); err != nil {
+		return 0, os.NewSyscallError("seek", err)
+	}
+	return size, nil
+}
+
+// allocationGranularity returns the memory page size which the file offset of the emulated mapping is aligned by.
+func allocationGranularity() int {
+	return os.Getpagesize()
+}
+
+// mapMemory reads the given file into the heap allocated buffer and returns it.
+// The given offset and length must be aligned by the memory page size.
+// The address hint and the fork flags are ignored, FlagFixed and FlagPersistentMemory are not supported.
+func (m *Mapping) mapMemory(fd uintptr, offset int64, length uintptr, mode Mode, flags Flag, o *options) ([]byte, error) {
+	if flags&(FlagFixed|FlagPersistentMemory) != 0 {
+		return nil, ErrNotSupported
+	}
+	m.fd = int(fd)
+	m.offset = offset
+	m.private = fd == anonymousFd || mode != ModeReadWrite
+	b := make([]byte, length)
+	if fd == anonymousFd {
+		return b, nil
+	}
+	// The descriptor is duplicated for every mode since the given one may be closed
+	// as soon as the mapping is opened.
+	nfd, err := dup(m.fd)
+	if err != nil {
+		return nil, os.NewSyscallError("dup", err)
+	}
+	m.fd, m.dup = nfd, nfd != m.fd
+	for n := 0; n < len(b); {
+		k, err := syscall.Pread(m.fd, b[n:], offset+int64(n))
+		if err != nil {
+			_ = m.unmapMemory()
+			return nil, os.NewSyscallError("pread", err)
+		}
+		if k == 0 {
+			// The rest of the buffer beyond the end of file stays zero.
+			break
+		}
+		n += k
+	}
+	return b, nil
+}
+
+// lockMemory does nothing since the emulated mapped memory can not be locked.
+func (m *Mapping) lockMemory(b []byte) error {
+	return nil
+}
+
+// unlockMemory does nothing since the emulated mapped memory can not be locked.
+func (m *Mapping) unlockMemory(b []byte) error {
+	return nil
+}
+
+// syncMemory writes the given part of the emulated mapped memory to the underlying file.
+func (m *Mapping) syncMemory(b []byte) error {
+	if m.private {
+		return nil
+	}
+	offset := m.offset + int64(addressOf(b)-addressOf(m.alignedMemory))
+	for n := 0; n < len(b); {
+		k, err := syscall.Pwrite(m.fd, b[n:], offset+int64(n))
+		if err != nil {
+			return os.NewSyscallError("pwrite", err)
+		}
+		if k == 0 {
+			return os.NewSyscallError("pwrite", io.ErrShortWrite)
+		}
+		n += k
+	}
+	return nil
+}
+
+// flushMemory synchronizes the given mapped memory pages like syncMemory.
+func (m *Mapping) flushMemory(b []byte) error {
+	return m.syncMemory(b)
+}
+
+// flushFile does nothing since syncMemory waits for the file to be synchronized.
+func (m *Mapping) flushFile() error {
+	return nil
+}
+
+// protectMemory does nothing since the emulated mapped memory can not be protected.
+func (m *Mapping) protectMemory(b []byte, writable bool) error {
+	return nil
+}
+
+// adviseMemory does nothing since the emulated mapped memory is always resident.
+func (m *Mapping) adviseMemory(b []byte, advice Advice) error {
+	return nil
+}
+
+// residentMemory reports all given pages as resident since the emulated mapped memory is always resident.
+func (m *Mapping) residentMemory(b []byte) ([]bool, error) {
+	resident := make([]bool, pageCount(b))
+	for i := range resident {
+		resident[i] = true
+	}
+	return resident, nil
+}
+
+// unmapMemory closes the duplicated file descriptor if any and forgets the descriptor.
+// The emulated mapped memory is released by the garbage collector.
+func (m *Mapping) unmapMemory() error {
+	fd := m.fd
+	m.fd = -1
+	if !m.dup {
+		return nil
+	}
+	m.dup = false
+	return os.NewSyscallError("close", syscall.Close(fd))
+}
+
+// descriptor returns the descriptor of the mapped file or anonymousFd for the anonymous mapping.
+func (m *Mapping) descriptor() uintptr {
+	return uintptr(m.fd)
+}
+
+// cloneDescriptor returns ErrNotSupported since the emulated mapped memory can not be shared.
+func (m *Mapping) cloneDescriptor(o *options) (uintptr, error) {
+	return 0, ErrNotSupported
+}
+
+// punchHole returns ErrNotSupported since the emulated mapped memory is not backed by the file pages.
+func (m *Mapping) punchHole(offset, length int64) error {
+	return ErrNotSupported
+}
+
+// truncateFile changes the size of the mapped file.
+// The ErrNotSupported returns if this mapping does not hold the descriptor of the file.
+func (m *Mapping) truncateFile(size int64) error {
+	if m.descriptor() == anonymousFd {
+		return ErrNotSupported
+	}
+	return os.NewSyscallError("ftruncate", ftruncate(m.fd, size))
+}
@@ -0,0 +1,131 @@
+package mmap
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strconv"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// mapFixedNoReplace is the mmap flag which places the mapping exactly at the address hint
+// without replacing existing mappings. Old kernels ignore it and treat the address as a hint.
+const mapFixedNoReplace = unix.MAP_FIXED_NOREPLACE
+
+// defaultHugePageSize is the huge page size which is used if the actual one can not be determined.
+const defaultHugePageSize = 2 << 20
+
+// hugePageSize returns the default huge page size.
+func hugePageSize() uintptr {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return defaultHugePageSize
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := bytes.Fields(scanner.Bytes())
+		if len(fields) == 3 && string(fields[0]) == "Hugepagesize:" && string(fields[2]) == "kB" {
+			if size, err := strconv.ParseUint(string(fields[1]), 10, 0); err == nil && size > 0 {
+				return uintptr(size) << 10
+			}
+		}
+	}
+	return defaultHugePageSize
+}
+
+// mapHugeMemory maps the anonymous memory backed by the reserved huge pages
+// and returns the byte slice which wraps the mapped memory rounded up to the huge page size.
+// It returns false if there are no huge pages available.
+func mapHugeMemory(hint unsafe.Pointer, length uintptr, prot, flags int) ([]byte, bool) {
+	pageSize := hugePageSize()
+	if rem := length % pageSize; rem != 0 {
+		if length > uintptr(MaxInt)-(pageSize-rem) {
+			return nil, false
+		}
+		length += pageSize - rem
+	}
+	ptr, err := unix.MmapPtr(-1, 0, hint, length, prot, flags|unix.MAP_HUGETLB)
+	if err != nil {
+		return nil, false
+	}
+	return unsafe.Slice((*byte)(ptr), length), true
+}
+
+// adviseHugePages advises the given mapped memory to be backed by transparent huge pages.
+// It is a best effort, errors are ignored.
+func adviseHugePages(b []byte) {
+	_ = unix.Madvise(b, unix.MADV_HUGEPAGE)
+}
+
+// adviseFork advises the given mapped memory to be not inherited or to be wiped in the child processes
+// according to the given flags.
+func adviseFork(b []byte, flags Flag) error {
+	if flags&FlagDontFork != 0 {
+		if err := unix.Madvise(b, unix.MADV_DONTFORK); err != nil {
+			return os.NewSyscallError("madvise", err)
+		}
+	}
+	if flags&FlagWipeOnFork != 0 {
+		if err := unix.Madvise(b, unix.MADV_WIPEONFORK); err != nil {
+			return os.NewSyscallError("madvise", err)
+		}
+	}
+	return nil
+}
+
+// shmOpen opens the shared memory object with the given name which is placed in /dev/shm.
+func shmOpen(name string, flag int, perm uint32) (int, error) {
+	return unix.Open("/dev/shm/"+name, flag|unix.O_CLOEXEC|unix.O_NOFOLLOW, perm)
+}
+
+// shmUnlink removes the shared memory object with the given name which is placed in /dev/shm.
+func shmUnlink(name string) error {
+	return unix.Unlink("/dev/shm/" + name)
+}
+
+// deviceSize returns the size of the given block device.
+func deviceSize(fd int) (int64, error) {
+	var size uint64
+	_, _, err := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), unix.BLKGETSIZE64, uintptr(unsafe.Pointer(&size)))
+	if err != 0 {
+		return 0, err
+	}
+	return int64(size), nil
+}
+
+// mincore wraps the system call for mincore.
+func mincore(b []byte, vec []byte) error {
+	_, _, err := unix.Syscall(
+		unix.SYS_MINCORE,
+		uintptr(unsafe.Pointer(&b[0])), uintptr(len(b)), uintptr(unsafe.Pointer(&vec[0])),
+	)
+	if err != 0 {
+		return err
+	}
+	return nil
+}
+
+// punchHole deallocates the given region of the file with the given descriptor keeping its size.
+func punchHole(fd int, offset, length int64) error {
+	err := unix.Fallocate(fd, unix.FALLOC_FL_PUNCH_HOLE|unix.FALLOC_FL_KEEP_SIZE, offset, length)
+	if err == unix.EOPNOTSUPP {
+		return ErrNotSupported
+	}
+	return os.NewSyscallError("fallocate", err)
+}
+
+// adviseFile tunes the read-ahead of the given region of the file with the given descriptor
+// for the given access pattern.
+func adviseFile(fd int, offset, length int64, advice Advice) error {
+	behavior := unix.FADV_NORMAL
+	switch advice {
+	case AdviceSequential:
+		behavior = unix.FADV_SEQUENTIAL
+	case AdviceRandom:
+		behavior = unix.FADV_RANDOM
+	}
+	return os.NewSyscallError("fadvise", unix.Fadvise(fd, offset, length, behavior))
+}
@@ -0,0 +1,66 @@
+//go:build unix && !darwin && !linux
+
+package mmap
+
+import (
+	"io"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// mapFixedNoReplace is zero since there is no portable mmap flag which does not replace existing mappings,
+// the address hint is checked after the mapping.
+const mapFixedNoReplace = 0
+
+// mapPersistent is zero since the persistent memory is not supported.
+const mapPersistent = 0
+
+// mapHugeMemory does nothing and returns false since huge pages are not supported.
+func mapHugeMemory(hint unsafe.Pointer, length uintptr, prot, flags int) ([]byte, bool) {
+	return nil, false
+}
+
+// adviseHugePages does nothing since huge pages are not supported.
+func adviseHugePages(b []byte) {}
+
+// adviseFork returns ErrNotSupported if FlagDontFork or FlagWipeOnFork is set
+// since the inheritance of the mapped memory can not be changed portably.
+func adviseFork(b []byte, flags Flag) error {
+	if flags&(FlagDontFork|FlagWipeOnFork) != 0 {
+		return ErrNotSupported
+	}
+	return nil
+}
+
+// deviceSize returns the size of the given block device.
+// The current offset of the device is restored.
+func deviceSize(fd int) (int64, error) {
+	current, err := unix.Seek(fd, 0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	size, err := unix.Seek(fd, 0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := unix.Seek(fd, current, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// mincore returns ErrNotSupported since the residency of the pages can not be reported portably.
+func mincore(b []byte, vec []byte) error {
+	return ErrNotSupported
+}
+
+// punchHole returns ErrNotSupported since the holes can not be punched portably.
+func punchHole(fd int, offset, length int64) error {
+	return ErrNotSupported
+}
+
+// adviseFile does nothing since the read-ahead of the file can not be tuned portably.
+func adviseFile(fd int, offset, length int64, advice Advice) error {
+	return nil
+}
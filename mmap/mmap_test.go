@@ -2,13 +2,20 @@ package mmap
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"io/ioutil"
 	"math"
+	"net"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+	"unsafe"
 )
 
 // testFilePath is the template of the path to the test file.
@@ -309,8 +316,1911 @@ func TestSegment(t *testing.T) {
 	if _, err := f.ReadAt(buf, 0); err != nil {
 		t.Fatal(err)
 	}
-	uint32Data := []byte{254, 255, 255, 255}
+	uint32Data := make([]byte, 4)
+	*(*uint32)(unsafe.Pointer(&uint32Data[0])) = math.MaxUint32 - 1
 	if bytes.Compare(buf, uint32Data) != 0 {
 		t.Fatalf("data must be %v, %v found", uint32Data, buf)
 	}
 }
+
+// TestAnonymous tests the mapping which is not backed by any file.
+// CASE 1: The mapped memory MUST be initialized with zeros.
+// CASE 2: The read data MUST be exactly the same as the previously written.
+func TestAnonymous(t *testing.T) {
+	m, err := OpenAnonymous(uintptr(testDataLength), ModeReadWrite, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	buf := make([]byte, testDataLength)
+	if _, err := m.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testZeroData) != 0 {
+		t.Fatalf("data must be %v, %v found", testZeroData, buf)
+	}
+	if _, err := m.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestPageOffset tests the mapping which starts beyond the first memory page.
+// CASE: The data which is read directly from the underlying file at the given offset MUST be exactly the same
+// as the previously written through the mapped memory.
+func TestPageOffset(t *testing.T) {
+	f := openNextTestFile(t, false)
+	defer closeTestEntity(t, f)
+	offset := int64(os.Getpagesize()) + 1
+	if err := f.Truncate(offset + int64(testDataLength)); err != nil {
+		t.Fatal(err)
+	}
+	m, err := Open(f.Fd(), offset, uintptr(testDataLength), ModeReadWrite, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	if _, err := m.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, testDataLength)
+	if _, err := f.ReadAt(buf, offset); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+}
+
+// TestAdvise tests the hint about the expected access pattern.
+// CASE 1: The valid hint MUST be accepted.
+// CASE 2: The ErrBadAdvice MUST be returned for the unknown hint.
+// CASE 3: The ErrOutOfBounds MUST be returned for the range beyond the mapped memory.
+func TestAdvise(t *testing.T) {
+	m := openTestMapping(t, ModeReadWrite)
+	defer closeTestEntity(t, m)
+	if err := m.Advise(AdviceSequential, 1, uintptr(testDataLength-1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Advise(AdviceDontNeed+1, 0, uintptr(testDataLength)); err != ErrBadAdvice {
+		t.Fatalf("expected ErrBadAdvice, [%v] error found", err)
+	}
+	if err := m.Advise(AdviceWillNeed, 1, uintptr(testDataLength)); err != ErrOutOfBounds {
+		t.Fatalf("expected ErrOutOfBounds, [%v] error found", err)
+	}
+}
+
+// TestHugePages tests the anonymous mapping backed by huge pages.
+// CASE: The mapping MUST work correctly whether huge pages are available or not.
+func TestHugePages(t *testing.T) {
+	m, err := OpenAnonymous(uintptr(testDataLength), ModeReadWrite, FlagHugePages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	if m.Length() != uintptr(testDataLength) {
+		t.Fatalf("length must be %d, %d found", testDataLength, m.Length())
+	}
+	if _, err := m.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, testDataLength)
+	if _, err := m.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestLockRange tests the locking of the mapped memory range.
+// CASE 1: The range within the mapped memory MUST be locked and unlocked successfully.
+// CASE 2: The ErrOutOfBounds MUST be returned for the range beyond the mapped memory.
+func TestLockRange(t *testing.T) {
+	m := openTestMapping(t, ModeReadWrite)
+	defer closeTestEntity(t, m)
+	if err := m.LockRange(1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.UnlockRange(1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.LockRange(int64(testDataLength), 1); err != ErrOutOfBounds {
+		t.Fatalf("expected ErrOutOfBounds, [%v] error found", err)
+	}
+}
+
+// TestResidency tests the page residency reporting.
+// CASE: The page which was just written MUST be reported as resident.
+func TestResidency(t *testing.T) {
+	m := openTestMapping(t, ModeReadWrite)
+	defer closeTestEntity(t, m)
+	if _, err := m.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	resident, err := m.Residency(0, uintptr(testDataLength))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resident) != 1 || !resident[0] {
+		t.Fatalf("single resident page must be reported, %v found", resident)
+	}
+}
+
+// TestFixedAddress tests the mapping placed at the given address.
+// CASE 1: The ErrBadAddress MUST be returned for the address which is not aligned by the memory page size.
+// CASE 2: The mapped memory MUST be placed exactly at the given free address.
+// CASE 3: The ErrAddressUnavailable MUST be returned for the address which is already in use.
+func TestFixedAddress(t *testing.T) {
+	m, err := OpenAnonymous(uintptr(testDataLength), ModeReadWrite, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	address := m.Address()
+	if address%uintptr(os.Getpagesize()) != 0 {
+		t.Skip("emulated mapped memory is not aligned by the memory page size")
+	}
+	if _, err := OpenAnonymous(uintptr(testDataLength), ModeReadWrite, FlagFixed, WithAddress(address+1)); err != ErrBadAddress {
+		t.Fatalf("expected ErrBadAddress, [%v] error found", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	m, err = OpenAnonymous(uintptr(testDataLength), ModeReadWrite, FlagFixed, WithAddress(address))
+	if err == ErrNotSupported {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	if m.Address() != address {
+		t.Fatalf("address must be %#x, %#x found", address, m.Address())
+	}
+	if _, err := OpenAnonymous(uintptr(testDataLength), ModeReadWrite, FlagFixed, WithAddress(address)); err != ErrAddressUnavailable {
+		t.Fatalf("expected ErrAddressUnavailable, [%v] error found", err)
+	}
+}
+
+// TestForkFlags tests the mapping which is not inherited by the child processes as is.
+// CASE: The private anonymous mapping MUST work correctly with the fork flags.
+func TestForkFlags(t *testing.T) {
+	m, err := OpenAnonymous(uintptr(testDataLength), ModeWriteCopy, FlagDontFork|FlagWipeOnFork)
+	if err == ErrNotSupported {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	if _, err := m.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, testDataLength)
+	if _, err := m.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestEvict tests the discarding of the mapped memory pages.
+// CASE 1: The data MUST be exactly the same as the previously written after the eviction.
+// CASE 2: The ErrLocked MUST be returned for the locked mapping.
+func TestEvict(t *testing.T) {
+	m := openTestMapping(t, ModeReadWrite)
+	defer closeTestEntity(t, m)
+	if _, err := m.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Evict(0, uintptr(testDataLength)); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, testDataLength)
+	if _, err := m.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+	if err := m.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Evict(0, uintptr(testDataLength)); err != ErrLocked {
+		t.Fatalf("expected ErrLocked, [%v] error found", err)
+	}
+}
+
+// TestPrefetch tests the warming of the mapped memory pages.
+// CASE 1: The page MUST be reported as resident after the waiting prefetch.
+// CASE 2: The ErrOutOfBounds MUST be returned for the range beyond the mapped memory.
+func TestPrefetch(t *testing.T) {
+	m := openTestMapping(t, ModeReadOnly)
+	defer closeTestEntity(t, m)
+	if err := m.Prefetch(0, uintptr(testDataLength), false); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Prefetch(0, uintptr(testDataLength), true); err != nil {
+		t.Fatal(err)
+	}
+	resident, err := m.Residency(0, uintptr(testDataLength))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resident) != 1 || !resident[0] {
+		t.Fatalf("single resident page must be reported, %v found", resident)
+	}
+	if err := m.Prefetch(1, uintptr(testDataLength), false); err != ErrOutOfBounds {
+		t.Fatalf("expected ErrOutOfBounds, [%v] error found", err)
+	}
+}
+
+// TestPersistentMemory tests the mapping of the persistent memory.
+// CASE 1: The CPU caches MUST be flushed without errors if it is supported.
+// CASE 2: The ErrNotSupported MUST be returned for the file which is not placed on the persistent memory.
+func TestPersistentMemory(t *testing.T) {
+	buf := make([]byte, 3*testDataLength)
+	copy(buf[1:], testData)
+	flushCache(buf[1 : 1+testDataLength])
+	if bytes.Compare(buf[1:1+testDataLength], testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf[1:1+testDataLength])
+	}
+	f := openNextTestFile(t, false)
+	defer closeTestEntity(t, f)
+	m, err := Open(f.Fd(), 0, uintptr(testDataLength), ModeReadWrite, FlagPersistentMemory)
+	if err == ErrNotSupported {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	if _, err := m.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Sync(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestRaiseLockLimit tests the locking which may raise the locked memory limit of the process.
+// CASE: The mapped memory MUST be locked and unlocked successfully.
+func TestRaiseLockLimit(t *testing.T) {
+	f := openNextTestFile(t, false)
+	defer closeTestEntity(t, f)
+	m, err := Open(f.Fd(), 0, uintptr(testDataLength), ModeReadWrite, 0, WithRaiseLockLimit())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	if err := m.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestShared tests the mapping of the named shared memory.
+// CASE 1: The data which is read through the second mapping of the shared memory MUST be exactly the same
+// as the previously written through the first one.
+// CASE 2: The ErrBadName MUST be returned for the invalid name.
+func TestShared(t *testing.T) {
+	name := "github.com+alexeymaximov+go-bio+mmap_" + strconv.Itoa(os.Getpid())
+	m, err := OpenShared(name, uintptr(testDataLength), ModeReadWrite)
+	if err == ErrNotSupported {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	defer func() {
+		if err := RemoveShared(name); err != nil {
+			t.Error(err)
+		}
+	}()
+	if _, err := m.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	shared, err := OpenShared(name, uintptr(testDataLength), ModeReadOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, shared)
+	buf := make([]byte, testDataLength)
+	if _, err := shared.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+	if _, err := OpenShared("", uintptr(testDataLength), ModeReadWrite); err != ErrBadName {
+		t.Fatalf("expected ErrBadName, [%v] error found", err)
+	}
+}
+
+// TestWholeFile tests the mapping of the file from the given offset to its end.
+// CASE 1: The length of the mapping MUST be equal to the rest of the file.
+// CASE 2: The ErrBadLength MUST be returned for the offset at the end of the file.
+func TestWholeFile(t *testing.T) {
+	f := openNextTestFile(t, false)
+	defer closeTestEntity(t, f)
+	m, err := Open(f.Fd(), 1, 0, ModeReadOnly, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	if m.Length() != uintptr(testDataLength-1) {
+		t.Fatalf("length must be %d, %d found", testDataLength-1, m.Length())
+	}
+	if _, err := Open(f.Fd(), int64(testDataLength), 0, ModeReadOnly, 0); err != ErrBadLength {
+		t.Fatalf("expected ErrBadLength, [%v] error found", err)
+	}
+}
+
+// TestFileOpeningMode tests the OpenFileMode function.
+// CASE 1: The ErrBadLength MUST be returned for the read-only mapping beyond the end of the file.
+// CASE 2: The whole file MUST be mapped in the read-only mode for the zero size.
+// CASE 3: Updates to the copy-on-write mapping MUST NOT be carried through to the file.
+func TestFileOpeningMode(t *testing.T) {
+	f := openNextTestFile(t, false)
+	filePath := f.Name()
+	closeTestEntity(t, f)
+	if _, err := OpenFileMode(filePath, testFileMode, uintptr(testDataLength+1), ModeReadOnly, 0, nil); err != ErrBadLength {
+		t.Fatalf("expected ErrBadLength, [%v] error found", err)
+	}
+	m, err := OpenFileMode(filePath, testFileMode, 0, ModeReadOnly, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	if m.Length() != uintptr(testDataLength) {
+		t.Fatalf("length must be %d, %d found", testDataLength, m.Length())
+	}
+	private, err := OpenFileMode(filePath, testFileMode, uintptr(testDataLength), ModeWriteCopy, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, private)
+	if _, err := private.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := private.Close(); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testZeroData) != 0 {
+		t.Fatalf("data must be %v, %v found", testZeroData, buf)
+	}
+}
+
+// TestFileRegionOpening tests the OpenFileRegion function.
+// CASE 1: The file MUST be extended to the end of the region.
+// CASE 2: The data which is read directly from the file at the given offset MUST be exactly the same
+// as the previously written through the mapped memory.
+// CASE 3: The region at the offset which is aligned by the memory page size but not by Granularity
+// MUST be mapped.
+func TestFileRegionOpening(t *testing.T) {
+	filePath := nextTestFilePath(t)
+	offset := int64(os.Getpagesize()) + 1
+	m, err := OpenFileRegion(filePath, testFileMode, offset, uintptr(testDataLength), ModeReadWrite, 0, func(m *Mapping) error {
+		_, err := m.WriteAt(testData, 0)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(buf)) != offset+int64(testDataLength) {
+		t.Fatalf("file size must be %d, %d found", offset+int64(testDataLength), len(buf))
+	}
+	if bytes.Compare(buf[offset:], testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf[offset:])
+	}
+	offset = 3 * int64(os.Getpagesize())
+	m, err = OpenFileRegion(filePath, testFileMode, offset, uintptr(testDataLength), ModeReadWrite, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.AlignedOffset()%int64(Granularity()) != 0 {
+		t.Fatalf("aligned offset %d must be aligned by %d", m.AlignedOffset(), Granularity())
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestOpenFD tests the mapping of the file which is closed together with the mapping.
+// CASE 1: The data which is read directly from the file MUST be exactly the same
+// as the previously written through the mapped memory.
+// CASE 2: The file MUST be closed together with the mapping.
+func TestOpenFD(t *testing.T) {
+	f := openNextTestFile(t, false)
+	m, err := OpenFD(f, 0, uintptr(testDataLength), ModeReadWrite, 0, WithCloseFile())
+	if err != nil {
+		_ = f.Close()
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	if _, err := m.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, testDataLength)
+	if _, err := f.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err == nil {
+		t.Fatal("file must be closed")
+	}
+}
+
+// TestTemp tests the mapping of the temporary file.
+// CASE 1: The mapped memory MUST be initialized with zeros.
+// CASE 2: The temporary file MUST NOT be left in the directory after the mapping is closed.
+func TestTemp(t *testing.T) {
+	dir := t.TempDir()
+	m, err := OpenTemp(dir, uintptr(testDataLength))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	buf := make([]byte, testDataLength)
+	if _, err := m.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testZeroData) != 0 {
+		t.Fatalf("data must be %v, %v found", testZeroData, buf)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("directory must be empty, %d entries found", len(entries))
+	}
+}
+
+// TestFileCreation tests the CreateFile and OpenExistingFile functions.
+// CASE 1: The ErrFileNotExist MUST be returned for the file which does not exist.
+// CASE 2: The ErrFileExists MUST be returned for the file which already exists.
+// CASE 3: The data read on the opening of the existing file MUST be exactly the same as previously written
+// on the creation.
+func TestFileCreation(t *testing.T) {
+	filePath := nextTestFilePath(t)
+	if _, err := OpenExistingFile(filePath, uintptr(testDataLength), ModeReadWrite, 0); err != ErrFileNotExist {
+		t.Fatalf("expected ErrFileNotExist, [%v] error found", err)
+	}
+	m, err := CreateFile(filePath, testFileMode, uintptr(testDataLength), 0, func(m *Mapping) error {
+		_, err := m.WriteAt(testData, 0)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	closeTestEntity(t, m)
+	if _, err := CreateFile(filePath, testFileMode, uintptr(testDataLength), 0, nil); err != ErrFileExists {
+		t.Fatalf("expected ErrFileExists, [%v] error found", err)
+	}
+	m, err = OpenExistingFile(filePath, 0, ModeReadOnly, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	buf := make([]byte, testDataLength)
+	if _, err := m.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+}
+
+// TestFileValidation tests the validator of the existing file.
+// CASE 1: The validator MUST NOT be called for the file which was just created.
+// CASE 2: The error of the validator MUST be returned for the existing file.
+func TestFileValidation(t *testing.T) {
+	errInvalid := errors.New("invalid")
+	validateCallCount := 0
+	validate := WithValidator(func(m *Mapping) error {
+		validateCallCount++
+		buf := make([]byte, testDataLength)
+		if _, err := m.ReadAt(buf, 0); err != nil {
+			return err
+		}
+		if bytes.Compare(buf, testData) != 0 {
+			return errInvalid
+		}
+		return nil
+	})
+	filePath := nextTestFilePath(t)
+	m, err := OpenFile(filePath, testFileMode, uintptr(testDataLength), 0, nil, validate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closeTestEntity(t, m)
+	if validateCallCount != 0 {
+		t.Fatalf("validator must not be called, %d calls found", validateCallCount)
+	}
+	if _, err := OpenFile(filePath, testFileMode, uintptr(testDataLength), 0, nil, validate); err != errInvalid {
+		t.Fatalf("expected validation error, [%v] error found", err)
+	}
+	if validateCallCount != 1 {
+		t.Fatalf("validator must be called once, %d calls found", validateCallCount)
+	}
+}
+
+// TestGrowable tests the mapping which grows automatically.
+// CASE 1: The mapping MUST grow by the growth factor on the write beyond its end.
+// CASE 2: The data which is read directly from the underlying file MUST be exactly the same
+// as the previously written beyond the initial end of the mapping.
+func TestGrowable(t *testing.T) {
+	f := openNextTestFile(t, false)
+	defer closeTestEntity(t, f)
+	m, err := Open(f.Fd(), 0, uintptr(testDataLength), ModeReadWrite, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := Growable(m, 2)
+	defer closeTestEntity(t, g)
+	if _, err := g.WriteAt(testData, int64(testDataLength)); err != nil {
+		t.Fatal(err)
+	}
+	if g.Length() != uintptr(2*testDataLength) {
+		t.Fatalf("length must be %d, %d found", 2*testDataLength, g.Length())
+	}
+	if err := g.Close(); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 2*testDataLength)
+	if _, err := f.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf[testDataLength:], testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf[testDataLength:])
+	}
+}
+
+// TestTruncate tests the resizing of the mapping.
+// CASE 1: The length of the mapping and the size of the underlying file MUST be changed.
+// CASE 2: The segment MUST be reinitialized on top of the new mapped memory.
+// CASE 3: The data of the anonymous mapping MUST be retained.
+func TestTruncate(t *testing.T) {
+	f := openNextTestFile(t, false)
+	defer closeTestEntity(t, f)
+	m, err := Open(f.Fd(), 0, uintptr(testDataLength), ModeReadWrite, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	seg := m.Segment()
+	if err := m.Truncate(2); err != nil {
+		t.Fatal(err)
+	}
+	if m.Length() != 2 {
+		t.Fatalf("length must be 2, %d found", m.Length())
+	}
+	if m.Segment() == seg {
+		t.Fatal("segment must be reinitialized")
+	}
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != 2 {
+		t.Fatalf("file size must be 2, %d found", info.Size())
+	}
+	a, err := OpenAnonymous(uintptr(testDataLength), ModeReadWrite, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, a)
+	if _, err := a.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Truncate(uintptr(os.Getpagesize() + testDataLength)); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, testDataLength)
+	if _, err := a.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+}
+
+// TestRegion tests the bounds-checked view of the mapped memory.
+// CASE 1: The data which is read through the mapping MUST be exactly the same
+// as the previously written through the region at its offset.
+// CASE 2: The ErrOutOfBounds MUST be returned for the access beyond the region.
+func TestRegion(t *testing.T) {
+	m, err := OpenAnonymous(uintptr(2*testDataLength), ModeReadWrite, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	r, err := m.Region(int64(testDataLength), uintptr(testDataLength))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, testDataLength)
+	if _, err := m.ReadAt(buf, int64(testDataLength)); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+	if _, err := r.WriteAt(testData, 1); err != ErrOutOfBounds {
+		t.Fatalf("expected ErrOutOfBounds, [%v] error found", err)
+	}
+	if _, err := m.Region(int64(testDataLength)+1, uintptr(testDataLength)); err != ErrOutOfBounds {
+		t.Fatalf("expected ErrOutOfBounds, [%v] error found", err)
+	}
+}
+
+// TestSplit tests the splitting of the mapped memory into regions.
+// CASE 1: The regions MUST cover the whole mapped memory without overlapping.
+// CASE 2: The data which is read directly from the underlying file MUST be exactly the same
+// as the previously written through the regions and synchronized.
+func TestSplit(t *testing.T) {
+	f := openNextTestFile(t, false)
+	defer closeTestEntity(t, f)
+	m, err := Open(f.Fd(), 0, uintptr(testDataLength), ModeReadWrite, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	regions, err := m.Split(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offset := int64(0)
+	for _, r := range regions {
+		if r.Offset() != offset {
+			t.Fatalf("offset must be %d, %d found", offset, r.Offset())
+		}
+		if _, err := r.WriteAt(testData[offset:offset+int64(r.Length())], 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Sync(); err != nil {
+			t.Fatal(err)
+		}
+		offset += int64(r.Length())
+	}
+	if offset != int64(testDataLength) {
+		t.Fatalf("total length must be %d, %d found", testDataLength, offset)
+	}
+	buf := make([]byte, testDataLength)
+	if _, err := f.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+}
+
+// TestClone tests the independent view of the same file.
+// CASE 1: The data which is read through the read-only clone MUST be exactly the same
+// as the previously written through the original mapping.
+// CASE 2: The clone MUST remain valid after the original mapping is closed.
+func TestClone(t *testing.T) {
+	m := openTestMapping(t, ModeReadWrite)
+	defer closeTestEntity(t, m)
+	c, err := m.Clone(ModeReadOnly)
+	if err == ErrNotSupported {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, c)
+	if c.Writable() {
+		t.Fatal("clone must be read-only")
+	}
+	if _, err := m.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, testDataLength)
+	if _, err := c.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+}
+
+// TestFreeze tests the point-in-time snapshot of the mapping.
+// CASE 1: The snapshot MUST be read-only.
+// CASE 2: The data of the snapshot MUST NOT be changed by the subsequent writes through the original mapping.
+// CASE 3: The snapshot of the tracked mapping MUST NOT track the dirty pages.
+func TestFreeze(t *testing.T) {
+	m := openTestMapping(t, ModeReadWrite)
+	defer closeTestEntity(t, m)
+	if _, err := m.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	s, err := m.Freeze()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, s)
+	if _, err := s.WriteAt(testData, 0); err != ErrReadOnly {
+		t.Fatalf("expected ErrReadOnly, [%v] error found", err)
+	}
+	if _, err := m.WriteAt(testZeroData, 0); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, testDataLength)
+	if _, err := s.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+	for _, opt := range []Option{WithDirtyTracking(), WithWriteProtectTracking()} {
+		a, err := OpenAnonymous(uintptr(testDataLength), ModeReadWrite, 0, opt)
+		if err == ErrNotSupported {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := a.Freeze()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.dirty != nil || s.wp != nil || s.options.dirtyTracking || s.options.writeProtect {
+			t.Fatal("snapshot must not track the dirty pages")
+		}
+		closeTestEntity(t, s)
+		closeTestEntity(t, a)
+	}
+	f := openNextTestFile(t, false)
+	defer closeTestEntity(t, f)
+	w, err := Open(f.Fd(), 0, uintptr(testDataLength), ModeReadWrite, 0, WithDirtyTracking())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, w)
+	s, err = w.Freeze()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, s)
+	if s.dirty != nil || s.options.dirtyTracking {
+		t.Fatal("snapshot must not track the dirty pages")
+	}
+}
+
+// TestSeal tests the protection of the mapped memory from writing.
+// CASE 1: The ErrReadOnly MUST be returned for the write after the sealing.
+// CASE 2: The data which is read after the sealing MUST be exactly the same as the previously written.
+func TestSeal(t *testing.T) {
+	m := openTestMapping(t, ModeReadWrite)
+	defer closeTestEntity(t, m)
+	if _, err := m.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Seal(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.WriteAt(testData, 0); err != ErrReadOnly {
+		t.Fatalf("expected ErrReadOnly, [%v] error found", err)
+	}
+	buf := make([]byte, testDataLength)
+	if _, err := m.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+}
+
+// TestSynced tests the concurrency-safe wrapper of the mapping.
+// CASE 1: The concurrent reads MUST either succeed or return ErrClosed while the mapping is closed.
+// CASE 2: The ErrClosed MUST be returned for the reads after the closing.
+func TestSynced(t *testing.T) {
+	s := Synced(openTestMapping(t, ModeReadWrite))
+	defer closeTestEntity(t, s)
+	if _, err := s.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, testDataLength)
+			for {
+				if _, err := s.ReadAt(buf, 0); err != nil {
+					if err != ErrClosed {
+						errs <- err
+					}
+					return
+				}
+			}
+		}()
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	if _, err := s.ReadAt(make([]byte, 1), 0); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, [%v] error found", err)
+	}
+}
+
+// TestCursor tests the stream-oriented view of the mapped memory.
+// CASE 1: The data which is read by the cursor MUST be exactly the same as the previously written by it.
+// CASE 2: The io.EOF MUST be returned when the cursor reaches the end of the mapped memory.
+// CASE 3: The ErrOutOfBounds MUST be returned for the write which does not fit into the mapped memory.
+// CASE 4: The ErrBadOffset MUST be returned for the negative position.
+func TestCursor(t *testing.T) {
+	m := openTestMapping(t, ModeReadWrite)
+	defer closeTestEntity(t, m)
+	c := m.NewCursor()
+	if _, err := c.Write(testData); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := ioutil.ReadAll(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+	if _, err := c.ReadByte(); err != io.EOF {
+		t.Fatalf("expected io.EOF, [%v] error found", err)
+	}
+	if _, err := c.Seek(-1, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := c.Write(testData); err != ErrOutOfBounds || n != 1 {
+		t.Fatalf("expected ErrOutOfBounds after 1 byte, [%v] error found after %d bytes", err, n)
+	}
+	if _, err := c.Seek(-1, io.SeekStart); err != ErrBadOffset {
+		t.Fatalf("expected ErrBadOffset, [%v] error found", err)
+	}
+}
+
+// TestCopying tests the direct copying of the mapped memory without an intermediate buffer.
+// CASE 1: The data which is copied into the mapping MUST be exactly the same as the source data.
+// CASE 2: The data which is copied from the region MUST be exactly the same as the part of the mapped memory.
+// CASE 3: The data which is copied by io.Copy from the cursor MUST be exactly the same as the mapped memory.
+func TestCopying(t *testing.T) {
+	m := openTestMapping(t, ModeReadWrite)
+	defer closeTestEntity(t, m)
+	if n, err := m.ReadFrom(bytes.NewReader(testData)); err != nil || n != int64(testDataLength) {
+		t.Fatalf("expected %d bytes copied, %d bytes copied with [%v] error", testDataLength, n, err)
+	}
+	buf := make([]byte, testDataLength)
+	if _, err := m.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+	r, err := m.Region(1, uintptr(testDataLength-1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if _, err := r.WriteTo(&out); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(out.Bytes(), testData[1:]) != 0 {
+		t.Fatalf("data must be %q, %v found", testData[1:], out.Bytes())
+	}
+	out.Reset()
+	if _, err := io.Copy(&out, m.NewCursor()); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(out.Bytes(), testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, out.Bytes())
+	}
+}
+
+// TestCopy tests the copying between the mappings.
+// CASE 1: The data which is copied to another mapping MUST be exactly the same as the source data.
+// CASE 2: The overlapping ranges within the same mapping MUST be copied as if through an intermediate buffer.
+// CASE 3: The ErrOutOfBounds MUST be returned if any of the ranges is out of bounds.
+func TestCopy(t *testing.T) {
+	src := openTestMapping(t, ModeReadWrite)
+	defer closeTestEntity(t, src)
+	dst := openTestMapping(t, ModeReadWrite)
+	defer closeTestEntity(t, dst)
+	if _, err := src.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := Copy(dst, 0, src, 0, uintptr(testDataLength)); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, testDataLength)
+	if _, err := dst.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+	if err := Copy(src, 1, src, 0, uintptr(testDataLength-1)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	expected := append(testData[:1:1], testData[:testDataLength-1]...)
+	if bytes.Compare(buf, expected) != 0 {
+		t.Fatalf("data must be %q, %v found", expected, buf)
+	}
+	if err := Copy(dst, 1, src, 0, uintptr(testDataLength)); err != ErrOutOfBounds {
+		t.Fatalf("expected ErrOutOfBounds, [%v] error found", err)
+	}
+}
+
+// TestFillRange tests the filling of the mapped memory.
+// CASE 1: The filled range MUST contain the given byte only and the rest of data MUST be unchanged.
+// CASE 2: The zeroed range MUST contain zeros only.
+// CASE 3: The ErrOutOfBounds MUST be returned if the range is out of bounds.
+func TestFillRange(t *testing.T) {
+	m := openTestMapping(t, ModeReadWrite)
+	defer closeTestEntity(t, m)
+	if err := m.FillRange(1, uintptr(testDataLength-1), 'X'); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, testDataLength)
+	if _, err := m.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	expected := append([]byte{0}, bytes.Repeat([]byte{'X'}, testDataLength-1)...)
+	if bytes.Compare(buf, expected) != 0 {
+		t.Fatalf("data must be %q, %v found", expected, buf)
+	}
+	if err := m.ZeroRange(0, uintptr(testDataLength)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testZeroData) != 0 {
+		t.Fatalf("data must be %q, %v found", testZeroData, buf)
+	}
+	if err := m.ZeroRange(1, uintptr(testDataLength)); err != ErrOutOfBounds {
+		t.Fatalf("expected ErrOutOfBounds, [%v] error found", err)
+	}
+}
+
+// TestCompareRange tests the comparison of the mapped memory.
+// CASE 1: The -1 MUST be returned for the equal data.
+// CASE 2: The index of the first mismatching byte MUST be returned for the different data.
+// CASE 3: The ErrOutOfBounds MUST be returned if the range is out of bounds.
+func TestCompareRange(t *testing.T) {
+	a := openTestMapping(t, ModeReadWrite)
+	defer closeTestEntity(t, a)
+	b := openTestMapping(t, ModeReadWrite)
+	defer closeTestEntity(t, b)
+	if _, err := a.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	if equal, err := a.EqualRange(0, testData); err != nil || !equal {
+		t.Fatalf("data must be equal, [%v] error found", err)
+	}
+	if i, err := a.CompareRange(0, testZeroData); err != nil || i != 0 {
+		t.Fatalf("expected mismatch at 0, mismatch at %d with [%v] error found", i, err)
+	}
+	if _, err := b.WriteAt(testData[:2], 0); err != nil {
+		t.Fatal(err)
+	}
+	if i, err := Compare(a, 0, b, 0, uintptr(testDataLength)); err != nil || i != 2 {
+		t.Fatalf("expected mismatch at 2, mismatch at %d with [%v] error found", i, err)
+	}
+	if _, err := a.CompareRange(1, testData); err != ErrOutOfBounds {
+		t.Fatalf("expected ErrOutOfBounds, [%v] error found", err)
+	}
+}
+
+// TestFd tests the descriptor of the mapped file.
+// CASE 1: The descriptor of the file mapping MUST be valid.
+// CASE 2: The ^uintptr(0) MUST be returned for the anonymous and closed mappings.
+func TestFd(t *testing.T) {
+	f := openNextTestFile(t, false)
+	defer closeTestEntity(t, f)
+	m, err := Open(f.Fd(), 0, uintptr(testDataLength), ModeReadWrite, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	if fd := m.Fd(); fd == ^uintptr(0) {
+		t.Fatal("expected valid descriptor for the file mapping")
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if fd := m.Fd(); fd != ^uintptr(0) {
+		t.Fatalf("expected ^uintptr(0) for the closed mapping, %d found", fd)
+	}
+	a, err := OpenAnonymous(uintptr(testDataLength), ModeReadWrite, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, a)
+	if fd := a.Fd(); fd != ^uintptr(0) {
+		t.Fatalf("expected ^uintptr(0) for the anonymous mapping, %d found", fd)
+	}
+}
+
+// TestAlignedBounds tests the bounds of the underlying view.
+// CASE 1: The offset MUST be the same as given on opening.
+// CASE 2: The aligned offset MUST be aligned by Granularity and precede the offset.
+// CASE 3: The aligned length MUST include the part which precedes the offset.
+func TestAlignedBounds(t *testing.T) {
+	f := openNextTestFile(t, false)
+	defer closeTestEntity(t, f)
+	pageSize := int64(os.Getpagesize())
+	granularity := int64(Granularity())
+	if err := f.Truncate(2*granularity + pageSize); err != nil {
+		t.Fatal(err)
+	}
+	offset := granularity + pageSize + 3
+	m, err := Open(f.Fd(), offset, uintptr(testDataLength), ModeReadOnly, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	if m.Offset() != offset {
+		t.Fatalf("offset must be %d, %d found", offset, m.Offset())
+	}
+	if m.PageSize() != int(pageSize) {
+		t.Fatalf("page size must be %d, %d found", pageSize, m.PageSize())
+	}
+	if expected := offset - offset%granularity; m.AlignedOffset() != expected {
+		t.Fatalf("aligned offset must be %d, %d found", expected, m.AlignedOffset())
+	}
+	if expected := uintptr(offset%granularity + int64(testDataLength)); m.AlignedLength() != expected {
+		t.Fatalf("aligned length must be %d, %d found", expected, m.AlignedLength())
+	}
+}
+
+// TestLeakHandler tests the replacement of the finalizer of the mapping.
+// CASE 1: The leak handler MUST be called for the unreachable mapping which is not closed.
+func TestLeakHandler(t *testing.T) {
+	leaks := make(chan *Mapping, 1)
+	m, err := OpenAnonymous(uintptr(testDataLength), ModeReadWrite, 0, WithLeakHandler(func(m *Mapping) {
+		leaks <- m
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = nil
+	for i := 0; i < 10; i++ {
+		runtime.GC()
+		select {
+		case m = <-leaks:
+			closeTestEntity(t, m)
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Fatal("leak handler must be called")
+}
+
+// TestCloseErrors tests the errors returned by Close.
+// CASE 1: The single error MUST be returned as is.
+// CASE 2: All errors MUST be returned joined.
+func TestCloseErrors(t *testing.T) {
+	f := openNextTestFile(t, false)
+	m, err := OpenFD(f, 0, uintptr(testDataLength), ModeReadWrite, 0, WithCloseFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	closeTestEntity(t, f)
+	if err := m.Close(); !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("expected os.ErrClosed, [%v] error found", err)
+	}
+	if err := joinErrors([]error{ErrLocked}); err != ErrLocked {
+		t.Fatalf("expected ErrLocked, [%v] error found", err)
+	}
+	err = joinErrors([]error{ErrLocked, ErrClosed})
+	if !errors.Is(err, ErrLocked) || !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrLocked and ErrClosed, [%v] error found", err)
+	}
+}
+
+// TestStat tests the snapshot of the mapping state.
+// CASE 1: The snapshot MUST describe the mapping as it is opened.
+// CASE 2: The compact description MUST contain the path, the mode and the flags of the mapping.
+// CASE 3: The ErrClosed MUST be returned for the closed mapping.
+func TestStat(t *testing.T) {
+	path := nextTestFilePath(t)
+	m, err := OpenFile(path, testFileMode, uintptr(testDataLength), FlagDontFork, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	s, err := m.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Path != path || s.Length != uintptr(testDataLength) || s.Mode != ModeReadWrite || s.Flags != FlagDontFork {
+		t.Fatalf("unexpected stat %+v", s)
+	}
+	if s.Pages != 1 || s.ResidentPages > s.Pages {
+		t.Fatalf("unexpected pages in stat %+v", s)
+	}
+	expected := "mmap(" + strconv.Quote(path) + " @0+" + strconv.Itoa(testDataLength) + " rw dontfork)"
+	if str := m.String(); str != expected {
+		t.Fatalf("string must be %s, %s found", expected, str)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Stat(); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, [%v] error found", err)
+	}
+}
+
+// testMetrics is the metrics receiver which counts the calls.
+type testMetrics struct {
+	opened, closed, synced, lockFailed, outOfBounds int
+}
+
+func (tm *testMetrics) Opened(length uintptr)                             { tm.opened++ }
+func (tm *testMetrics) Closed(length uintptr)                             { tm.closed++ }
+func (tm *testMetrics) Synced(length uintptr, d time.Duration, err error) { tm.synced++ }
+func (tm *testMetrics) LockFailed(err error)                              { tm.lockFailed++ }
+func (tm *testMetrics) OutOfBounds()                                      { tm.outOfBounds++ }
+
+// TestMetrics tests the metrics receiver.
+// CASE 1: The opening, closing, synchronization and access violation MUST be reported.
+// CASE 2: Nothing MUST be reported after the metrics are disabled.
+func TestMetrics(t *testing.T) {
+	tm := &testMetrics{}
+	SetMetrics(tm)
+	defer SetMetrics(nil)
+	m := openTestMapping(t, ModeReadWrite)
+	if err := m.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.WriteAt(testData, 1); err != ErrOutOfBounds {
+		t.Fatalf("expected ErrOutOfBounds, [%v] error found", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if tm.opened != 1 || tm.closed != 1 || tm.synced != 2 || tm.outOfBounds != 1 {
+		t.Fatalf("unexpected metrics %+v", *tm)
+	}
+	SetMetrics(nil)
+	closeTestEntity(t, openTestMapping(t, ModeReadOnly))
+	if tm.opened != 1 || tm.closed != 1 {
+		t.Fatalf("unexpected metrics %+v", *tm)
+	}
+}
+
+// testTracer is the tracer which records the traced operations.
+type testTracer []Op
+
+func (tt *testTracer) Trace(e Event) { *tt = append(*tt, e.Op) }
+
+// TestTracer tests the tracing of the mapping operations.
+// CASE 1: The opening, synchronization and closing MUST be traced in order.
+func TestTracer(t *testing.T) {
+	tt := &testTracer{}
+	SetTracer(tt)
+	defer SetTracer(nil)
+	m := openTestMapping(t, ModeReadOnly)
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	m = openTestMapping(t, ModeReadWrite)
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []Op{OpOpen, OpClose, OpOpen, OpSync, OpClose}
+	if len(*tt) != len(expected) {
+		t.Fatalf("traced operations must be %v, %v found", expected, *tt)
+	}
+	for i, op := range expected {
+		if (*tt)[i] != op {
+			t.Fatalf("traced operations must be %v, %v found", expected, *tt)
+		}
+	}
+}
+
+// TestRegistry tests the registry of the live mappings.
+// CASE 1: The mapping which is opened while the registry is enabled MUST be live until it is closed.
+// CASE 2: The dump of the live mappings MUST contain the path of the mapped file.
+func TestRegistry(t *testing.T) {
+	EnableRegistry(true)
+	defer EnableRegistry(false)
+	path := nextTestFilePath(t)
+	m, err := OpenFile(path, testFileMode, uintptr(testDataLength), 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	live := Live()
+	if len(live) != 1 || live[0].Address != m.Address() || live[0].Path != path {
+		t.Fatalf("unexpected live mappings %+v", live)
+	}
+	var buf bytes.Buffer
+	if err := DumpLive(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(strconv.Quote(path))) {
+		t.Fatalf("dump must contain the path, %q found", buf.String())
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if live := Live(); len(live) != 0 {
+		t.Fatalf("unexpected live mappings %+v", live)
+	}
+}
+
+// TestAutoSync tests the background synchronization of the mapped memory.
+// CASE 1: The background synchronization MUST be performed periodically.
+// CASE 2: The background synchronization MUST survive the mapping again by Truncate.
+// CASE 3: The background synchronization MUST be stopped when the mapping is closed.
+func TestAutoSync(t *testing.T) {
+	syncs := &testSyncCounter{}
+	SetTracer(syncs)
+	defer SetTracer(nil)
+	f := openNextTestFile(t, false)
+	defer closeTestEntity(t, f)
+	m, err := Open(f.Fd(), 0, uintptr(testDataLength), ModeReadWrite, 0, WithAutoSync(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	if err := m.Truncate(uintptr(2 * testDataLength)); err != nil {
+		t.Fatal(err)
+	}
+	if m.autoSync == nil {
+		t.Fatal("background synchronization must survive the truncation")
+	}
+	for i := 0; atomic.LoadInt32(&syncs.n) < 2; i++ {
+		if i == 100 {
+			t.Fatal("background synchronization must be performed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if m.autoSync != nil {
+		t.Fatal("background synchronization must be stopped")
+	}
+}
+
+// testSyncCounter is the tracer which counts the synchronizations concurrently.
+type testSyncCounter struct {
+	n int32
+}
+
+func (c *testSyncCounter) Trace(e Event) {
+	if e.Op == OpSync {
+		atomic.AddInt32(&c.n, 1)
+	}
+}
+
+// testSyncLengths is the tracer which records the lengths of the synchronized memory.
+type testSyncLengths []uintptr
+
+func (tl *testSyncLengths) Trace(e Event) {
+	if e.Op == OpSync {
+		*tl = append(*tl, e.Length)
+	}
+}
+
+// TestSyncDirty tests the synchronization of the written mapped memory pages only.
+// CASE 1: Only the written and marked pages MUST be synchronized merging the adjacent ones.
+// CASE 2: Nothing MUST be synchronized if there are no written pages.
+func TestSyncDirty(t *testing.T) {
+	f := openNextTestFile(t, false)
+	defer closeTestEntity(t, f)
+	pageSize := os.Getpagesize()
+	if err := f.Truncate(int64(8 * pageSize)); err != nil {
+		t.Fatal(err)
+	}
+	m, err := Open(f.Fd(), 0, uintptr(8*pageSize), ModeReadWrite, 0, WithDirtyTracking())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	if _, err := m.WriteAt(testData, int64(2*pageSize-1)); err != nil {
+		t.Fatal(err)
+	}
+	*m.Segment().Uint8(int64(6 * pageSize)) = 1
+	if err := m.MarkDirty(int64(6*pageSize), 1); err != nil {
+		t.Fatal(err)
+	}
+	tl := &testSyncLengths{}
+	SetTracer(tl)
+	defer SetTracer(nil)
+	if err := m.SyncDirty(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SyncDirty(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []uintptr{uintptr(2 * pageSize), uintptr(pageSize)}
+	if len(*tl) != len(expected) || (*tl)[0] != expected[0] || (*tl)[1] != expected[1] {
+		t.Fatalf("synchronized lengths must be %v, %v found", expected, *tl)
+	}
+}
+
+// TestWriteProtectTracking tests the dirty tracking of the direct writes by the write-protected pages.
+// CASE 1: The pages written through the segment MUST be synchronized merging the adjacent ones.
+// CASE 2: Nothing MUST be synchronized if there are no written pages.
+// CASE 3: The page written again after the synchronization MUST be synchronized again.
+func TestWriteProtectTracking(t *testing.T) {
+	pageSize := os.Getpagesize()
+	m, err := OpenAnonymous(uintptr(8*pageSize), ModeReadWrite, 0, WithWriteProtectTracking())
+	if err != nil {
+		if err == ErrNotSupported {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	tl := &testSyncLengths{}
+	SetTracer(tl)
+	defer SetTracer(nil)
+	*m.Segment().Uint8(int64(2 * pageSize)) = 1
+	*m.Segment().Uint8(int64(5*pageSize + 1)) = 1
+	*m.Segment().Uint8(int64(7*pageSize - 1)) = 1
+	if err := m.SyncDirty(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SyncDirty(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []uintptr{uintptr(pageSize), uintptr(2 * pageSize)}
+	if len(*tl) != len(expected) || (*tl)[0] != expected[0] || (*tl)[1] != expected[1] {
+		t.Fatalf("synchronized lengths must be %v, %v found", expected, *tl)
+	}
+	*tl = nil
+	*m.Segment().Uint8(int64(5 * pageSize)) = 2
+	if err := m.SyncDirty(); err != nil {
+		t.Fatal(err)
+	}
+	if len(*tl) != 1 || (*tl)[0] != uintptr(pageSize) {
+		t.Fatalf("synchronized lengths must be %v, %v found", []uintptr{uintptr(pageSize)}, *tl)
+	}
+}
+
+// TestSoftDirty tests the soft-dirty bits of the mapped memory pages.
+// CASE 1: Only the page which is written directly after the reset MUST be soft-dirty.
+// CASE 2: The reset MUST return ErrNotSupported if the kernel does not set the soft-dirty bits.
+func TestSoftDirty(t *testing.T) {
+	pageSize := os.Getpagesize()
+	m, err := OpenAnonymous(uintptr(2*pageSize), ModeReadWrite, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	if err := ResetSoftDirty(); err != nil {
+		if err == ErrNotSupported || os.IsPermission(err) {
+			t.Skip("soft-dirty bits are not supported")
+		}
+		t.Fatal(err)
+	}
+	*m.Segment().Uint8(int64(pageSize)) = 1
+	dirty, err := m.SoftDirty(0, m.Length())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dirty[1] || dirty[0] {
+		t.Fatalf("only the second page must be soft-dirty, %v found", dirty)
+	}
+}
+
+// TestPool tests the sharing of the mappings by the pool.
+// CASE 1: The references to the same file region MUST share the same mapping.
+// CASE 2: The mapping MUST stay open until the last reference is released.
+// CASE 3: The released reference MUST return ErrClosed on the second release.
+// CASE 4: ModeWriteCopy MUST NOT be allowed.
+func TestPool(t *testing.T) {
+	f := openNextTestFile(t, false)
+	closeTestEntity(t, f)
+	p := NewPool(0)
+	r1, err := p.Open(f.Name(), 0, uintptr(testDataLength), ModeReadWrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, r1)
+	r2, err := p.Open(f.Name(), 0, uintptr(testDataLength), ModeReadWrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, r2)
+	m := r1.Mapping()
+	if r2.Mapping() != m {
+		t.Fatal("references must share the same mapping")
+	}
+	if p.Len() != 1 {
+		t.Fatalf("pool must contain 1 mapping, %d found", p.Len())
+	}
+	if err := r1.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r1.Close(); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, [%v] error found", err)
+	}
+	if _, err := m.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := r2.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if m.Length() != 0 {
+		t.Fatal("mapping must be closed")
+	}
+	if p.Len() != 0 {
+		t.Fatalf("pool must be empty, %d mappings found", p.Len())
+	}
+	buf, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+	if _, err := p.Open(f.Name(), 0, uintptr(testDataLength), ModeWriteCopy); err != ErrBadMode {
+		t.Fatalf("expected ErrBadMode, [%v] error found", err)
+	}
+}
+
+// TestPunchHole tests the deallocation of the mapped file region.
+// CASE 1: The punched region MUST read back as zeros.
+// CASE 2: The file size MUST NOT be changed.
+// CASE 3: The read-only mapping MUST NOT allow it.
+func TestPunchHole(t *testing.T) {
+	pageSize := os.Getpagesize()
+	f := openNextTestFile(t, false)
+	defer closeTestEntity(t, f)
+	if err := f.Truncate(int64(3 * pageSize)); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenFD(f, 0, uintptr(3*pageSize), ModeReadWrite, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	if err := m.FillRange(0, m.Length(), 0xff); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.PunchHole(int64(pageSize), uintptr(pageSize)); err != nil {
+		if err == ErrNotSupported {
+			t.Skip("punching holes is not supported")
+		}
+		t.Fatal(err)
+	}
+	buf := make([]byte, 3*pageSize)
+	if _, err := m.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	for i, b := range buf {
+		if hole := i >= pageSize && i < 2*pageSize; (b == 0) != hole {
+			t.Fatalf("byte %d must be %t zero, %d found", i, hole, b)
+		}
+	}
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != int64(3*pageSize) {
+		t.Fatalf("file size must be %d, %d found", 3*pageSize, info.Size())
+	}
+	if err := m.PunchHole(int64(3*pageSize), 1); err != ErrOutOfBounds {
+		t.Fatalf("expected ErrOutOfBounds, [%v] error found", err)
+	}
+	r := openTestMapping(t, ModeReadOnly)
+	defer closeTestEntity(t, r)
+	if err := r.PunchHole(0, 1); err != ErrReadOnly {
+		t.Fatalf("expected ErrReadOnly, [%v] error found", err)
+	}
+}
+
+// TestWatch tests the watching for the external modifications of the mapped file.
+// CASE 1: The write through the descriptor MUST be reported as ChangeWritten.
+// CASE 2: The truncation below the end of the mapped memory MUST be reported as ChangeTruncated.
+// CASE 3: The removal of the file MUST be reported as ChangeReplaced.
+// CASE 4: The channel MUST be closed when the mapping is closed.
+func TestWatch(t *testing.T) {
+	f := openNextTestFile(t, false)
+	defer closeTestEntity(t, f)
+	m, err := OpenFD(f, 0, uintptr(testDataLength), ModeReadWrite, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	changes, err := m.Watch()
+	if err == ErrNotSupported {
+		t.Skip("watching is not supported")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := func(change Change) {
+		t.Helper()
+		select {
+		case c := <-changes:
+			if c != change {
+				t.Fatalf("change must be %s, %s found", change, c)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("change %s is not reported", change)
+		}
+	}
+	if _, err := f.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	expect(ChangeWritten)
+	if err := f.Truncate(1); err != nil {
+		t.Fatal(err)
+	}
+	expect(ChangeTruncated)
+	if err := f.Truncate(int64(testDataLength)); err != nil {
+		t.Fatal(err)
+	}
+	expect(ChangeWritten)
+	if err := os.Remove(f.Name()); err != nil {
+		t.Fatal(err)
+	}
+	expect(ChangeReplaced)
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for range changes {
+	}
+}
+
+// TestWarmup tests the warming of the mapped memory.
+// CASE 1: All mapped memory pages MUST be resident in RAM.
+// CASE 2: The progress MUST reach the total length.
+func TestWarmup(t *testing.T) {
+	length := uintptr(3<<20 + 1)
+	m, err := OpenAnonymous(length, ModeReadWrite, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	var last, total uintptr
+	if err := m.Warmup(4, func(warmed, all uintptr) {
+		if warmed <= last {
+			t.Errorf("progress must grow, %d after %d found", warmed, last)
+		}
+		last, total = warmed, all
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if last != total || total != m.AlignedLength() {
+		t.Fatalf("progress must reach %d, %d of %d found", m.AlignedLength(), last, total)
+	}
+	resident, err := m.Residency(0, length)
+	if err != nil {
+		if err == ErrNotSupported {
+			return
+		}
+		t.Fatal(err)
+	}
+	for i, r := range resident {
+		if !r {
+			t.Fatalf("page %d must be resident", i)
+		}
+	}
+}
+
+// TestAccessPattern tests the mapping with the expected access pattern.
+// CASE: The mapping MUST work correctly with the read-ahead hints.
+func TestAccessPattern(t *testing.T) {
+	for _, opt := range []Option{WithSequentialAccess(), WithRandomAccess()} {
+		f := openNextTestFile(t, false)
+		m, err := OpenFD(f, 0, uintptr(testDataLength), ModeReadWrite, 0, opt)
+		closeTestEntity(t, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.WriteAt(testData, 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.Close(); err != nil {
+			t.Fatal(err)
+		}
+		buf, err := ioutil.ReadFile(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Compare(buf, testData) != 0 {
+			t.Fatalf("data must be %q, %v found", testData, buf)
+		}
+		a, err := OpenAnonymous(uintptr(testDataLength), ModeReadWrite, 0, opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		closeTestEntity(t, a)
+	}
+}
+
+// TestSnapshot tests the snapshot of the mapped memory.
+// CASE 1: The snapshot MUST contain the data of the mapping at the moment of the call.
+// CASE 2: The snapshot file MUST replace the existing one.
+func TestSnapshot(t *testing.T) {
+	m := openTestMapping(t, ModeReadWrite)
+	defer closeTestEntity(t, m)
+	if _, err := m.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if n, err := m.Snapshot(&buf); err != nil || n != int64(testDataLength) {
+		t.Fatalf("expected %d bytes, %d bytes and [%v] error found", testDataLength, n, err)
+	}
+	if bytes.Compare(buf.Bytes(), testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf.Bytes())
+	}
+	path := nextTestFilePath(t)
+	if err := ioutil.WriteFile(path, []byte("OLD"), testFileMode); err != nil {
+		t.Fatal(err)
+	}
+	s := Synced(m)
+	if err := s.SnapshotToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(data, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, data)
+	}
+}
+
+// TestCode tests the loading of the machine code.
+// CASE 1: The sealed code MUST be executable and MUST NOT be writable.
+// CASE 2: The sealed code MUST be callable.
+func TestCode(t *testing.T) {
+	code := map[string][]byte{
+		"amd64": {0xc3},                   // RET
+		"arm64": {0xc0, 0x03, 0x5f, 0xd6}, // RET
+	}[runtime.GOARCH]
+	if code == nil {
+		code = []byte{0}
+	}
+	m, address, err := LoadCode(code)
+	if err == ErrNotSupported {
+		t.Skip("code loading is not supported")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	if address != m.Address() || !m.Executable() || m.Writable() {
+		t.Fatal("code must be executable and must not be writable")
+	}
+	if _, err := m.WriteAt(code, 0); err != ErrReadOnly {
+		t.Fatalf("expected ErrReadOnly, [%v] error found", err)
+	}
+	if _, err := m.SealCode(); err != ErrReadOnly {
+		t.Fatalf("expected ErrReadOnly, [%v] error found", err)
+	}
+	if runtime.GOARCH == "amd64" || runtime.GOARCH == "arm64" {
+		// The function value is the pointer to the code pointer.
+		pc := &address
+		fn := *(*func())(unsafe.Pointer(&pc))
+		fn()
+	}
+}
+
+// TestSyncParallelism tests the concurrent synchronization of the mapped memory parts.
+// CASE: The data of every part MUST be synchronized with the file.
+func TestSyncParallelism(t *testing.T) {
+	length := 4 * syncChunk
+	f := openNextTestFile(t, false)
+	defer closeTestEntity(t, f)
+	if err := f.Truncate(int64(length)); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenFD(f, 0, uintptr(length), ModeReadWrite, 0, WithSyncParallelism(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	for offset := int64(0); offset < int64(length); offset += syncChunk {
+		if _, err := m.WriteAt(testData, offset+syncChunk-int64(testDataLength)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, testDataLength)
+	for offset := int64(0); offset < int64(length); offset += syncChunk {
+		if _, err := f.ReadAt(buf, offset+syncChunk-int64(testDataLength)); err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Compare(buf, testData) != 0 {
+			t.Fatalf("data at %d must be %q, %v found", offset, testData, buf)
+		}
+	}
+}
+
+// TestSendTo tests the sending of the mapped memory into the connection.
+// CASE 1: The part of the file mapping MUST be sent into the TCP connection.
+// CASE 2: The part of the anonymous mapping MUST be sent into the in-memory connection.
+func TestSendTo(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer l.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer c.Close()
+		buf, _ := io.ReadAll(c)
+		received <- buf
+	}()
+	m := openTestMapping(t, ModeReadWrite)
+	defer closeTestEntity(t, m)
+	if _, err := m.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	c, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := m.SendTo(c, 1, 3); err != nil || n != 3 {
+		t.Fatalf("expected 3 bytes, %d bytes and [%v] error found", n, err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if buf := <-received; bytes.Compare(buf, testData[1:4]) != 0 {
+		t.Fatalf("data must be %q, %v found", testData[1:4], buf)
+	}
+	if _, err := m.SendTo(c, 3, 3); err != ErrOutOfBounds {
+		t.Fatalf("expected ErrOutOfBounds, [%v] error found", err)
+	}
+	a, err := OpenAnonymous(uintptr(testDataLength), ModeReadWrite, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, a)
+	if _, err := a.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	r, w := net.Pipe()
+	go func() {
+		_, _ = a.SendTo(w, 0, uintptr(testDataLength))
+		_ = w.Close()
+	}()
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+}
+
+// TestLazy tests the demand-paged anonymous mapping.
+// CASE 1: The pages MUST be supplied by the fill function on first access in any order.
+// CASE 2: The error of the fill function MUST be returned by the accessing method.
+// CASE 3: The evicted pages MUST be supplied again at the next access.
+func TestLazy(t *testing.T) {
+	pageSize := os.Getpagesize()
+	var fails, fills int64
+	failure := errors.New("fill failure")
+	m, err := OpenLazy(uintptr(4*pageSize), ModeReadWrite, func(offset int64, page []byte) error {
+		if atomic.LoadInt64(&fails) != 0 {
+			return failure
+		}
+		atomic.AddInt64(&fills, 1)
+		copy(page, strconv.FormatInt(offset, 10))
+		return nil
+	})
+	if err == ErrNotSupported {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	for _, page := range []int{2, 0, 3} {
+		expected := strconv.Itoa(page * pageSize)
+		buf := make([]byte, len(expected))
+		if _, err := m.ReadAt(buf, int64(page*pageSize)); err != nil {
+			t.Fatal(err)
+		}
+		if string(buf) != expected {
+			t.Fatalf("page %d must start with %q, %q found", page, expected, buf)
+		}
+	}
+	if n := atomic.LoadInt64(&fills); n != 3 {
+		t.Fatalf("3 pages must be supplied, %d found", n)
+	}
+	atomic.StoreInt64(&fails, 1)
+	if _, err := m.ReadAt(make([]byte, 1), int64(pageSize)); err != failure {
+		t.Fatalf("expected fill failure, [%v] error found", err)
+	}
+	if err := m.Evict(0, uintptr(pageSize)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.ReadAt(make([]byte, 1), 0); err != failure {
+		t.Fatalf("expected fill failure, [%v] error found", err)
+	}
+	atomic.StoreInt64(&fails, 0)
+	if err := m.Populate(0, uintptr(4*pageSize)); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt64(&fills); n != 5 {
+		t.Fatalf("5 pages must be supplied, %d found", n)
+	}
+	if expected := strconv.Itoa(pageSize); string(m.Memory()[pageSize:pageSize+len(expected)]) != expected {
+		t.Fatalf("page 1 must start with %q, %q found", expected, m.Memory()[pageSize:pageSize+len(expected)])
+	}
+}
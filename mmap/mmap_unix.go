@@ -0,0 +1,230 @@
+//go:build unix
+
+package mmap
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Mapping is a mapping of the file into the memory.
+type Mapping struct {
+	generic
+	// fd specifies the duplicated descriptor of the mapped file or -1 for the anonymous mapping.
+	fd int
+}
+
+// fileSize returns the size of the given file or block device.
+func fileSize(fd uintptr) (int64, error) {
+	var stat unix.Stat_t
+	if err := unix.Fstat(int(fd), &stat); err != nil {
+		return 0, os.NewSyscallError("fstat", err)
+	}
+	if stat.Mode&unix.S_IFMT == unix.S_IFBLK {
+		size, err := deviceSize(int(fd))
+		if err != nil {
+			return 0, os.NewSyscallError("ioctl", err)
+		}
+		return size, nil
+	}
+	return stat.Size, nil
+}
+
+// allocationGranularity returns the memory page size which the file offset
+// and the address of the mapped memory must be aligned by.
+func allocationGranularity() int {
+	return os.Getpagesize()
+}
+
+// mapMemory maps the given file into the memory and returns the byte slice which wraps the mapped memory.
+// The given offset, length and non-zero address hint must be aligned by the memory page size.
+func (m *Mapping) mapMemory(fd uintptr, offset int64, length uintptr, mode Mode, flags Flag, o *options) ([]byte, error) {
+	prot := unix.PROT_READ
+	mmapFlags := unix.MAP_SHARED
+	if mode > ModeReadOnly {
+		prot |= unix.PROT_WRITE
+	}
+	if mode == ModeWriteCopy {
+		mmapFlags = unix.MAP_PRIVATE
+	}
+	if fd == anonymousFd {
+		mmapFlags |= unix.MAP_ANON
+	}
+	if flags&FlagExecutable != 0 {
+		prot |= unix.PROT_EXEC
+	}
+	if flags&FlagFixed != 0 {
+		mmapFlags |= mapFixedNoReplace
+	}
+	if flags&FlagPersistentMemory != 0 {
+		if mapPersistent == 0 || fd == anonymousFd {
+			return nil, ErrNotSupported
+		}
+		if mode == ModeWriteCopy {
+			return nil, ErrBadMode
+		}
+		mmapFlags = mmapFlags&^unix.MAP_SHARED | mapPersistent
+	}
+	// The address is just a hint for the kernel, it is never dereferenced.
+	hint := unsafe.Add(unsafe.Pointer(nil), o.address)
+	var b []byte
+	if flags&FlagHugePages != 0 && fd == anonymousFd {
+		b, _ = mapHugeMemory(hint, length, prot, mmapFlags)
+	}
+	if b == nil {
+		ptr, err := unix.MmapPtr(int(fd), offset, hint, length, prot, mmapFlags)
+		if err == unix.EEXIST && flags&FlagFixed != 0 {
+			return nil, ErrAddressUnavailable
+		}
+		if err == unix.EOPNOTSUPP && flags&FlagPersistentMemory != 0 {
+			return nil, ErrNotSupported
+		}
+		if err != nil {
+			return nil, os.NewSyscallError("mmap", err)
+		}
+		b = unsafe.Slice((*byte)(ptr), length)
+		if flags&FlagHugePages != 0 {
+			adviseHugePages(b)
+		}
+	}
+	if err := adviseFork(b, flags); err != nil {
+		_ = unix.MunmapPtr(unsafe.Pointer(&b[0]), uintptr(len(b)))
+		return nil, err
+	}
+
+	// The separate file descriptor is needed to resize or to map the file again
+	// after the mapped file external closing.
+	m.fd = -1
+	if fd != anonymousFd {
+		nfd, err := dupCloexec(int(fd))
+		if err != nil {
+			_ = unix.MunmapPtr(unsafe.Pointer(&b[0]), uintptr(len(b)))
+			return nil, os.NewSyscallError("fcntl", err)
+		}
+		m.fd = nfd
+	}
+	if o.access != AdviceNormal {
+		_ = m.adviseMemory(b, o.access)
+		if fd != anonymousFd {
+			_ = adviseFile(int(fd), offset, int64(length), o.access)
+		}
+	}
+	return b, nil
+}
+
+// lockMemory locks the given mapped memory pages.
+func (m *Mapping) lockMemory(b []byte) error {
+	err := unix.Mlock(b)
+	if (err == unix.ENOMEM || err == unix.EAGAIN) && m.raiseLockLimit && raiseLockLimit(uintptr(len(b))) {
+		err = unix.Mlock(b)
+	}
+	return os.NewSyscallError("mlock", err)
+}
+
+// unlockMemory unlocks the given mapped memory pages.
+func (m *Mapping) unlockMemory(b []byte) error {
+	return os.NewSyscallError("munlock", unix.Munlock(b))
+}
+
+// syncMemory synchronizes the given mapped memory pages with the underlying file.
+func (m *Mapping) syncMemory(b []byte) error {
+	return os.NewSyscallError("msync", unix.Msync(b, unix.MS_SYNC))
+}
+
+// flushMemory synchronizes the given mapped memory pages like syncMemory.
+func (m *Mapping) flushMemory(b []byte) error {
+	return m.syncMemory(b)
+}
+
+// flushFile does nothing since syncMemory waits for the file to be synchronized.
+func (m *Mapping) flushFile() error {
+	return nil
+}
+
+// protectMemory changes the protection of the given mapped memory pages.
+func (m *Mapping) protectMemory(b []byte, writable bool) error {
+	prot := unix.PROT_READ
+	if writable {
+		prot |= unix.PROT_WRITE
+	}
+	if m.executable {
+		prot |= unix.PROT_EXEC
+	}
+	return os.NewSyscallError("mprotect", unix.Mprotect(b, prot))
+}
+
+// adviseMemory gives the hint about the expected access pattern of the given mapped memory pages.
+func (m *Mapping) adviseMemory(b []byte, advice Advice) error {
+	behavior := unix.MADV_NORMAL
+	switch advice {
+	case AdviceSequential:
+		behavior = unix.MADV_SEQUENTIAL
+	case AdviceRandom:
+		behavior = unix.MADV_RANDOM
+	case AdviceWillNeed:
+		behavior = unix.MADV_WILLNEED
+	case AdviceDontNeed:
+		behavior = unix.MADV_DONTNEED
+	}
+	return os.NewSyscallError("madvise", unix.Madvise(b, behavior))
+}
+
+// residentMemory reports which of the given mapped memory pages are resident in RAM.
+func (m *Mapping) residentMemory(b []byte) ([]bool, error) {
+	vec := make([]byte, pageCount(b))
+	if err := mincore(b, vec); err == ErrNotSupported {
+		return nil, err
+	} else if err != nil {
+		return nil, os.NewSyscallError("mincore", err)
+	}
+	resident := make([]bool, len(vec))
+	for i, v := range vec {
+		resident[i] = v&1 != 0
+	}
+	return resident, nil
+}
+
+// unmapMemory unmaps the mapped memory.
+func (m *Mapping) unmapMemory() error {
+	ptr := unsafe.Pointer(&m.alignedMemory[0])
+	err := os.NewSyscallError("munmap", unix.MunmapPtr(ptr, uintptr(len(m.alignedMemory))))
+	if m.fd >= 0 {
+		if closeErr := unix.Close(m.fd); closeErr != nil && err == nil {
+			err = os.NewSyscallError("close", closeErr)
+		}
+		m.fd = -1
+	}
+	return err
+}
+
+// descriptor returns the duplicated descriptor of the mapped file or anonymousFd for the anonymous mapping.
+func (m *Mapping) descriptor() uintptr {
+	if m.fd < 0 {
+		return anonymousFd
+	}
+	return uintptr(m.fd)
+}
+
+// cloneDescriptor returns the descriptor of the mapped file to map it again by Clone.
+// The anonymous mapping can not be cloned.
+func (m *Mapping) cloneDescriptor(o *options) (uintptr, error) {
+	if m.fd < 0 {
+		return 0, ErrNotSupported
+	}
+	return uintptr(m.fd), nil
+}
+
+// truncateFile changes the size of the mapped file.
+func (m *Mapping) truncateFile(size int64) error {
+	return os.NewSyscallError("ftruncate", unix.Ftruncate(m.fd, size))
+}
+
+// punchHole deallocates the given region of the mapped file.
+func (m *Mapping) punchHole(offset, length int64) error {
+	if m.fd < 0 {
+		return ErrNotSupported
+	}
+	return punchHole(m.fd, offset, length)
+}
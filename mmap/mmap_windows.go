@@ -0,0 +1,433 @@
+package mmap
+
+import (
+	"math"
+	"os"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Mapping is a mapping of the file into the memory.
+type Mapping struct {
+	generic
+	// hProcess specifies the descriptor of the current process.
+	hProcess syscall.Handle
+	// hFile specifies the descriptor of the mapped file.
+	hFile syscall.Handle
+	// hMapping specifies the descriptor of the mapping object provided by the operation system.
+	hMapping syscall.Handle
+}
+
+// fileSize returns the size of the given file or disk.
+// Note that the volumes and the physical disks can not be mapped on Windows.
+func fileSize(fd uintptr) (int64, error) {
+	var size int64
+	var n uint32
+	err := syscall.DeviceIoControl(
+		syscall.Handle(fd), ioctlDiskGetLengthInfo,
+		nil, 0, (*byte)(unsafe.Pointer(&size)), uint32(unsafe.Sizeof(size)), &n, nil,
+	)
+	if err == nil {
+		return size, nil
+	}
+	var info syscall.ByHandleFileInformation
+	if err := syscall.GetFileInformationByHandle(syscall.Handle(fd), &info); err != nil {
+		return 0, os.NewSyscallError("GetFileInformationByHandle", err)
+	}
+	return int64(info.FileSizeHigh)<<32 | int64(info.FileSizeLow), nil
+}
+
+// granularity specifies the allocation granularity which is queried once per process.
+var granularity struct {
+	once  sync.Once
+	value int
+}
+
+// allocationGranularity returns the allocation granularity which the file offset
+// and the address of the mapped view must be aligned by, it is usually 64 KiB.
+func allocationGranularity() int {
+	granularity.once.Do(func() {
+		var info systemInfo
+		getSystemInfo(&info)
+		granularity.value = int(info.allocationGranularity)
+	})
+	return granularity.value
+}
+
+// mapMemory maps the given file into the memory and returns the byte slice which wraps the mapped memory.
+// The given offset and the non-zero address hint must be aligned by the allocation granularity.
+// FlagPersistentMemory is not supported.
+func (m *Mapping) mapMemory(fd uintptr, offset int64, length uintptr, mode Mode, flags Flag, o *options) ([]byte, error) {
+	if flags&FlagPersistentMemory != 0 {
+		return nil, ErrNotSupported
+	}
+	prot := uint32(syscall.PAGE_READONLY)
+	access := uint32(syscall.FILE_MAP_READ)
+	switch mode {
+	case ModeReadWrite:
+		prot = syscall.PAGE_READWRITE
+		access = syscall.FILE_MAP_WRITE
+	case ModeWriteCopy:
+		prot = syscall.PAGE_WRITECOPY
+		access = syscall.FILE_MAP_COPY
+	}
+	if flags&FlagExecutable != 0 {
+		prot <<= 4
+		access |= syscall.FILE_MAP_EXECUTE
+	}
+
+	// The separate file handle is needed to avoid errors on the mapped file external closing.
+	// The anonymous mapping is backed by the system paging file.
+	var err error
+	m.hProcess, err = syscall.GetCurrentProcess()
+	if err != nil {
+		return nil, os.NewSyscallError("GetCurrentProcess", err)
+	}
+	m.hFile = syscall.InvalidHandle
+	if fd != anonymousFd {
+		err = syscall.DuplicateHandle(
+			m.hProcess, syscall.Handle(fd),
+			m.hProcess, &m.hFile,
+			0, true, syscall.DUPLICATE_SAME_ACCESS,
+		)
+		if err != nil {
+			return nil, os.NewSyscallError("DuplicateHandle", err)
+		}
+		if o.access != AdviceNormal {
+			m.reopenFile(mode, o.access)
+		}
+	}
+
+	// The section handle is inheritable if it is requested to be shared with the child processes.
+	var sa *syscall.SecurityAttributes
+	if o.inheritable {
+		sa = &syscall.SecurityAttributes{InheritHandle: 1}
+		sa.Length = uint32(unsafe.Sizeof(*sa))
+	}
+
+	if flags&FlagHugePages != 0 && fd == anonymousFd && o.section == 0 && o.name == "" {
+		if b, ok := m.mapLargeMemory(length, prot, access, o.address, sa); ok {
+			return b, nil
+		}
+	}
+
+	if o.section != 0 {
+		err = syscall.DuplicateHandle(
+			m.hProcess, syscall.Handle(o.section),
+			m.hProcess, &m.hMapping,
+			0, false, syscall.DUPLICATE_SAME_ACCESS,
+		)
+		if err != nil {
+			return nil, os.NewSyscallError("DuplicateHandle", err)
+		}
+	} else {
+		maxSize := uint64(offset) + uint64(length)
+		maxSizeHigh := uint32(maxSize >> 32)
+		maxSizeLow := uint32(maxSize & uint64(math.MaxUint32))
+		var name *uint16
+		if o.name != "" {
+			if name, err = syscall.UTF16PtrFromString(o.name); err != nil {
+				_ = m.closeFile()
+				return nil, ErrBadName
+			}
+		}
+		m.hMapping, err = syscall.CreateFileMapping(m.hFile, sa, prot, maxSizeHigh, maxSizeLow, name)
+		if err != nil {
+			_ = m.closeFile()
+			return nil, os.NewSyscallError("CreateFileMapping", err)
+		}
+	}
+	fileOffset := uint64(offset)
+	fileOffsetHigh := uint32(fileOffset >> 32)
+	fileOffsetLow := uint32(fileOffset & uint64(math.MaxUint32))
+	var addr uintptr
+	if o.address != 0 {
+		addr, err = mapViewOfFileEx(m.hMapping, access, fileOffsetHigh, fileOffsetLow, length, o.address)
+		if err != nil && flags&FlagFixed != 0 {
+			_ = syscall.CloseHandle(m.hMapping)
+			_ = m.closeFile()
+			return nil, ErrAddressUnavailable
+		}
+	}
+	if addr == 0 {
+		addr, err = syscall.MapViewOfFile(m.hMapping, access, fileOffsetHigh, fileOffsetLow, length)
+		if err != nil {
+			_ = syscall.CloseHandle(m.hMapping)
+			_ = m.closeFile()
+			return nil, os.NewSyscallError("MapViewOfFile", err)
+		}
+	}
+	return viewMemory(addr, length), nil
+}
+
+// enableLockMemoryPrivilege enables the privilege which is required for large pages once per process.
+var enableLockMemoryPrivilege sync.Once
+
+// mapLargeMemory maps the memory backed by the system paging file and large pages
+// and returns the byte slice which wraps the mapped memory rounded up to the large page size.
+// It returns false if there are no large pages available.
+func (m *Mapping) mapLargeMemory(length uintptr, prot, access uint32, address uintptr, sa *syscall.SecurityAttributes) ([]byte, bool) {
+	enableLockMemoryPrivilege.Do(func() {
+		_ = enablePrivilege(seLockMemoryPrivilege)
+	})
+	pageSize := windows.GetLargePageMinimum()
+	if pageSize == 0 {
+		return nil, false
+	}
+	if rem := length % pageSize; rem != 0 {
+		if length > uintptr(MaxInt)-(pageSize-rem) {
+			return nil, false
+		}
+		length += pageSize - rem
+	}
+	maxSize := uint64(length)
+	hMapping, err := syscall.CreateFileMapping(
+		syscall.InvalidHandle, sa, prot|secCommit|secLargePages,
+		uint32(maxSize>>32), uint32(maxSize&uint64(math.MaxUint32)), nil,
+	)
+	if err != nil {
+		return nil, false
+	}
+	var addr uintptr
+	if address != 0 {
+		addr, err = mapViewOfFileEx(hMapping, access|fileMapLargePages, 0, 0, length, address)
+	}
+	if addr == 0 {
+		addr, err = syscall.MapViewOfFile(hMapping, access|fileMapLargePages, 0, 0, length)
+	}
+	if err != nil {
+		_ = syscall.CloseHandle(hMapping)
+		return nil, false
+	}
+	m.hMapping = hMapping
+	return viewMemory(addr, length), true
+}
+
+// lockMemory locks the given mapped memory pages.
+func (m *Mapping) lockMemory(b []byte) error {
+	err := syscall.VirtualLock(addressOf(b), uintptr(len(b)))
+	// VirtualLock fails unless the minimum working set of the process can hold the locked pages.
+	if err == errWorkingSetQuota && m.raiseWorkingSet(uintptr(len(b))) {
+		err = syscall.VirtualLock(addressOf(b), uintptr(len(b)))
+	}
+	if err != nil {
+		return os.NewSyscallError("VirtualLock", err)
+	}
+	return nil
+}
+
+// raiseWorkingSet raises the working set size of the process by the given length and reports whether it is raised.
+// The maximum working set size is not enforced, so the process is never trimmed because of it.
+func (m *Mapping) raiseWorkingSet(length uintptr) bool {
+	var minSize, maxSize uintptr
+	var flags uint32
+	windows.GetProcessWorkingSetSizeEx(windows.Handle(m.hProcess), &minSize, &maxSize, &flags)
+	if minSize == 0 || minSize+length < minSize || maxSize+length < maxSize {
+		return false
+	}
+	err := windows.SetProcessWorkingSetSizeEx(
+		windows.Handle(m.hProcess), minSize+length, maxSize+length,
+		windows.QUOTA_LIMITS_HARDWS_MIN_DISABLE|windows.QUOTA_LIMITS_HARDWS_MAX_DISABLE,
+	)
+	return err == nil
+}
+
+// unlockMemory unlocks the given mapped memory pages.
+func (m *Mapping) unlockMemory(b []byte) error {
+	if err := syscall.VirtualUnlock(addressOf(b), uintptr(len(b))); err != nil {
+		return os.NewSyscallError("VirtualUnlock", err)
+	}
+	return nil
+}
+
+// protectMemory changes the protection of the given mapped memory pages.
+// The writable pages of the copy-on-write mapping remain copy-on-write.
+func (m *Mapping) protectMemory(b []byte, writable bool) error {
+	prot := uint32(windows.PAGE_READONLY)
+	if writable {
+		prot = windows.PAGE_READWRITE
+	}
+	if m.executable {
+		prot <<= 4
+	}
+	var old uint32
+	if err := windows.VirtualProtect(addressOf(b), uintptr(len(b)), prot, &old); err != nil {
+		return os.NewSyscallError("VirtualProtect", err)
+	}
+	return nil
+}
+
+// syncMemory synchronizes the given mapped memory pages with the underlying file.
+func (m *Mapping) syncMemory(b []byte) error {
+	if err := m.flushMemory(b); err != nil {
+		return err
+	}
+	return m.flushFile()
+}
+
+// flushMemory writes the given mapped memory pages to the underlying file
+// without waiting for the file metadata and the disk cache.
+func (m *Mapping) flushMemory(b []byte) error {
+	if err := syscall.FlushViewOfFile(addressOf(b), uintptr(len(b))); err != nil {
+		return os.NewSyscallError("FlushViewOfFile", err)
+	}
+	return nil
+}
+
+// flushFile flushes the buffers of the underlying file to the disk.
+func (m *Mapping) flushFile() error {
+	if m.hFile == syscall.InvalidHandle {
+		return nil
+	}
+	if err := syscall.FlushFileBuffers(m.hFile); err != nil {
+		return os.NewSyscallError("FlushFileBuffers", err)
+	}
+	return nil
+}
+
+// adviseMemory gives the hint about the expected access pattern of the given mapped memory pages.
+// The pages are prefetched for AdviceWillNeed and removed from the working set for AdviceDontNeed
+// unless they are locked, other hints are ignored.
+func (m *Mapping) adviseMemory(b []byte, advice Advice) error {
+	switch advice {
+	case AdviceWillNeed:
+		entry := memoryRangeEntry{address: addressOf(b), length: uintptr(len(b))}
+		if err := prefetchVirtualMemory(m.hProcess, 1, &entry, 0); err != nil {
+			return os.NewSyscallError("PrefetchVirtualMemory", err)
+		}
+	case AdviceDontNeed:
+		if m.locked {
+			return nil
+		}
+		// Unlocking of the pages which are not locked removes them from the working set.
+		err := syscall.VirtualUnlock(addressOf(b), uintptr(len(b)))
+		if err != nil && err != errNotLocked {
+			return os.NewSyscallError("VirtualUnlock", err)
+		}
+	}
+	return nil
+}
+
+// residentMemory reports which of the given mapped memory pages are resident in RAM.
+// The page is considered resident if it belongs to the working set of the current process.
+func (m *Mapping) residentMemory(b []byte) ([]bool, error) {
+	pageSize := os.Getpagesize()
+	info := make([]windows.PSAPI_WORKING_SET_EX_INFORMATION, pageCount(b))
+	for i := range info {
+		info[i].VirtualAddress = windows.Pointer(unsafe.Pointer(&b[i*pageSize]))
+	}
+	size := uint32(len(info)) * uint32(unsafe.Sizeof(info[0]))
+	err := windows.QueryWorkingSetEx(windows.Handle(m.hProcess), uintptr(unsafe.Pointer(&info[0])), size)
+	if err != nil {
+		return nil, os.NewSyscallError("QueryWorkingSetEx", err)
+	}
+	resident := make([]bool, len(info))
+	for i := range info {
+		resident[i] = info[i].VirtualAttributes.Valid()
+	}
+	return resident, nil
+}
+
+// unmapMemory unmaps the mapped memory and closes all descriptors associated with it.
+// All steps are executed even if some of them fail, all errors will be returned joined.
+func (m *Mapping) unmapMemory() error {
+	var errs []error
+	if err := syscall.UnmapViewOfFile(addressOf(m.alignedMemory)); err != nil {
+		errs = append(errs, os.NewSyscallError("UnmapViewOfFile", err))
+	}
+	if err := syscall.CloseHandle(m.hMapping); err != nil {
+		errs = append(errs, os.NewSyscallError("CloseHandle", err))
+	}
+	if err := m.closeFile(); err != nil {
+		errs = append(errs, err)
+	}
+	return joinErrors(errs)
+}
+
+// descriptor returns the duplicated descriptor of the mapped file or anonymousFd for the anonymous mapping.
+func (m *Mapping) descriptor() uintptr {
+	return uintptr(m.hFile)
+}
+
+// cloneDescriptor returns the descriptor of the mapped file to map it again by Clone.
+// The anonymous mapping is cloned by its section object.
+func (m *Mapping) cloneDescriptor(o *options) (uintptr, error) {
+	if m.hFile == syscall.InvalidHandle {
+		o.section = uintptr(m.hMapping)
+	}
+	return uintptr(m.hFile), nil
+}
+
+// truncateFile changes the size of the mapped file.
+// The file position is not changed.
+func (m *Mapping) truncateFile(size int64) error {
+	err := windows.SetFileInformationByHandle(
+		windows.Handle(m.hFile), windows.FileEndOfFileInfo,
+		(*byte)(unsafe.Pointer(&size)), uint32(unsafe.Sizeof(size)),
+	)
+	return os.NewSyscallError("SetFileInformationByHandle", err)
+}
+
+// fileZeroDataInformation is the FILE_ZERO_DATA_INFORMATION structure.
+type fileZeroDataInformation struct {
+	fileOffset      int64
+	beyondFinalZero int64
+}
+
+// punchHole marks the mapped file sparse and deallocates the given region of it.
+// The file system may refuse to deallocate the region of the file which is mapped by the views,
+// e.g. the region of this mapping, in such case the region is just zeroed.
+func (m *Mapping) punchHole(offset, length int64) error {
+	if m.hFile == syscall.InvalidHandle {
+		return ErrNotSupported
+	}
+	var n uint32
+	err := windows.DeviceIoControl(windows.Handle(m.hFile), windows.FSCTL_SET_SPARSE, nil, 0, nil, 0, &n, nil)
+	if err == windows.ERROR_INVALID_FUNCTION {
+		return ErrNotSupported
+	}
+	if err != nil {
+		return os.NewSyscallError("DeviceIoControl", err)
+	}
+	info := fileZeroDataInformation{fileOffset: offset, beyondFinalZero: offset + length}
+	err = windows.DeviceIoControl(
+		windows.Handle(m.hFile), windows.FSCTL_SET_ZERO_DATA,
+		(*byte)(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info)), nil, 0, &n, nil,
+	)
+	return os.NewSyscallError("DeviceIoControl", err)
+}
+
+// reopenFile replaces the duplicated handle of the mapped file by the new one which is opened
+// with the file flag of the given access pattern, so the section object tunes the read-ahead of the file.
+// The duplicated handle is kept if the file can not be opened again.
+func (m *Mapping) reopenFile(mode Mode, advice Advice) {
+	access := uint32(windows.GENERIC_READ)
+	if mode == ModeReadWrite {
+		access |= windows.GENERIC_WRITE
+	}
+	flags := uint32(windows.FILE_FLAG_SEQUENTIAL_SCAN)
+	if advice == AdviceRandom {
+		flags = windows.FILE_FLAG_RANDOM_ACCESS
+	}
+	share := uint32(windows.FILE_SHARE_READ | windows.FILE_SHARE_WRITE | windows.FILE_SHARE_DELETE)
+	h, err := reOpenFile(m.hFile, access, share, flags)
+	if err != nil {
+		return
+	}
+	_ = syscall.CloseHandle(m.hFile)
+	m.hFile = h
+}
+
+// closeFile closes the duplicated descriptor of the mapped file if any.
+func (m *Mapping) closeFile() error {
+	if m.hFile == syscall.InvalidHandle {
+		return nil
+	}
+	if err := syscall.CloseHandle(m.hFile); err != nil {
+		return os.NewSyscallError("CloseHandle", err)
+	}
+	return nil
+}
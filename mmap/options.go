@@ -0,0 +1,137 @@
+package mmap
+
+import "time"
+
+// Option is a mapping option.
+type Option func(o *options)
+
+// options is a set of the mapping options.
+type options struct {
+	// address specifies the preferred address of the mapped memory.
+	// It is aligned by the memory page size before the mapping.
+	address uintptr
+	// section specifies the handle of the existing section object to be mapped on Windows.
+	section uintptr
+	// name specifies the name of the new or existing section object to be mapped on Windows.
+	name string
+	// inheritable specifies whether the handle of the new section object is inherited by the child processes on Windows.
+	inheritable bool
+	// raiseLockLimit specifies whether the locked memory limit of the process may be raised.
+	raiseLockLimit bool
+	// closeFile specifies whether the mapped file is closed together with the mapping.
+	closeFile bool
+	// validate specifies the validator of the existing file which is mapped by OpenFile.
+	validate func(m *Mapping) error
+	// path specifies the path of the mapped file if it is known.
+	path string
+	// autoSync specifies the interval of the background synchronization of the mapped memory.
+	autoSync time.Duration
+	// dirtyTracking specifies whether the written mapped memory pages are tracked.
+	dirtyTracking bool
+	// writeProtect specifies whether the direct writes are tracked by the write-protected pages.
+	writeProtect bool
+	// noFinalizer specifies whether the mapping is not closed by the garbage collector.
+	noFinalizer bool
+	// leak specifies the handler which is called instead of closing by the garbage collector.
+	leak func(m *Mapping)
+	// syncParallelism specifies the number of the concurrent synchronizations of the mapped memory parts.
+	syncParallelism int
+	// access specifies the expected access pattern of the mapped memory, AdviceSequential or AdviceRandom.
+	access Advice
+}
+
+// newOptions returns a new set of the mapping options with the given options applied.
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// withPath specifies the path of the mapped file for the diagnostics.
+func withPath(path string) Option {
+	return func(o *options) {
+		o.path = path
+	}
+}
+
+// WithAddress specifies the preferred address of the mapped memory, see Mapping.Address.
+// The address must have the same offset from the multiple of Granularity as the mapping offset.
+// By default the address is just a hint which may be ignored by the operating system,
+// use FlagFixed to fail if the mapped memory can not be placed at the given address.
+func WithAddress(address uintptr) Option {
+	return func(o *options) {
+		o.address = address
+	}
+}
+
+// WithRaiseLockLimit allows Lock and LockRange to raise the locked memory limit of the process
+// and to retry if the locking fails because the limit is exceeded.
+// The limit is RLIMIT_MEMLOCK on Darwin, Linux, NetBSD and OpenBSD, the working set size on Windows is always raised.
+// The soft limit is raised up to the hard limit, the hard limit is raised only if the process has the privilege.
+func WithRaiseLockLimit() Option {
+	return func(o *options) {
+		o.raiseLockLimit = true
+	}
+}
+
+// WithCloseFile ties the lifetime of the file given to OpenFD to the mapping,
+// so closing of the mapping closes the file too.
+// It is useful on platforms which can not duplicate the file descriptor, such as js/wasm,
+// where the file must stay open until the mapping is closed.
+func WithCloseFile() Option {
+	return func(o *options) {
+		o.closeFile = true
+	}
+}
+
+// WithValidator specifies the validator which is called by OpenFile and its variants
+// when the file already exists, e.g. to verify its magic number, version or checksum
+// before the mapping is handed to the application.
+// If the validator fails, the mapping is closed and its error returns.
+func WithValidator(validate func(m *Mapping) error) Option {
+	return func(o *options) {
+		o.validate = validate
+	}
+}
+
+// WithoutFinalizer disables closing of the unreachable mapping by the garbage collector.
+// It is useful for programs which manage the lifetime of the mapping explicitly
+// or share the mapped memory with cgo, where the background synchronization and unmapping are surprising.
+// The mapped memory of the unreachable mapping which is not closed is leaked.
+func WithoutFinalizer() Option {
+	return func(o *options) {
+		o.noFinalizer = true
+		o.leak = nil
+	}
+}
+
+// WithLeakHandler replaces closing of the unreachable mapping by the garbage collector
+// with a call of the given handler, e.g. to report the leak of the mapping which was not closed.
+// The mapping is not closed by the garbage collector, so the mapped memory is leaked
+// unless the handler closes the mapping itself.
+func WithLeakHandler(leak func(m *Mapping)) Option {
+	return func(o *options) {
+		o.noFinalizer = false
+		o.leak = leak
+	}
+}
+
+// WithSequentialAccess tunes the read-ahead of the mapped file for the sequential access
+// in one place: the mapped memory is advised with AdviceSequential and the file with posix_fadvise on Linux,
+// the read-ahead of the file is enabled on Darwin and the file is opened again with FILE_FLAG_SEQUENTIAL_SCAN
+// to create the section object on Windows. The hints are best effort, their failures are ignored.
+func WithSequentialAccess() Option {
+	return func(o *options) {
+		o.access = AdviceSequential
+	}
+}
+
+// WithRandomAccess tunes the read-ahead of the mapped file for the random access like WithSequentialAccess,
+// the read-ahead of the file is disabled on Darwin and FILE_FLAG_RANDOM_ACCESS is used on Windows.
+func WithRandomAccess() Option {
+	return func(o *options) {
+		o.access = AdviceRandom
+	}
+}
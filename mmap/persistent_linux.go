@@ -0,0 +1,9 @@
+//go:build linux && !(mips || mipsle || mips64 || mips64le)
+
+package mmap
+
+import "golang.org/x/sys/unix"
+
+// mapPersistent is the set of mmap flags which maps the persistent memory of the file placed on NVDIMM
+// with the guarantee that the file metadata is synchronized before the memory may be written.
+const mapPersistent = unix.MAP_SHARED_VALIDATE | unix.MAP_SYNC
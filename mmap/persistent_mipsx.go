@@ -0,0 +1,6 @@
+//go:build linux && (mips || mipsle || mips64 || mips64le)
+
+package mmap
+
+// mapPersistent is zero since MAP_SYNC is not defined on MIPS.
+const mapPersistent = 0
@@ -0,0 +1,55 @@
+package mmap
+
+// CPU features which are used to flush the CPU caches, see cpuid.
+const (
+	cpuidCLFLUSHOPT = 1 << 23
+	cpuidCLWB       = 1 << 24
+)
+
+// cacheLineSize specifies the size of the CPU cache line which is flushed by a single instruction.
+var cacheLineSize uintptr
+
+// flushLines specifies the function which flushes the given number of the CPU cache lines
+// starting from the given address with the best instruction available.
+var flushLines func(address, count uintptr)
+
+func init() {
+	_, ebx, _, _ := cpuid(1, 0)
+	cacheLineSize = uintptr((ebx>>8)&0xff) * 8
+	if cacheLineSize == 0 {
+		cacheLineSize = 64
+	}
+	flushLines = flushCLFLUSH
+	if maxLeaf, _, _, _ := cpuid(0, 0); maxLeaf >= 7 {
+		_, ebx, _, _ := cpuid(7, 0)
+		switch {
+		case ebx&cpuidCLWB != 0:
+			flushLines = flushCLWB
+		case ebx&cpuidCLFLUSHOPT != 0:
+			flushLines = flushCLFLUSHOPT
+		}
+	}
+}
+
+// flushCache writes back the CPU cache lines which contain the given memory to the persistent memory.
+func flushCache(b []byte) bool {
+	if len(b) == 0 {
+		return true
+	}
+	start := addressOf(b) &^ (cacheLineSize - 1)
+	end := addressOf(b) + uintptr(len(b))
+	flushLines(start, (end-start+cacheLineSize-1)/cacheLineSize)
+	return true
+}
+
+// cpuid executes the CPUID instruction with the given leaf and subleaf.
+func cpuid(leaf, subleaf uint32) (eax, ebx, ecx, edx uint32)
+
+// flushCLWB writes back the given number of the CPU cache lines with CLWB keeping them valid.
+func flushCLWB(address, count uintptr)
+
+// flushCLFLUSHOPT flushes the given number of the CPU cache lines with CLFLUSHOPT.
+func flushCLFLUSHOPT(address, count uintptr)
+
+// flushCLFLUSH flushes the given number of the CPU cache lines with CLFLUSH.
+func flushCLFLUSH(address, count uintptr)
@@ -0,0 +1,51 @@
+#include "textflag.h"
+
+// func cpuid(leaf, subleaf uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL leaf+0(FP), AX
+	MOVL subleaf+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+// func flushCLWB(address, count uintptr)
+TEXT ·flushCLWB(SB), NOSPLIT, $0-16
+	MOVQ address+0(FP), AX
+	MOVQ count+8(FP), CX
+	MOVQ ·cacheLineSize(SB), DX
+loop:
+	CLWB (AX)
+	ADDQ DX, AX
+	DECQ CX
+	JNZ loop
+	SFENCE
+	RET
+
+// func flushCLFLUSHOPT(address, count uintptr)
+TEXT ·flushCLFLUSHOPT(SB), NOSPLIT, $0-16
+	MOVQ address+0(FP), AX
+	MOVQ count+8(FP), CX
+	MOVQ ·cacheLineSize(SB), DX
+loop:
+	CLFLUSHOPT (AX)
+	ADDQ DX, AX
+	DECQ CX
+	JNZ loop
+	SFENCE
+	RET
+
+// func flushCLFLUSH(address, count uintptr)
+TEXT ·flushCLFLUSH(SB), NOSPLIT, $0-16
+	MOVQ address+0(FP), AX
+	MOVQ count+8(FP), CX
+	MOVQ ·cacheLineSize(SB), DX
+loop:
+	CLFLUSH (AX)
+	ADDQ DX, AX
+	DECQ CX
+	JNZ loop
+	SFENCE
+	RET
@@ -0,0 +1,8 @@
+//go:build !amd64
+
+package mmap
+
+// flushCache returns false since the CPU caches can not be flushed in the user space on this architecture.
+func flushCache(b []byte) bool {
+	return false
+}
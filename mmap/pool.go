@@ -0,0 +1,137 @@
+package mmap
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Pool deduplicates the mappings of the same file region, so many goroutines which map the same file
+// share one view of it and the mapping is closed only when the last user releases it.
+// The pool is safe for concurrent use.
+type Pool struct {
+	// mu protects the entries.
+	mu sync.Mutex
+	// flags specifies the flags of the mappings opened by this pool.
+	flags Flag
+	// opts specifies the options of the mappings opened by this pool.
+	opts []Option
+	// entries specifies the shared mappings by their keys.
+	entries map[poolKey]*poolEntry
+}
+
+// poolKey identifies the mapped file region in the pool.
+type poolKey struct {
+	path   string
+	offset int64
+	length uintptr
+	mode   Mode
+}
+
+// poolEntry is the shared mapping with its reference counter.
+type poolEntry struct {
+	mapping *Mapping
+	refs    int
+	// info specifies the information of the mapped file at the moment of opening.
+	info os.FileInfo
+}
+
+// PooledMapping is a reference to the mapping shared by the pool.
+// The mapping must not be closed directly, Close of this reference releases it instead.
+type PooledMapping struct {
+	// pool specifies the pool which this reference belongs to.
+	pool *Pool
+	// key specifies the key of the shared mapping in the pool.
+	key poolKey
+	// entry specifies the shared mapping or nil if this reference is released.
+	entry *poolEntry
+}
+
+// NewPool returns a new pool which opens the mappings with the given flags and options.
+func NewPool(flags Flag, opts ...Option) *Pool {
+	return &Pool{flags: flags, opts: opts, entries: make(map[poolKey]*poolEntry)}
+}
+
+// Open returns the reference to the mapping of the region of the existing file
+// starting from the given offset and ends after the given length in the given mode.
+// The mapping is opened like by OpenFileRegion on the first request of the region
+// and shared by the subsequent ones with the same path, offset, length and mode.
+// If the file at the path is replaced, the subsequent requests get the new mapping of the new file
+// and the mapping of the replaced one stays valid until its last reference is released.
+// ModeWriteCopy is not allowed because the private copy can not be shared, the ErrBadMode returns in such case.
+// The ErrFileNotExist returns if the file does not exist.
+// The mapping is opened under the pool lock, so the concurrent requests of the same region wait for it.
+func (p *Pool) Open(name string, offset int64, length uintptr, mode Mode) (*PooledMapping, error) {
+	if mode != ModeReadOnly && mode != ModeReadWrite {
+		return nil, ErrBadMode
+	}
+	if offset < 0 {
+		return nil, ErrBadOffset
+	}
+	if uint64(length) > uint64(math.MaxInt64-offset) {
+		return nil, ErrBadLength
+	}
+	path, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+	key := poolKey{path: path, offset: offset, length: length, mode: mode}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, ErrFileNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	e, ok := p.entries[key]
+	if !ok || !os.SameFile(e.info, info) {
+		m, err := openFile(path, 0, 0, offset, length, mode, p.flags, nil, extendFile(offset, length), p.opts)
+		if err != nil {
+			return nil, err
+		}
+		e = &poolEntry{mapping: m, info: info}
+		p.entries[key] = e
+	}
+	e.refs++
+	return &PooledMapping{pool: p, key: key, entry: e}, nil
+}
+
+// Len returns the number of the mappings which are shared by this pool at the moment.
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.entries)
+}
+
+// Mapping returns the shared mapping or nil if this reference is released.
+func (r *PooledMapping) Mapping() *Mapping {
+	if r.entry == nil {
+		return nil
+	}
+	return r.entry.mapping
+}
+
+// Close releases this reference and closes the shared mapping if it is the last one.
+// The ErrClosed returns if this reference is already released.
+// Close implements the io.Closer interface.
+func (r *PooledMapping) Close() error {
+	if r.entry == nil {
+		return ErrClosed
+	}
+	p := r.pool
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e := r.entry
+	r.entry = nil
+	e.refs--
+	if e.refs > 0 {
+		return nil
+	}
+	if p.entries[r.key] == e {
+		delete(p.entries, r.key)
+	}
+	return e.mapping.Close()
+}
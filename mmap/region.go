@@ -0,0 +1,177 @@
+package mmap
+
+import (
+	"io"
+	"math"
+
+	"github.com/alexeymaximov/go-bio/internal/race"
+	"github.com/alexeymaximov/go-bio/segment"
+)
+
+// Region is a bounds-checked view of the part of the mapped memory.
+// It can not access the mapped memory outside of its window,
+// so it may be handed to the code which must not access the whole mapping.
+// The region must not be used after the mapping is closed or mapped again by Truncate.
+type Region struct {
+	// mapping specifies the mapping which this region belongs to.
+	mapping *Mapping
+	// offset specifies the offset of this region from start of the mapped memory.
+	offset int64
+	// length specifies the length of this region.
+	length uintptr
+	// segment specifies the lazily initialized data segment on top of this region.
+	segment *segment.Segment
+}
+
+// Region returns a new view of the mapped memory starting from the given offset and ends after the given length.
+func (m *Mapping) Region(offset int64, length uintptr) (*Region, error) {
+	if m.memory == nil {
+		return nil, ErrClosed
+	}
+	if length > uintptr(MaxInt) {
+		return nil, outOfBounds()
+	}
+	if err := m.access(offset, int(length)); err != nil {
+		return nil, err
+	}
+	return &Region{mapping: m, offset: offset, length: length}, nil
+}
+
+// Split splits the mapped memory into the given number of non-overlapping regions of nearly equal length,
+// so parallel workers may process and synchronize their own parts independently.
+// The ErrBadLength returns if the number is less than 1 or greater than the length of the mapping.
+func (m *Mapping) Split(n int) ([]*Region, error) {
+	if m.memory == nil {
+		return nil, ErrClosed
+	}
+	if n < 1 || n > len(m.memory) {
+		return nil, ErrBadLength
+	}
+	regions := make([]*Region, n)
+	length, rem := len(m.memory)/n, len(m.memory)%n
+	offset := 0
+	for i := range regions {
+		k := length
+		if i < rem {
+			k++
+		}
+		regions[i] = &Region{mapping: m, offset: int64(offset), length: uintptr(k)}
+		offset += k
+	}
+	return regions, nil
+}
+
+// Offset returns the offset of this region from start of the mapped memory.
+func (r *Region) Offset() int64 {
+	return r.offset
+}
+
+// Length returns the length of this region.
+func (r *Region) Length() uintptr {
+	return r.length
+}
+
+// Writable returns true if the memory of this region may be written.
+func (r *Region) Writable() bool {
+	return r.mapping.Writable()
+}
+
+// Segment returns the data segment on top of this region.
+// Offsets of the segment are relative to start of this region.
+// The segment of the closed mapping is empty.
+func (r *Region) Segment() *segment.Segment {
+	if r.mapping.memory == nil {
+		return segment.New(0, nil)
+	}
+	if r.segment == nil {
+		r.segment = segment.New(0, r.mapping.memory[r.offset:r.offset+int64(r.length)])
+	}
+	return r.segment
+}
+
+// access checks given offset and length to match the bounds of this region
+// and returns ErrOutOfBounds error at the access violation.
+func (r *Region) access(offset int64, length int) error {
+	if offset < 0 || offset > math.MaxInt64-int64(length) || offset+int64(length) > int64(r.length) {
+		return outOfBounds()
+	}
+	return nil
+}
+
+// ReadAt reads len(buf) bytes at the given offset from start of this region.
+// If the given offset is out of the region bounds or there are not enough bytes to read
+// the ErrOutOfBounds error will be returned. Otherwise len(buf) will be returned with no errors.
+// ReadAt implements the io.ReaderAt interface.
+func (r *Region) ReadAt(buf []byte, offset int64) (int, error) {
+	if err := r.access(offset, len(buf)); err != nil {
+		return 0, err
+	}
+	return r.mapping.ReadAt(buf, r.offset+offset)
+}
+
+// WriteAt writes len(buf) bytes at the given offset from start of this region.
+// If the given offset is out of the region bounds or there are not enough space to write all given bytes
+// the ErrOutOfBounds error will be returned. Otherwise len(buf) will be returned with no errors.
+// WriteAt implements the io.WriterAt interface.
+func (r *Region) WriteAt(buf []byte, offset int64) (int, error) {
+	if err := r.access(offset, len(buf)); err != nil {
+		return 0, err
+	}
+	return r.mapping.WriteAt(buf, r.offset+offset)
+}
+
+// WriteTo writes the whole memory of this region into the given writer directly
+// without an intermediate buffer and returns the number of bytes written.
+// WriteTo implements the io.WriterTo interface.
+func (r *Region) WriteTo(w io.Writer) (int64, error) {
+	m := r.mapping
+	if m.memory == nil {
+		return 0, ErrClosed
+	}
+	if err := m.populate(r.offset, r.length); err != nil {
+		return 0, err
+	}
+	race.ReadRange(m.address+uintptr(r.offset), r.length)
+	n, err := w.Write(m.memory[r.offset : r.offset+int64(r.length)])
+	return int64(n), err
+}
+
+// ReadFrom reads data from the given reader directly into the memory of this region
+// until io.EOF or the region is full and returns the number of bytes read.
+// ReadFrom implements the io.ReaderFrom interface.
+func (r *Region) ReadFrom(rd io.Reader) (int64, error) {
+	m := r.mapping
+	if m.memory == nil {
+		return 0, ErrClosed
+	}
+	if !m.writable {
+		return 0, ErrReadOnly
+	}
+	if err := m.populate(r.offset, r.length); err != nil {
+		return 0, err
+	}
+	race.WriteRange(m.address+uintptr(r.offset), r.length)
+	n, err := readFull(rd, m.memory[r.offset:r.offset+int64(r.length)])
+	m.markDirty(r.offset, uintptr(n))
+	return n, err
+}
+
+// Sync synchronizes the memory pages which contain a part of this region with the underlying file.
+// The pages on the bounds of the region may be shared with the neighbour regions.
+func (r *Region) Sync() error {
+	m := r.mapping
+	if m.memory == nil {
+		return ErrClosed
+	}
+	if !m.writable {
+		return ErrReadOnly
+	}
+	b, err := m.pages(r.offset, r.length)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	return m.sync(b)
+}
@@ -0,0 +1,95 @@
+package mmap
+
+import (
+	"fmt"
+	"io"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LiveMapping is a description of the live mapping in the registry.
+type LiveMapping struct {
+	// Address specifies the pointer to the mapped memory.
+	Address uintptr
+	// Length specifies the mapped memory length in bytes.
+	Length uintptr
+	// Path specifies the path of the mapped file if it is known.
+	Path string
+	// Created specifies the time when the mapping was opened.
+	Created time.Time
+	// Stack specifies the stack trace of the goroutine which opened the mapping.
+	Stack []byte
+}
+
+// registry is the registry of the live mappings.
+var registry struct {
+	sync.Mutex
+	// live specifies the live mappings by their addresses or nil if the registry is disabled.
+	live map[uintptr]*LiveMapping
+}
+
+// EnableRegistry enables or disables the registry of the live mappings.
+// The mappings which are opened while the registry is enabled are recorded with the stack traces
+// until they are closed, so the long-running services may find the leaked mappings by Live or DumpLive.
+// Recording of the stack traces is expensive, so the registry is disabled by default.
+// Disabling of the registry forgets all recorded mappings.
+func EnableRegistry(enabled bool) {
+	registry.Lock()
+	defer registry.Unlock()
+	if !enabled {
+		registry.live = nil
+	} else if registry.live == nil {
+		registry.live = make(map[uintptr]*LiveMapping)
+	}
+}
+
+// register records the given mapping in the registry if it is enabled.
+func register(m *Mapping) {
+	registry.Lock()
+	defer registry.Unlock()
+	if registry.live == nil {
+		return
+	}
+	registry.live[m.address] = &LiveMapping{
+		Address: m.address,
+		Length:  m.Length(),
+		Path:    m.path,
+		Created: time.Now(),
+		Stack:   debug.Stack(),
+	}
+}
+
+// unregister forgets the mapping of the given address.
+func unregister(address uintptr) {
+	registry.Lock()
+	defer registry.Unlock()
+	delete(registry.live, address)
+}
+
+// Live returns the mappings which are recorded in the registry and are not closed yet
+// in order of their creation. It returns nothing if the registry is disabled.
+func Live() []LiveMapping {
+	registry.Lock()
+	live := make([]LiveMapping, 0, len(registry.live))
+	for _, l := range registry.live {
+		live = append(live, *l)
+	}
+	registry.Unlock()
+	sort.Slice(live, func(i, j int) bool {
+		return live[i].Created.Before(live[j].Created)
+	})
+	return live
+}
+
+// DumpLive writes the descriptions of the live mappings with their stack traces into the given writer.
+func DumpLive(w io.Writer) error {
+	for _, l := range Live() {
+		if _, err := fmt.Fprintf(w, "mapping %#x+%d %q opened at %s:\n%s\n",
+			l.Address, l.Length, l.Path, l.Created.Format(time.RFC3339Nano), l.Stack); err != nil {
+			return err
+		}
+	}
+	return nil
+}
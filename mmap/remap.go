@@ -0,0 +1,185 @@
+package mmap
+
+import (
+	"math"
+	"os"
+	"runtime"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/alexeymaximov/go-bio/internal/race"
+)
+
+// Truncate changes the length of the mapped memory to the given size
+// and resizes the underlying file to the mapping offset plus the given size accordingly.
+// The file is mapped again, so the mapped memory, the address and the segment
+// which are obtained before the call must not be used after it, Segment returns a new one.
+// The data of the anonymous mapping is retained up to the new length.
+// The ErrReadOnly returns for the read-only and copy-on-write file mappings,
+// the ErrNotSupported returns for the named and the inherited section mappings on Windows.
+// If mapping of the resized file fails, this mapping remains valid.
+func (m *Mapping) Truncate(size uintptr) error {
+	return m.resize(size)
+}
+
+// Clone opens and returns a new independent mapping of the same file region or section in the given mode,
+// e.g. the read-only one to hand to readers while this one is written.
+// The mapped file descriptor is duplicated again, so closing of one mapping does not affect the other.
+// The ErrNotSupported returns for the anonymous mapping except Windows where it is backed by the section
+// and for the emulated mapping which memory can not be shared.
+func (m *Mapping) Clone(mode Mode) (*Mapping, error) {
+	if m.memory == nil {
+		return nil, ErrClosed
+	}
+	o := m.options
+	fd, err := m.cloneDescriptor(&o)
+	if err != nil {
+		return nil, err
+	}
+	return Open(fd, m.fileOffset, m.Length(), mode, m.flags&^FlagFixed, func(no *options) {
+		*no = o
+	})
+}
+
+// Freeze opens and returns a new read-only mapping which is a point-in-time snapshot of this mapping.
+// It is the copy-on-write mapping of the same file region or section which pages are privately copied,
+// so it is immune to subsequent writes through this mapping or to the file.
+// The anonymous and the emulated mappings are copied to the new anonymous mapping.
+// FlagWipeOnFork is kept by the anonymous copy only. The snapshot does not track the dirty pages
+// and is not synchronized automatically regardless of the options of this mapping.
+// Freeze is not cheap: every page of this mapping is read and copied before it returns,
+// so it takes the time and the memory proportional to the length of this mapping
+// and faults in the pages which are not resident, but there are no copies of the data in the Go heap.
+// Writes which are concurrent with the freezing may be partially visible in the snapshot.
+func (m *Mapping) Freeze() (*Mapping, error) {
+	if m.memory == nil {
+		return nil, ErrClosed
+	}
+	o := m.options
+	// The snapshot is read-only, so the writes to it are neither tracked nor synchronized.
+	o.dirtyTracking, o.writeProtect, o.autoSync = false, false, 0
+	flags := m.flags &^ (FlagFixed | FlagPersistentMemory)
+	fd, err := m.cloneDescriptor(&o)
+	var n *Mapping
+	switch err {
+	case nil:
+		// The wiping on fork is not applicable to the private file mappings, MADV_WIPEONFORK fails on them.
+		n, err = Open(fd, m.fileOffset, m.Length(), ModeWriteCopy, flags&^FlagWipeOnFork, func(no *options) {
+			*no = o
+		})
+	case ErrNotSupported:
+		fd = anonymousFd
+		n, err = OpenAnonymous(m.Length(), ModeReadWrite, flags&^FlagHugePages, withPath(o.path))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if fd == anonymousFd || m.mode == ModeWriteCopy {
+		// The data of this mapping differs from the file, so it is copied entirely.
+		if err := m.populate(0, m.Length()); err != nil {
+			_ = n.Close()
+			return nil, err
+		}
+		race.ReadRange(m.address, m.Length())
+		race.WriteRange(n.address, n.Length())
+		copy(n.memory, m.memory)
+	} else {
+		// Writing to the page makes the private copy of it.
+		// The atomic addition of zero is the write which can not be eliminated by the compiler.
+		pageSize := os.Getpagesize()
+		for i := 0; i < len(n.alignedMemory); i += pageSize {
+			atomic.AddUint32((*uint32)(unsafe.Pointer(&n.alignedMemory[i])), 0)
+		}
+	}
+	if err := n.protectMemory(n.alignedMemory, false); err != nil {
+		_ = n.Close()
+		return nil, err
+	}
+	n.writable = false
+	return n, nil
+}
+
+// resize changes the length of the mapped memory and the size of the underlying file accordingly.
+// The anonymous mapping is mapped again with its data copied.
+func (m *Mapping) resize(length uintptr) error {
+	if m.memory == nil {
+		return ErrClosed
+	}
+	if length == 0 || length > uintptr(MaxInt) {
+		return ErrBadLength
+	}
+	if !m.writable {
+		return ErrReadOnly
+	}
+	if m.options.section != 0 || m.options.name != "" {
+		return ErrNotSupported
+	}
+	if m.descriptor() == anonymousFd {
+		return m.remap(length)
+	}
+	if m.mode != ModeReadWrite {
+		return ErrReadOnly
+	}
+	if uint64(length) > uint64(math.MaxInt64-m.fileOffset) {
+		return ErrBadLength
+	}
+	size := m.fileOffset + int64(length)
+	if length > uintptr(len(m.memory)) {
+		if err := m.truncateFile(size); err != nil {
+			return err
+		}
+		return m.remap(length)
+	}
+	if err := m.remap(length); err != nil {
+		return err
+	}
+	return m.truncateFile(size)
+}
+
+// remap maps the same file again with the given length and replaces this mapping by the new one.
+// The data of the private mapping is copied into the new mapped memory,
+// the lock of the mapped memory is retained.
+func (m *Mapping) remap(length uintptr) error {
+	fd := m.descriptor()
+	o := m.options
+	o.autoSync = 0
+	n, err := Open(fd, m.fileOffset, length, m.mode, m.flags&^FlagFixed, func(no *options) {
+		*no = o
+	})
+	if err != nil {
+		return err
+	}
+	if fd == anonymousFd || m.mode == ModeWriteCopy {
+		if err := m.populate(0, m.Length()); err != nil {
+			_ = n.Close()
+			return err
+		}
+		k := copy(n.memory, m.memory)
+		race.ReadRange(m.address, uintptr(k))
+		race.WriteRange(n.address, uintptr(k))
+	}
+	if m.locked {
+		if err := n.Lock(); err != nil {
+			_ = n.Close()
+			return err
+		}
+	}
+	file, syncer := m.file, m.autoSync
+	if syncer != nil {
+		syncer.mu.Lock()
+		defer syncer.mu.Unlock()
+	}
+	old := &Mapping{}
+	*old = *m
+	watcher := m.watcher
+	if watcher != nil {
+		watcher.end.Store(n.fileOffset + int64(len(n.memory)))
+	}
+	old.file, old.autoSync, old.watcher = nil, nil, nil
+	*m = *n
+	m.file, m.autoSync, m.watcher = file, syncer, watcher
+	m.options.autoSync = old.options.autoSync
+	*n = Mapping{}
+	runtime.SetFinalizer(n, nil)
+	return old.Close()
+}
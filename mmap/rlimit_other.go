@@ -0,0 +1,8 @@
+//go:build aix || dragonfly || freebsd || solaris
+
+package mmap
+
+// raiseLockLimit returns false since RLIMIT_MEMLOCK is not raised on this platform.
+func raiseLockLimit(length uintptr) bool {
+	return false
+}
@@ -0,0 +1,28 @@
+//go:build darwin || linux || netbsd || openbsd
+
+package mmap
+
+import "golang.org/x/sys/unix"
+
+// raiseLockLimit raises RLIMIT_MEMLOCK of the process by the given length and reports whether it is raised.
+// The hard limit is raised only if the process has the privilege, otherwise the soft limit is raised up to it.
+func raiseLockLimit(length uintptr) bool {
+	var rlim unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_MEMLOCK, &rlim); err != nil || rlim.Cur == unix.RLIM_INFINITY {
+		return false
+	}
+	limit := rlim.Cur + uint64(length)
+	if limit < rlim.Cur || limit > unix.RLIM_INFINITY {
+		limit = unix.RLIM_INFINITY
+	}
+	if limit > rlim.Max {
+		if unix.Setrlimit(unix.RLIMIT_MEMLOCK, &unix.Rlimit{Cur: limit, Max: limit}) == nil {
+			return true
+		}
+		limit = rlim.Max
+	}
+	if limit == rlim.Cur {
+		return false
+	}
+	return unix.Setrlimit(unix.RLIMIT_MEMLOCK, &unix.Rlimit{Cur: limit, Max: rlim.Max}) == nil
+}
@@ -0,0 +1,46 @@
+package mmap
+
+import "syscall"
+
+// OpenSection opens and returns a new mapping of the new section object backed by the system paging file.
+// The mapped memory is initialized with zeros.
+// The handle of the section object returned by Section is inherited by the child processes,
+// so the parent process may share the mapped memory with them passing the handle,
+// see syscall.SysProcAttr.AdditionalInheritedHandles and OpenSectionHandle.
+func OpenSection(length uintptr, mode Mode, flags Flag, opts ...Option) (*Mapping, error) {
+	if length == 0 {
+		return nil, ErrBadLength
+	}
+	opts = append(opts[:len(opts):len(opts)], func(o *options) {
+		o.inheritable = true
+	})
+	return Open(anonymousFd, 0, length, mode, flags, opts...)
+}
+
+// OpenSectionHandle opens and returns a new mapping of the existing section object
+// given by its handle, e.g. inherited from the parent process.
+// The given handle will be duplicated. It means that
+// if the parent handle will be closed the mapping will still be valid.
+// The given offset and length must be within the section object.
+func OpenSectionHandle(section uintptr, offset int64, length uintptr, mode Mode, flags Flag, opts ...Option) (*Mapping, error) {
+	if length == 0 {
+		return nil, ErrBadLength
+	}
+	opts = append(opts[:len(opts):len(opts)], func(o *options) {
+		o.section = section
+	})
+	return Open(anonymousFd, offset, length, mode, flags, opts...)
+}
+
+// Section returns the handle of the section object provided by the operating system.
+// The handle is valid until the mapping is closed.
+func (m *Mapping) Section() uintptr {
+	return uintptr(m.hMapping)
+}
+
+// Handle returns the handle of the mapped file which is duplicated by the mapping,
+// so the caller may use it for DuplicateHandle or other calls against the same file.
+// It is the same as Fd and is valid until the mapping is closed.
+func (m *Mapping) Handle() syscall.Handle {
+	return syscall.Handle(m.Fd())
+}
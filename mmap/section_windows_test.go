@@ -0,0 +1,32 @@
+package mmap
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestSection tests the mapping of the section object backed by the system paging file.
+// CASE: The data which is read through the mapping of the section handle MUST be exactly the same
+// as the previously written through the mapping of the section.
+func TestSection(t *testing.T) {
+	m, err := OpenSection(uintptr(testDataLength), ModeReadWrite, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, m)
+	if _, err := m.WriteAt(testData, 0); err != nil {
+		t.Fatal(err)
+	}
+	shared, err := OpenSectionHandle(m.Section(), 0, uintptr(testDataLength), ModeReadOnly, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeTestEntity(t, shared)
+	buf := make([]byte, testDataLength)
+	if _, err := shared.ReadAt(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(buf, testData) != 0 {
+		t.Fatalf("data must be %q, %v found", testData, buf)
+	}
+}
@@ -0,0 +1,42 @@
+package mmap
+
+import (
+	"net"
+
+	"github.com/alexeymaximov/go-bio/internal/race"
+)
+
+// sendChunk is the maximal number of bytes which are sent by a single system call.
+const sendChunk = 1 << 30
+
+// SendTo sends the part of the mapped memory starting from the given offset and ends after the given length
+// into the given connection and returns the number of the sent bytes, so the file servers avoid copying the data twice.
+// The shared file mapping is sent by sendfile from the descriptor of the mapped file on Linux and Darwin
+// and by TransmitFile on Windows if the connection supports it, e.g. *net.TCPConn.
+// Otherwise, e.g. for the anonymous or the copy-on-write mappings which data differs from the file,
+// the mapped memory is written into the connection.
+func (m *Mapping) SendTo(conn net.Conn, offset int64, length uintptr) (int64, error) {
+	if m.memory == nil {
+		return 0, ErrClosed
+	}
+	if length > uintptr(MaxInt) {
+		return 0, outOfBounds()
+	}
+	if err := m.access(offset, int(length)); err != nil {
+		return 0, err
+	}
+	if length == 0 {
+		return 0, nil
+	}
+	if m.mode != ModeWriteCopy && m.descriptor() != anonymousFd {
+		if n, ok, err := m.sendFile(conn, m.fileOffset+offset, int64(length)); ok {
+			return n, err
+		}
+	}
+	if err := m.populate(offset, length); err != nil {
+		return 0, err
+	}
+	race.ReadRange(m.address+uintptr(offset), length)
+	n, err := conn.Write(m.memory[offset : offset+int64(length)])
+	return int64(n), err
+}
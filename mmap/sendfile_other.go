@@ -0,0 +1,10 @@
+//go:build !darwin && !linux && !windows
+
+package mmap
+
+import "net"
+
+// sendFile returns false since there is no portable sendfile, so the mapped memory is written into the connection directly.
+func (m *Mapping) sendFile(conn net.Conn, offset, length int64) (int64, bool, error) {
+	return 0, false, nil
+}
@@ -0,0 +1,70 @@
+//go:build darwin || linux
+
+package mmap
+
+import (
+	"io"
+	"net"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// sendFile sends the given region of the mapped file into the given connection by sendfile.
+// It returns false if the connection does not support it and nothing is sent.
+func (m *Mapping) sendFile(conn net.Conn, offset, length int64) (int64, bool, error) {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return 0, false, nil
+	}
+	rc, err := sc.SyscallConn()
+	if err != nil {
+		return 0, false, nil
+	}
+	var sent int64
+	var sendErr error
+	err = rc.Write(func(s uintptr) bool {
+		for sent < length {
+			k := length - sent
+			if k > sendChunk {
+				k = sendChunk
+			}
+			// The offset is advanced by the sent bytes on Linux only, so it is passed by the copy.
+			off := offset + sent
+			n, err := unix.Sendfile(int(s), m.fd, &off, int(k))
+			if n > 0 {
+				sent += int64(n)
+			}
+			switch {
+			case err == unix.EAGAIN:
+				return false
+			case err == unix.EINTR:
+			case err != nil:
+				sendErr = err
+				return true
+			case n == 0:
+				// The file is truncated below the end of the mapped memory.
+				sendErr = io.ErrUnexpectedEOF
+				return true
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return sent, true, err
+	}
+	if sendErr != nil {
+		if sent == 0 {
+			switch sendErr {
+			case unix.EINVAL, unix.ENOSYS, unix.EOPNOTSUPP, unix.ENOTSOCK:
+				return 0, false, nil
+			}
+		}
+		if sendErr != io.ErrUnexpectedEOF {
+			sendErr = os.NewSyscallError("sendfile", sendErr)
+		}
+		return sent, true, sendErr
+	}
+	return sent, true, nil
+}
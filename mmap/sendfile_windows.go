@@ -0,0 +1,35 @@
+package mmap
+
+import (
+	"io"
+	"net"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// sendFile sends the given region of the mapped file into the given connection by TransmitFile
+// which is used by the connection to read from the file, e.g. by *net.TCPConn.
+// The file is opened again to have its own position. It returns false if it is not possible and nothing is sent.
+func (m *Mapping) sendFile(conn net.Conn, offset, length int64) (int64, bool, error) {
+	rf, ok := conn.(io.ReaderFrom)
+	if !ok {
+		return 0, false, nil
+	}
+	share := uint32(windows.FILE_SHARE_READ | windows.FILE_SHARE_WRITE | windows.FILE_SHARE_DELETE)
+	h, err := reOpenFile(m.hFile, windows.GENERIC_READ, share, windows.FILE_FLAG_SEQUENTIAL_SCAN)
+	if err != nil {
+		return 0, false, nil
+	}
+	f := os.NewFile(uintptr(h), m.path)
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, false, nil
+	}
+	n, err := rf.ReadFrom(&io.LimitedReader{R: f, N: length})
+	if err == nil && n < length {
+		// The file is truncated below the end of the mapped memory.
+		err = io.ErrUnexpectedEOF
+	}
+	return n, true, err
+}
@@ -0,0 +1,13 @@
+//go:build !darwin && !linux && !windows
+
+package mmap
+
+// OpenShared returns ErrNotSupported since the shared memory objects are supported on Darwin, Linux and Windows only.
+func OpenShared(name string, size uintptr, mode Mode) (*Mapping, error) {
+	return nil, ErrNotSupported
+}
+
+// RemoveShared returns ErrNotSupported since the shared memory objects are supported on Darwin, Linux and Windows only.
+func RemoveShared(name string) error {
+	return ErrNotSupported
+}
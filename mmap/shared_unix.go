@@ -0,0 +1,69 @@
+//go:build darwin || linux
+
+package mmap
+
+import (
+	"os"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// OpenShared opens and returns a new mapping of the shared memory object with the given name
+// which may be attached by the unrelated processes, see shm_open for details.
+// The shared memory object is created and initialized with zeros if it does not exist
+// and the mode is not ModeReadOnly. It is extended to the given size if it is smaller.
+// The shared memory object persists until it is removed by RemoveShared.
+func OpenShared(name string, size uintptr, mode Mode) (*Mapping, error) {
+	name, err := sharedName(name)
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 || size > uintptr(MaxInt) {
+		return nil, ErrBadLength
+	}
+	if mode < ModeReadOnly || mode > ModeWriteCopy {
+		return nil, ErrBadMode
+	}
+	flag := unix.O_RDWR | unix.O_CREAT
+	if mode == ModeReadOnly {
+		flag = unix.O_RDONLY
+	}
+	fd, err := shmOpen(name, flag, 0600)
+	if err != nil {
+		return nil, os.NewSyscallError("shm_open", err)
+	}
+	defer unix.Close(fd)
+	var stat unix.Stat_t
+	if err := unix.Fstat(fd, &stat); err != nil {
+		return nil, os.NewSyscallError("fstat", err)
+	}
+	if uint64(stat.Size) < uint64(size) {
+		if mode == ModeReadOnly {
+			return nil, ErrBadLength
+		}
+		if err := unix.Ftruncate(fd, int64(size)); err != nil {
+			return nil, os.NewSyscallError("ftruncate", err)
+		}
+	}
+	return Open(uintptr(fd), 0, size, mode, 0)
+}
+
+// RemoveShared removes the shared memory object with the given name.
+// Existing mappings of it are still valid until they are closed.
+func RemoveShared(name string) error {
+	name, err := sharedName(name)
+	if err != nil {
+		return err
+	}
+	return os.NewSyscallError("shm_unlink", shmUnlink(name))
+}
+
+// sharedName returns the given name of the shared memory object without the leading slash.
+func sharedName(name string) (string, error) {
+	name = strings.TrimPrefix(name, "/")
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\x00") {
+		return "", ErrBadName
+	}
+	return name, nil
+}
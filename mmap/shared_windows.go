@@ -0,0 +1,27 @@
+package mmap
+
+// OpenShared opens and returns a new mapping of the named section object backed by the system paging file
+// which may be attached by the unrelated processes.
+// The section object is created and initialized with zeros if it does not exist,
+// the size of the existing section object is not changed.
+// The section object persists until the last mapping of it is closed.
+func OpenShared(name string, size uintptr, mode Mode) (*Mapping, error) {
+	if name == "" {
+		return nil, ErrBadName
+	}
+	if size == 0 {
+		return nil, ErrBadLength
+	}
+	return Open(anonymousFd, 0, size, mode, 0, func(o *options) {
+		o.name = name
+	})
+}
+
+// RemoveShared does nothing since the section object is removed automatically
+// when the last mapping of it is closed.
+func RemoveShared(name string) error {
+	if name == "" {
+		return ErrBadName
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package mmap
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Snapshot writes the consistent point-in-time copy of the mapped memory into the given writer
+// and returns the number of written bytes. The copy is taken by Freeze, so the writers of this mapping
+// are not stopped while the snapshot is written, but every page is copied before the writing starts
+// and the copy costs as much memory as this mapping, see Freeze for the consistency guarantees.
+func (m *Mapping) Snapshot(w io.Writer) (int64, error) {
+	frozen, err := m.Freeze()
+	if err != nil {
+		return 0, err
+	}
+	n, err := frozen.WriteTo(w)
+	if closeErr := frozen.Close(); err == nil {
+		err = closeErr
+	}
+	return n, err
+}
+
+// SnapshotToFile writes the consistent point-in-time copy of the mapped memory into the file with the given path
+// like Snapshot. The copy is written into the temporary file in the same directory first which is synchronized
+// and renamed to the given path then, so the file is either the complete copy or it is not changed at all.
+// The file is created with the permissions 0600 if it does not exist.
+func (m *Mapping) SnapshotToFile(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if err := func() error {
+		defer f.Close()
+		if _, err := m.Snapshot(f); err != nil {
+			return err
+		}
+		if err := f.Sync(); err != nil {
+			return err
+		}
+		return f.Close()
+	}(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package mmap
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+)
+
+// pagemapSoftDirty is the soft-dirty bit of the page entry in /proc/self/pagemap.
+const pagemapSoftDirty = 1 << 55
+
+// softDirtyProbe specifies the result of the probe of the soft-dirty bits support which is run once.
+var softDirtyProbe struct {
+	once sync.Once
+	err  error
+	// unsupported specifies whether the probe has found that the bits are not supported.
+	unsupported atomic.Bool
+}
+
+// resetSoftDirty clears the soft-dirty bits of all pages of the process.
+// The support of the soft-dirty bits is probed at the first reset, since the kernel without CONFIG_MEM_SOFT_DIRTY
+// accepts the reset but never sets the bits.
+func resetSoftDirty() error {
+	f, err := os.OpenFile("/proc/self/clear_refs", os.O_WRONLY, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotSupported
+		}
+		return err
+	}
+	if _, err := f.Write([]byte("4")); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	softDirtyProbe.once.Do(func() {
+		softDirtyProbe.err = probeSoftDirty()
+		softDirtyProbe.unsupported.Store(softDirtyProbe.err == ErrNotSupported)
+	})
+	return softDirtyProbe.err
+}
+
+// probeSoftDirty writes the scratch page after the reset and returns ErrNotSupported if its soft-dirty bit is not set.
+func probeSoftDirty() error {
+	b, err := syscall.Mmap(-1, 0, os.Getpagesize(), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_PRIVATE|syscall.MAP_ANON)
+	if err != nil {
+		return os.NewSyscallError("mmap", err)
+	}
+	defer syscall.Munmap(b)
+	b[0] = 1
+	entries, err := pagemapEntries(b)
+	if err != nil {
+		return err
+	}
+	if entries[0]&pagemapSoftDirty == 0 {
+		return ErrNotSupported
+	}
+	return nil
+}
+
+// softDirtyMemory reports which given mapped memory pages have the soft-dirty bit set.
+// The ErrNotSupported returns if the probe at the reset has found that the bits are not supported.
+func (m *Mapping) softDirtyMemory(b []byte) ([]bool, error) {
+	if softDirtyProbe.unsupported.Load() {
+		return nil, ErrNotSupported
+	}
+	entries, err := pagemapEntries(b)
+	if err != nil {
+		return nil, err
+	}
+	dirty := make([]bool, len(entries))
+	for i, entry := range entries {
+		dirty[i] = entry&pagemapSoftDirty != 0
+	}
+	return dirty, nil
+}
+
+// pagemapEntries returns the entries of the given memory pages from /proc/self/pagemap.
+func pagemapEntries(b []byte) ([]uint64, error) {
+	f, err := os.Open("/proc/self/pagemap")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	// The page entries are 64-bit integers in the native byte order.
+	entries := make([]uint64, pageCount(b))
+	buf := unsafe.Slice((*byte)(unsafe.Pointer(&entries[0])), 8*len(entries))
+	if _, err := f.ReadAt(buf, int64(addressOf(b)/uintptr(os.Getpagesize())*8)); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
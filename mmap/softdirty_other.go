@@ -0,0 +1,13 @@
+//go:build !linux
+
+package mmap
+
+// resetSoftDirty returns ErrNotSupported since the soft-dirty bits are available on Linux only.
+func resetSoftDirty() error {
+	return ErrNotSupported
+}
+
+// softDirtyMemory returns ErrNotSupported since the soft-dirty bits are available on Linux only.
+func (m *Mapping) softDirtyMemory(b []byte) ([]bool, error) {
+	return nil, ErrNotSupported
+}
@@ -0,0 +1,117 @@
+package mmap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Stat is a snapshot of the mapping state for logging and inspection.
+type Stat struct {
+	// Path specifies the path of the mapped file if it is known, e.g. the mapping is opened by OpenFile or OpenFD.
+	Path string
+	// Fd specifies the descriptor of the mapped file, see Mapping.Fd.
+	Fd uintptr
+	// Address specifies the pointer to the mapped memory.
+	Address uintptr
+	// Offset specifies the offset of the mapped memory from start of the file.
+	Offset int64
+	// Length specifies the mapped memory length in bytes.
+	Length uintptr
+	// Mode specifies the mapping mode.
+	Mode Mode
+	// Flags specifies the mapping flags.
+	Flags Flag
+	// Writable specifies whether the mapped memory pages may be written.
+	Writable bool
+	// Locked specifies whether the mapped memory is locked.
+	Locked bool
+	// Pages specifies the number of the memory pages which contain the mapped memory.
+	Pages int
+	// ResidentPages specifies the number of the memory pages which are resident in RAM at the moment.
+	ResidentPages int
+}
+
+// String returns the name of the mapping mode.
+func (mode Mode) String() string {
+	switch mode {
+	case ModeReadOnly:
+		return "ro"
+	case ModeReadWrite:
+		return "rw"
+	case ModeWriteCopy:
+		return "cow"
+	}
+	return fmt.Sprintf("Mode(%d)", int(mode))
+}
+
+// flagNames is the names of the mapping flags in order of their bits.
+var flagNames = []string{"exec", "huge", "fixed", "dontfork", "wipeonfork", "pmem"}
+
+// String returns the names of the mapping flags separated by the vertical bar.
+func (flags Flag) String() string {
+	var names []string
+	for i, name := range flagNames {
+		if flags&(1<<i) != 0 {
+			names = append(names, name)
+			flags &^= 1 << i
+		}
+	}
+	if flags != 0 {
+		names = append(names, fmt.Sprintf("%#x", int(flags)))
+	}
+	return strings.Join(names, "|")
+}
+
+// Stat returns the snapshot of the mapping state.
+func (m *Mapping) Stat() (Stat, error) {
+	if m.memory == nil {
+		return Stat{}, ErrClosed
+	}
+	s := Stat{
+		Path:     m.path,
+		Fd:       m.Fd(),
+		Address:  m.address,
+		Offset:   m.fileOffset,
+		Length:   m.Length(),
+		Mode:     m.mode,
+		Flags:    m.flags,
+		Writable: m.writable,
+		Locked:   m.locked,
+		Pages:    pageCount(m.alignedMemory),
+	}
+	resident, err := m.residentMemory(m.alignedMemory)
+	if err != nil {
+		return Stat{}, err
+	}
+	for _, r := range resident {
+		if r {
+			s.ResidentPages++
+		}
+	}
+	return s, nil
+}
+
+// String returns the compact description of the mapping state.
+func (m *Mapping) String() string {
+	if m.memory == nil {
+		return "mmap(closed)"
+	}
+	var b strings.Builder
+	b.WriteString("mmap(")
+	if m.path != "" {
+		fmt.Fprintf(&b, "%q", m.path)
+	} else if fd := m.Fd(); fd == anonymousFd {
+		b.WriteString("anonymous")
+	} else {
+		fmt.Fprintf(&b, "fd=%d", fd)
+	}
+	fmt.Fprintf(&b, " @%d+%d %s", m.fileOffset, len(m.memory), m.mode)
+	if m.flags != 0 {
+		fmt.Fprintf(&b, " %s", m.flags)
+	}
+	if m.locked {
+		b.WriteString(" locked")
+	}
+	b.WriteString(")")
+	return b.String()
+}
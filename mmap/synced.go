@@ -0,0 +1,84 @@
+package mmap
+
+import (
+	"io"
+	"sync"
+)
+
+// SyncedMapping is a concurrency-safe wrapper of the mapping.
+// Its methods may be called concurrently, Close waits for the in-flight operations
+// and the subsequent ones return ErrClosed instead of accessing the unmapped memory.
+// Concurrent writes of the same bytes are not ordered, it is up to the caller.
+type SyncedMapping struct {
+	// mu protects the mapping from closing during the operations on it.
+	mu sync.RWMutex
+	// m specifies the wrapped mapping.
+	m *Mapping
+}
+
+// Synced returns a new concurrency-safe wrapper of the given mapping.
+// The mapping must not be used directly after that.
+func Synced(m *Mapping) *SyncedMapping {
+	return &SyncedMapping{m: m}
+}
+
+// Length returns the mapped memory length in bytes or zero if the mapping is closed.
+func (s *SyncedMapping) Length() uintptr {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Length()
+}
+
+// ReadAt reads len(buf) bytes at the given offset from start of the mapped memory from the mapped memory.
+// ReadAt implements the io.ReaderAt interface.
+func (s *SyncedMapping) ReadAt(buf []byte, offset int64) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.ReadAt(buf, offset)
+}
+
+// WriteAt writes len(buf) bytes at the given offset from start of the mapped memory into the mapped memory.
+// WriteAt implements the io.WriterAt interface.
+func (s *SyncedMapping) WriteAt(buf []byte, offset int64) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.WriteAt(buf, offset)
+}
+
+// Sync synchronizes the mapped memory with the underlying file.
+func (s *SyncedMapping) Sync() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Sync()
+}
+
+// SyncDirty synchronizes only the written mapped memory pages with the underlying file, see Mapping.SyncDirty.
+func (s *SyncedMapping) SyncDirty() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.SyncDirty()
+}
+
+// Snapshot writes the consistent point-in-time copy of the mapped memory into the given writer,
+// see Mapping.Snapshot. The mapping is not closed while the snapshot is written.
+func (s *SyncedMapping) Snapshot(w io.Writer) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Snapshot(w)
+}
+
+// SnapshotToFile writes the consistent point-in-time copy of the mapped memory into the file with the given path,
+// see Mapping.SnapshotToFile. The mapping is not closed while the snapshot is written.
+func (s *SyncedMapping) SnapshotToFile(path string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.SnapshotToFile(path)
+}
+
+// Close waits for the in-flight operations and closes the wrapped mapping.
+// Close implements the io.Closer interface.
+func (s *SyncedMapping) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.Close()
+}
@@ -0,0 +1,125 @@
+package mmap
+
+import (
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// errNotLocked is the ERROR_NOT_LOCKED system error code.
+const errNotLocked = syscall.Errno(158)
+
+// errWorkingSetQuota is the ERROR_WORKING_SET_QUOTA system error code.
+const errWorkingSetQuota = syscall.Errno(1453)
+
+// ioctlDiskGetLengthInfo is the IOCTL_DISK_GET_LENGTH_INFO control code.
+const ioctlDiskGetLengthInfo = 0x7405c
+
+// Mapping object attributes and access flags which are not provided by the syscall package.
+const (
+	secCommit         = 0x8000000
+	secLargePages     = 0x80000000
+	fileMapLargePages = 0x20000000
+)
+
+// seLockMemoryPrivilege is the name of the privilege which allows to use large pages.
+const seLockMemoryPrivilege = "SeLockMemoryPrivilege"
+
+var (
+	modkernel32               = windows.NewLazySystemDLL("kernel32.dll")
+	procFlushInstructionCache = modkernel32.NewProc("FlushInstructionCache")
+	procGetSystemInfo         = modkernel32.NewProc("GetSystemInfo")
+	procMapViewOfFileEx       = modkernel32.NewProc("MapViewOfFileEx")
+	procPrefetchVirtualMemory = modkernel32.NewProc("PrefetchVirtualMemory")
+	procReOpenFile            = modkernel32.NewProc("ReOpenFile")
+)
+
+// memoryRangeEntry is the WIN32_MEMORY_RANGE_ENTRY structure.
+type memoryRangeEntry struct {
+	address uintptr
+	length  uintptr
+}
+
+// systemInfo is the SYSTEM_INFO structure.
+type systemInfo struct {
+	processorArchitecture     uint16
+	reserved                  uint16
+	pageSize                  uint32
+	minimumApplicationAddress uintptr
+	maximumApplicationAddress uintptr
+	activeProcessorMask       uintptr
+	numberOfProcessors        uint32
+	processorType             uint32
+	allocationGranularity     uint32
+	processorLevel            uint16
+	processorRevision         uint16
+}
+
+// getSystemInfo wraps the system call for GetSystemInfo which never fails.
+func getSystemInfo(info *systemInfo) {
+	_, _, _ = procGetSystemInfo.Call(uintptr(unsafe.Pointer(info)))
+}
+
+// viewMemory returns the byte slice which wraps the mapped view of the given length at the given address.
+// The address is reinterpreted through its storage instead of the conversion from uintptr,
+// the view is not managed by the garbage collector and stays valid until it is unmapped.
+func viewMemory(addr uintptr, length uintptr) []byte {
+	return unsafe.Slice((*byte)(*(*unsafe.Pointer)(unsafe.Pointer(&addr))), length)
+}
+
+// mapViewOfFileEx wraps the system call for MapViewOfFileEx.
+// The base address is just a placement request, it is never dereferenced.
+func mapViewOfFileEx(hMapping syscall.Handle, access, offsetHigh, offsetLow uint32, length uintptr, base uintptr) (uintptr, error) {
+	r, _, err := procMapViewOfFileEx.Call(
+		uintptr(hMapping), uintptr(access), uintptr(offsetHigh), uintptr(offsetLow), length, base,
+	)
+	if r == 0 {
+		return 0, err
+	}
+	return r, nil
+}
+
+// prefetchVirtualMemory wraps the system call for PrefetchVirtualMemory.
+func prefetchVirtualMemory(hProcess syscall.Handle, count uintptr, entries *memoryRangeEntry, flags uint32) error {
+	if err := procPrefetchVirtualMemory.Find(); err != nil {
+		return err
+	}
+	r, _, err := procPrefetchVirtualMemory.Call(
+		uintptr(hProcess), count, uintptr(unsafe.Pointer(entries)), uintptr(flags),
+	)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+// reOpenFile wraps the system call for ReOpenFile.
+func reOpenFile(h syscall.Handle, access, share, flags uint32) (syscall.Handle, error) {
+	r, _, err := procReOpenFile.Call(uintptr(h), uintptr(access), uintptr(share), uintptr(flags))
+	if syscall.Handle(r) == syscall.InvalidHandle {
+		return syscall.InvalidHandle, err
+	}
+	return syscall.Handle(r), nil
+}
+
+// enablePrivilege enables the given privilege in the access token of the current process.
+// It succeeds but has no effect if the privilege is not granted to the user.
+func enablePrivilege(name string) error {
+	var token windows.Token
+	err := windows.OpenProcessToken(windows.CurrentProcess(), windows.TOKEN_ADJUST_PRIVILEGES|windows.TOKEN_QUERY, &token)
+	if err != nil {
+		return err
+	}
+	defer token.Close()
+	privileges := windows.Tokenprivileges{PrivilegeCount: 1}
+	privileges.Privileges[0].Attributes = windows.SE_PRIVILEGE_ENABLED
+	namePtr, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	if err := windows.LookupPrivilegeValue(nil, namePtr, &privileges.Privileges[0].Luid); err != nil {
+		return err
+	}
+	return windows.AdjustTokenPrivileges(token, false, &privileges, 0, nil, nil)
+}
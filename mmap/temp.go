@@ -0,0 +1,42 @@
+package mmap
+
+import "os"
+
+// OpenTemp opens and returns a new mapping of the new temporary file of the given size
+// which is created in the given directory, or in the default directory for temporary files if it is empty.
+// The mapped memory is initialized with zeros. The file has no name or is deleted on close,
+// so it disappears automatically when the mapping is closed or the process exits.
+// It provides the disk-backed scratch space of an arbitrary size.
+func OpenTemp(dir string, size uintptr) (*Mapping, error) {
+	if size == 0 || size > uintptr(MaxInt) {
+		return nil, ErrBadLength
+	}
+	f, err := createTemp(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Truncate(int64(size)); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	m, err := OpenFD(f, 0, size, ModeReadWrite, 0, WithCloseFile())
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// createRemovedTemp creates the new temporary file in the given directory and removes it immediately,
+// so the file exists until it is closed. It is not used on Windows where the open file can not be removed.
+func createRemovedTemp(dir string) (*os.File, error) {
+	f, err := os.CreateTemp(dir, "go-bio-mmap-*")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(f.Name()); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return f, nil
+}
@@ -0,0 +1,19 @@
+package mmap
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// createTemp creates the new unnamed temporary file in the given directory.
+// The named file is created and removed immediately if the file system does not support O_TMPFILE.
+func createTemp(dir string) (*os.File, error) {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	if f, err := os.OpenFile(dir, os.O_RDWR|unix.O_TMPFILE, 0600); err == nil {
+		return f, nil
+	}
+	return createRemovedTemp(dir)
+}
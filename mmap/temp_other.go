@@ -0,0 +1,10 @@
+//go:build !linux && !windows
+
+package mmap
+
+import "os"
+
+// createTemp creates the new temporary file in the given directory and removes it immediately.
+func createTemp(dir string) (*os.File, error) {
+	return createRemovedTemp(dir)
+}
@@ -0,0 +1,42 @@
+package mmap
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// tempIndex is the index of the last temporary file created by this process.
+var tempIndex uint32
+
+// createTemp creates the new temporary file in the given directory which is deleted on close.
+func createTemp(dir string) (*os.File, error) {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	for try := 0; ; try++ {
+		name := filepath.Join(dir, "go-bio-mmap-"+strconv.Itoa(os.Getpid())+"-"+
+			strconv.FormatUint(uint64(atomic.AddUint32(&tempIndex, 1)), 10)+"-"+
+			strconv.FormatInt(time.Now().UnixNano(), 36))
+		namePtr, err := windows.UTF16PtrFromString(name)
+		if err != nil {
+			return nil, err
+		}
+		h, err := windows.CreateFile(
+			namePtr, windows.GENERIC_READ|windows.GENERIC_WRITE,
+			windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE, nil,
+			windows.CREATE_NEW, windows.FILE_ATTRIBUTE_TEMPORARY|windows.FILE_FLAG_DELETE_ON_CLOSE, 0,
+		)
+		if err == windows.ERROR_FILE_EXISTS && try < 10000 {
+			continue
+		}
+		if err != nil {
+			return nil, &os.PathError{Op: "open", Path: name, Err: err}
+		}
+		return os.NewFile(uintptr(h), name), nil
+	}
+}
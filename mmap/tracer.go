@@ -0,0 +1,82 @@
+package mmap
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Op is a traced mapping operation.
+type Op int
+
+const (
+	// Opening of the mapping.
+	OpOpen Op = iota
+
+	// Synchronization of the mapped memory with the underlying file.
+	OpSync
+
+	// Locking of the mapped memory.
+	OpLock
+
+	// Closing of the mapping.
+	OpClose
+)
+
+// String returns the name of the traced operation.
+func (op Op) String() string {
+	switch op {
+	case OpOpen:
+		return "open"
+	case OpSync:
+		return "sync"
+	case OpLock:
+		return "lock"
+	case OpClose:
+		return "close"
+	}
+	return "unknown"
+}
+
+// Event is a traced mapping operation which is completed.
+type Event struct {
+	// Op specifies the operation.
+	Op Op
+	// Mapping specifies the mapping which the operation is performed on.
+	// It is nil if the opening fails and it is already closed for the closing.
+	Mapping *Mapping
+	// Length specifies the length in bytes of the mapped memory which the operation is performed on.
+	Length uintptr
+	// Start specifies the start time of the operation.
+	Start time.Time
+	// Duration specifies the duration of the operation.
+	Duration time.Duration
+	// Err specifies the error of the operation if any.
+	Err error
+}
+
+// Tracer is a receiver of the traced mapping operations, e.g. to correlate the long synchronization
+// with the latency of the application. Its method is called synchronously after the operation
+// from any goroutine, so it must be fast and concurrency-safe.
+type Tracer interface {
+	// Trace is called when the mapping operation is completed.
+	Trace(e Event)
+}
+
+// tracerHolder holds the current tracer.
+type tracerHolder struct {
+	tracer Tracer
+}
+
+// currentTracer specifies the current tracer of all mappings.
+var currentTracer atomic.Value
+
+// SetTracer sets the tracer of all mappings, the nil one disables the tracing.
+func SetTracer(tracer Tracer) {
+	currentTracer.Store(tracerHolder{tracer})
+}
+
+// loadTracer returns the current tracer or nil if the tracing is disabled.
+func loadTracer() Tracer {
+	h, _ := currentTracer.Load().(tracerHolder)
+	return h.tracer
+}
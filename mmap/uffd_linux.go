@@ -0,0 +1,110 @@
+//go:build linux && (386 || amd64 || arm || arm64 || loong64 || riscv64 || s390x)
+
+package mmap
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// The userfaultfd constants from linux/userfaultfd.h.
+// The ioctl numbers are encoded by the generic scheme which is used by the listed architectures.
+const (
+	uffdAPI                     = 0xaa
+	uffdUserModeOnly            = 1
+	uffdFeatureSigbus           = 1 << 7
+	uffdFeatureWPHugetlbfsShmem = 1 << 12
+	uffdFeatureWPUnpopulated    = 1 << 13
+	uffdFeatureWPAsync          = 1 << 15
+	uffdioRegisterModeMissing   = 1
+	uffdioRegisterModeWP        = 2
+	uffdioWriteProtectModeWP    = 1
+	uffdioAPI                   = 0xc018aa3f
+	uffdioRegister              = 0xc020aa00
+	uffdioCopy                  = 0xc028aa03
+	uffdioWriteProtect          = 0xc018aa06
+)
+
+// uffdioAPIArg is the argument of the UFFDIO_API request.
+type uffdioAPIArg struct {
+	api      uint64
+	features uint64
+	ioctls   uint64
+}
+
+// uffdioRange is the memory range of the userfaultfd requests.
+type uffdioRange struct {
+	start uint64
+	len   uint64
+}
+
+// uffdioRegisterArg is the argument of the UFFDIO_REGISTER request.
+type uffdioRegisterArg struct {
+	rng    uffdioRange
+	mode   uint64
+	ioctls uint64
+}
+
+// uffdioCopyArg is the argument of the UFFDIO_COPY request.
+type uffdioCopyArg struct {
+	dst    uint64
+	src    uint64
+	len    uint64
+	mode   uint64
+	copied int64
+}
+
+// uffdioWriteProtectArg is the argument of the UFFDIO_WRITEPROTECT request.
+type uffdioWriteProtectArg struct {
+	rng  uffdioRange
+	mode uint64
+}
+
+// openUserfaultfd opens the new userfaultfd object with the given features
+// and registers the given memory in it in the given mode.
+// The ErrNotSupported returns if the userfaultfd or the features are not available.
+func openUserfaultfd(b []byte, features, mode uint64) (int, error) {
+	fd, _, errno := unix.Syscall(unix.SYS_USERFAULTFD, unix.O_CLOEXEC|unix.O_NONBLOCK, 0, 0)
+	if errno == unix.EPERM {
+		// The unprivileged users may handle the faults from the user mode only,
+		// see the vm.unprivileged_userfaultfd sysctl.
+		fd, _, errno = unix.Syscall(unix.SYS_USERFAULTFD, unix.O_CLOEXEC|unix.O_NONBLOCK|uffdUserModeOnly, 0, 0)
+	}
+	if errno != 0 {
+		return -1, uffdError(errno)
+	}
+	api := uffdioAPIArg{api: uffdAPI, features: features}
+	if err := uffdIoctl(int(fd), uffdioAPI, unsafe.Pointer(&api)); err != nil {
+		_ = unix.Close(int(fd))
+		return -1, err
+	}
+	// The registered range consists of the whole memory pages.
+	register := uffdioRegisterArg{
+		rng:  uffdioRange{start: uint64(addressOf(b)), len: uint64(pageCount(b) * os.Getpagesize())},
+		mode: mode,
+	}
+	if err := uffdIoctl(int(fd), uffdioRegister, unsafe.Pointer(&register)); err != nil {
+		_ = unix.Close(int(fd))
+		return -1, err
+	}
+	return int(fd), nil
+}
+
+// uffdIoctl performs the given userfaultfd request.
+func uffdIoctl(fd int, req uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), req, uintptr(arg)); errno != 0 {
+		return uffdError(errno)
+	}
+	return nil
+}
+
+// uffdError converts the userfaultfd error, the missing features are reported by ErrNotSupported.
+func uffdError(errno unix.Errno) error {
+	switch errno {
+	case unix.ENOSYS, unix.EINVAL, unix.EPERM, unix.EOPNOTSUPP:
+		return ErrNotSupported
+	}
+	return os.NewSyscallError("userfaultfd", errno)
+}
@@ -0,0 +1,78 @@
+package mmap
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// warmupChunk is the length in bytes of the part of the mapped memory which is warmed by a single worker at once.
+const warmupChunk = 1 << 20
+
+// Warmup faults in every page of the mapped memory and returns when all of them are resident in RAM,
+// so services may load the index file at startup deterministically instead of paying
+// the page fault latency during the first requests.
+// The pages are requested with AdviceWillNeed first and then touched by the given number of workers in parallel,
+// a single worker is used if it is less than one. If the progress function is not nil,
+// it is called from the calling goroutine with the number of the warmed and total bytes after each part.
+// The pages of the lazy mapping are supplied by the workers, the first error of the fill function returns.
+func (m *Mapping) Warmup(workers int, progress func(warmed, total uintptr)) error {
+	if m.memory == nil {
+		return ErrClosed
+	}
+	b := m.alignedMemory
+	if err := m.adviseMemory(b, AdviceWillNeed); err != nil {
+		return err
+	}
+	total := uintptr(len(b))
+	chunks := (len(b) + warmupChunk - 1) / warmupChunk
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > chunks {
+		workers = chunks
+	}
+	pageSize := os.Getpagesize()
+	var next int64
+	var failure error
+	var failed sync.Once
+	warmed := make(chan uintptr, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				chunk := int(atomic.AddInt64(&next, 1) - 1)
+				if chunk >= chunks {
+					return
+				}
+				low, high := chunk*warmupChunk, (chunk+1)*warmupChunk
+				if high > len(b) {
+					high = len(b)
+				}
+				if m.lazy != nil {
+					if err := m.lazy.populate(m, low/pageSize, (high+pageSize-1)/pageSize); err != nil {
+						failed.Do(func() { failure = err })
+						return
+					}
+				} else {
+					touchPages(b[low:high])
+				}
+				warmed <- uintptr(high - low)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(warmed)
+	}()
+	var done uintptr
+	for n := range warmed {
+		done += n
+		if progress != nil {
+			progress(done, total)
+		}
+	}
+	return failure
+}
@@ -0,0 +1,111 @@
+package mmap
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Change is a kind of the external modification of the mapped file.
+type Change int
+
+const (
+	// The file is written, e.g. by another process through its descriptor.
+	ChangeWritten Change = iota
+
+	// The file is truncated below the end of the mapped memory,
+	// so the access to the mapped memory beyond the end of the file raises SIGBUS.
+	ChangeTruncated
+
+	// The file is removed or renamed, e.g. it is replaced by another file by the atomic rename,
+	// so the mapped memory is not the content of the file by the path anymore.
+	ChangeReplaced
+)
+
+// watchBuffer is the number of the changes which are buffered for the receiver of the watcher channel.
+const watchBuffer = 16
+
+// String returns the name of the change.
+func (c Change) String() string {
+	switch c {
+	case ChangeWritten:
+		return "written"
+	case ChangeTruncated:
+		return "truncated"
+	case ChangeReplaced:
+		return "replaced"
+	}
+	return fmt.Sprintf("Change(%d)", int(c))
+}
+
+// watcher is a background goroutine which watches for the external modifications of the mapped file.
+type watcher struct {
+	// changes specifies the channel which receives the changes.
+	changes chan Change
+	// end specifies the offset of the end of the mapped memory from start of the file.
+	end atomic.Int64
+	// done specifies the channel which is closed when the goroutine is stopped.
+	done chan struct{}
+	// wake specifies the function which wakes the goroutine to stop it.
+	wake func()
+	// release specifies the function which releases the resources of the stopped goroutine.
+	release func()
+}
+
+// Watch returns the channel which is notified when the mapped file is modified externally:
+// written, truncated below the end of the mapped memory, removed or replaced.
+// So the readers may map the file again or bail out instead of hitting SIGBUS.
+// The changes which are not received in time are dropped, the channel is closed when the mapping is closed.
+// The same channel returns if the mapping is already watched.
+// The modifications through the mapped memory are not reported, the ones through the descriptor are reported
+// even if they are made by this process. It is based on inotify on Linux, kqueue on Darwin
+// and ReadDirectoryChangesW on Windows, the ErrNotSupported returns on other platforms and for the anonymous mapping.
+func (m *Mapping) Watch() (<-chan Change, error) {
+	if m.memory == nil {
+		return nil, ErrClosed
+	}
+	if m.watcher != nil {
+		return m.watcher.changes, nil
+	}
+	if m.descriptor() == anonymousFd {
+		return nil, ErrNotSupported
+	}
+	w := &watcher{changes: make(chan Change, watchBuffer), done: make(chan struct{})}
+	w.end.Store(m.fileOffset + int64(len(m.memory)))
+	if err := m.startWatch(w); err != nil {
+		return nil, err
+	}
+	m.watcher = w
+	return w.changes, nil
+}
+
+// stopWatch stops the watcher of this mapping if any and waits for it.
+func (m *Mapping) stopWatch() {
+	if w := m.watcher; w != nil {
+		w.wake()
+		<-w.done
+		w.release()
+		m.watcher = nil
+	}
+}
+
+// notify sends the given change to the receiver unless the channel is full.
+func (w *watcher) notify(c Change) {
+	select {
+	case w.changes <- c:
+	default:
+	}
+}
+
+// resized returns the change of the file which is written and has the given size.
+func (w *watcher) resized(size int64) Change {
+	if size < w.end.Load() {
+		return ChangeTruncated
+	}
+	return ChangeWritten
+}
+
+// finish closes the channels of the stopped goroutine.
+func (w *watcher) finish() {
+	close(w.changes)
+	close(w.done)
+}
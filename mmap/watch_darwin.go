@@ -0,0 +1,78 @@
+package mmap
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// startWatch starts the goroutine which watches for the kqueue vnode events of the mapped file.
+// The file is watched by its descriptor, so the watch follows the file itself and not its path.
+func (m *Mapping) startWatch(w *watcher) error {
+	fd, err := unix.FcntlInt(uintptr(m.fd), unix.F_DUPFD_CLOEXEC, 0)
+	if err != nil {
+		return os.NewSyscallError("fcntl", err)
+	}
+	kq, err := unix.Kqueue()
+	if err != nil {
+		_ = unix.Close(fd)
+		return os.NewSyscallError("kqueue", err)
+	}
+	unix.CloseOnExec(kq)
+	changes := make([]unix.Kevent_t, 2)
+	unix.SetKevent(&changes[0], fd, unix.EVFILT_VNODE, unix.EV_ADD|unix.EV_CLEAR)
+	changes[0].Fflags = unix.NOTE_WRITE | unix.NOTE_EXTEND | unix.NOTE_ATTRIB | unix.NOTE_DELETE | unix.NOTE_RENAME | unix.NOTE_REVOKE
+	unix.SetKevent(&changes[1], 0, unix.EVFILT_USER, unix.EV_ADD|unix.EV_CLEAR)
+	if _, err := unix.Kevent(kq, changes, nil, nil); err != nil {
+		_ = unix.Close(kq)
+		_ = unix.Close(fd)
+		return os.NewSyscallError("kevent", err)
+	}
+	w.wake = func() {
+		trigger := make([]unix.Kevent_t, 1)
+		unix.SetKevent(&trigger[0], 0, unix.EVFILT_USER, 0)
+		trigger[0].Fflags = unix.NOTE_TRIGGER
+		_, _ = unix.Kevent(kq, trigger, nil, nil)
+	}
+	w.release = func() {
+		_ = unix.Close(kq)
+		_ = unix.Close(fd)
+	}
+	go w.serve(fd, kq)
+	return nil
+}
+
+// serve receives the kqueue events of the file with the given descriptor
+// and notifies about the changes until the goroutine is woken to stop.
+func (w *watcher) serve(fd, kq int) {
+	defer w.finish()
+	events := make([]unix.Kevent_t, 4)
+	for {
+		n, err := unix.Kevent(kq, nil, events, nil)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return
+		}
+		var fflags uint32
+		for _, event := range events[:n] {
+			if event.Filter == unix.EVFILT_USER {
+				return
+			}
+			fflags |= event.Fflags
+		}
+		var stat unix.Stat_t
+		if err := unix.Fstat(fd, &stat); err != nil {
+			continue
+		}
+		switch {
+		case fflags&(unix.NOTE_DELETE|unix.NOTE_RENAME|unix.NOTE_REVOKE) != 0 || stat.Nlink == 0:
+			w.notify(ChangeReplaced)
+		case fflags&(unix.NOTE_WRITE|unix.NOTE_EXTEND) != 0:
+			w.notify(w.resized(stat.Size))
+		case stat.Size < w.end.Load():
+			w.notify(ChangeTruncated)
+		}
+	}
+}
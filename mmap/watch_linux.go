@@ -0,0 +1,94 @@
+package mmap
+
+import (
+	"os"
+	"strconv"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// startWatch starts the goroutine which watches for the inotify events of the mapped file.
+// The file is watched by its descriptor, so the watch follows the file itself and not its path.
+func (m *Mapping) startWatch(w *watcher) error {
+	fd, err := unix.FcntlInt(uintptr(m.fd), unix.F_DUPFD_CLOEXEC, 0)
+	if err != nil {
+		return os.NewSyscallError("fcntl", err)
+	}
+	ifd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		_ = unix.Close(fd)
+		return os.NewSyscallError("inotify_init1", err)
+	}
+	mask := uint32(unix.IN_MODIFY | unix.IN_ATTRIB | unix.IN_MOVE_SELF | unix.IN_DELETE_SELF)
+	if _, err := unix.InotifyAddWatch(ifd, "/proc/self/fd/"+strconv.Itoa(fd), mask); err != nil {
+		_ = unix.Close(ifd)
+		_ = unix.Close(fd)
+		if err == unix.ENOENT {
+			return ErrNotSupported
+		}
+		return os.NewSyscallError("inotify_add_watch", err)
+	}
+	wake, err := unix.Eventfd(0, unix.EFD_CLOEXEC)
+	if err != nil {
+		_ = unix.Close(ifd)
+		_ = unix.Close(fd)
+		return os.NewSyscallError("eventfd", err)
+	}
+	w.wake = func() {
+		// The eventfd counter is the 64-bit integer in the native byte order.
+		one := uint64(1)
+		_, _ = unix.Write(wake, unsafe.Slice((*byte)(unsafe.Pointer(&one)), 8))
+	}
+	w.release = func() {
+		_ = unix.Close(wake)
+		_ = unix.Close(ifd)
+		_ = unix.Close(fd)
+	}
+	go w.serve(fd, ifd, wake)
+	return nil
+}
+
+// serve reads the inotify events of the file with the given descriptor
+// and notifies about the changes until the goroutine is woken to stop.
+func (w *watcher) serve(fd, ifd, wake int) {
+	defer w.finish()
+	buf := make([]byte, 4096)
+	fds := []unix.PollFd{{Fd: int32(ifd), Events: unix.POLLIN}, {Fd: int32(wake), Events: unix.POLLIN}}
+	for {
+		if _, err := unix.Poll(fds, -1); err != nil {
+			if err == unix.EINTR {
+				continue
+			}
+			return
+		}
+		if fds[1].Revents != 0 {
+			return
+		}
+		n, err := unix.Read(ifd, buf)
+		if err == unix.EAGAIN || err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return
+		}
+		var mask uint32
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			mask |= event.Mask
+			offset += unix.SizeofInotifyEvent + int(event.Len)
+		}
+		var stat unix.Stat_t
+		if err := unix.Fstat(fd, &stat); err != nil {
+			continue
+		}
+		switch {
+		case mask&(unix.IN_MOVE_SELF|unix.IN_DELETE_SELF) != 0 || stat.Nlink == 0:
+			w.notify(ChangeReplaced)
+		case mask&unix.IN_MODIFY != 0:
+			w.notify(w.resized(stat.Size))
+		case stat.Size < w.end.Load():
+			w.notify(ChangeTruncated)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !darwin && !linux && !windows
+
+package mmap
+
+// startWatch returns ErrNotSupported since there is no portable way to watch for the file changes.
+func (m *Mapping) startWatch(w *watcher) error {
+	return ErrNotSupported
+}
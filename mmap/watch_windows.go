@@ -0,0 +1,130 @@
+package mmap
+
+import (
+	"os"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// startWatch starts the goroutine which watches for the changes of the directory of the mapped file
+// and filters the ones of the file by its name.
+func (m *Mapping) startWatch(w *watcher) error {
+	path, err := finalPath(windows.Handle(m.hFile))
+	if err != nil {
+		return err
+	}
+	i := strings.LastIndexByte(path, '\\')
+	if i < 0 {
+		return ErrNotSupported
+	}
+	dirName, err := windows.UTF16PtrFromString(path[:i])
+	if err != nil {
+		return ErrBadName
+	}
+	process := windows.CurrentProcess()
+	var file windows.Handle
+	err = windows.DuplicateHandle(process, windows.Handle(m.hFile), process, &file, 0, false, windows.DUPLICATE_SAME_ACCESS)
+	if err != nil {
+		return os.NewSyscallError("DuplicateHandle", err)
+	}
+	dir, err := windows.CreateFile(
+		dirName, windows.FILE_LIST_DIRECTORY,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE,
+		nil, windows.OPEN_EXISTING, windows.FILE_FLAG_BACKUP_SEMANTICS|windows.FILE_FLAG_OVERLAPPED, 0,
+	)
+	if err != nil {
+		_ = windows.CloseHandle(file)
+		return os.NewSyscallError("CreateFile", err)
+	}
+	events := make([]windows.Handle, 2)
+	for j := range events {
+		if events[j], err = windows.CreateEvent(nil, 1, 0, nil); err != nil {
+			for _, event := range events[:j] {
+				_ = windows.CloseHandle(event)
+			}
+			_ = windows.CloseHandle(dir)
+			_ = windows.CloseHandle(file)
+			return os.NewSyscallError("CreateEvent", err)
+		}
+	}
+	w.wake = func() {
+		_ = windows.SetEvent(events[1])
+	}
+	w.release = func() {
+		for _, event := range events {
+			_ = windows.CloseHandle(event)
+		}
+		_ = windows.CloseHandle(dir)
+		_ = windows.CloseHandle(file)
+	}
+	go w.serve(file, dir, events, path[i+1:])
+	return nil
+}
+
+// finalPath returns the path of the file with the given handle.
+func finalPath(h windows.Handle) (string, error) {
+	buf := make([]uint16, windows.MAX_PATH)
+	for {
+		n, err := windows.GetFinalPathNameByHandle(h, &buf[0], uint32(len(buf)), 0)
+		if err != nil {
+			return "", os.NewSyscallError("GetFinalPathNameByHandle", err)
+		}
+		if n < uint32(len(buf)) {
+			return windows.UTF16ToString(buf[:n]), nil
+		}
+		buf = make([]uint16, n)
+	}
+}
+
+// serve reads the changes of the given directory, notifies about the ones of the file with the given name
+// and handle until the goroutine is woken to stop by the second given event.
+func (w *watcher) serve(file, dir windows.Handle, events []windows.Handle, name string) {
+	defer w.finish()
+	// The buffer must be aligned by the DWORD boundary.
+	buf := make([]uint64, 512)
+	data := unsafe.Slice((*byte)(unsafe.Pointer(&buf[0])), len(buf)*8)
+	mask := uint32(windows.FILE_NOTIFY_CHANGE_FILE_NAME | windows.FILE_NOTIFY_CHANGE_SIZE | windows.FILE_NOTIFY_CHANGE_LAST_WRITE)
+	for {
+		ov := windows.Overlapped{HEvent: events[0]}
+		if err := windows.ReadDirectoryChanges(dir, &data[0], uint32(len(data)), false, mask, nil, &ov, 0); err != nil {
+			return
+		}
+		event, err := windows.WaitForMultipleObjects(events, false, windows.INFINITE)
+		var n uint32
+		if err != nil || event != windows.WAIT_OBJECT_0 {
+			_ = windows.CancelIoEx(dir, &ov)
+			_ = windows.GetOverlappedResult(dir, &ov, &n, true)
+			return
+		}
+		if err := windows.GetOverlappedResult(dir, &ov, &n, false); err != nil {
+			return
+		}
+		var written, replaced bool
+		for offset := uint32(0); n > 0 && offset < n; {
+			info := (*windows.FileNotifyInformation)(unsafe.Pointer(&data[offset]))
+			fileName := windows.UTF16ToString(unsafe.Slice(&info.FileName, info.FileNameLength/2))
+			if strings.EqualFold(fileName, name) {
+				switch info.Action {
+				case windows.FILE_ACTION_MODIFIED:
+					written = true
+				default:
+					replaced = true
+				}
+			}
+			if info.NextEntryOffset == 0 {
+				break
+			}
+			offset += info.NextEntryOffset
+		}
+		switch {
+		case replaced:
+			w.notify(ChangeReplaced)
+		case written:
+			if size, err := fileSize(uintptr(file)); err == nil {
+				w.notify(w.resized(size))
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+//go:build linux && (386 || amd64 || arm || arm64 || loong64 || riscv64 || s390x)
+
+package mmap
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// The write protection constants.
+const (
+	uffdWriteProtectFeatures        = uffdFeatureWPAsync | uffdFeatureWPUnpopulated | uffdFeatureWPHugetlbfsShmem
+	pagemapUffdWP            uint64 = 1 << 57
+)
+
+// writeProtector is the userfaultfd object which write-protects the mapped memory pages.
+// The faults are resolved by the kernel itself (UFFD_FEATURE_WP_ASYNC) without any handler
+// which would have to run outside the Go runtime, it only clears the protection bit of the written page,
+// so the written pages are found in /proc/self/pagemap.
+type writeProtector struct {
+	fd int
+}
+
+// startWriteProtect registers the mapped memory in the new userfaultfd object and write-protects all its pages.
+// The ErrNotSupported returns if the kernel is older than 6.7 or the userfaultfd is not available.
+func (m *Mapping) startWriteProtect() error {
+	fd, err := openUserfaultfd(m.alignedMemory, uffdWriteProtectFeatures, uffdioRegisterModeWP)
+	if err != nil {
+		return err
+	}
+	p := &writeProtector{fd: fd}
+	m.wp = p
+	if err := m.writeProtect(0, pageCount(m.alignedMemory)); err != nil {
+		m.wp = nil
+		p.stop()
+		return err
+	}
+	return nil
+}
+
+// writeProtect write-protects the mapped memory pages from the given low one to the given high one exclusively.
+func (m *Mapping) writeProtect(low, high int) error {
+	pageSize := os.Getpagesize()
+	arg := uffdioWriteProtectArg{
+		rng:  uffdioRange{start: uint64(addressOf(m.alignedMemory[low*pageSize:])), len: uint64((high - low) * pageSize)},
+		mode: uffdioWriteProtectModeWP,
+	}
+	return uffdIoctl(m.wp.fd, uffdioWriteProtect, unsafe.Pointer(&arg))
+}
+
+// collectWriteProtected marks the mapped memory pages which protection is cleared by the writes as written
+// and write-protects them again. The pages are protected before they are synchronized,
+// so the writes made during the synchronization are collected by the next one.
+func (m *Mapping) collectWriteProtected() error {
+	entries, err := pagemapEntries(m.alignedMemory)
+	if err != nil {
+		return err
+	}
+	for low := 0; low < len(entries); {
+		if entries[low]&pagemapUffdWP != 0 {
+			low++
+			continue
+		}
+		high := low + 1
+		for high < len(entries) && entries[high]&pagemapUffdWP == 0 {
+			high++
+		}
+		if err := m.writeProtect(low, high); err != nil {
+			return err
+		}
+		m.markPages(low, high)
+		low = high
+	}
+	return nil
+}
+
+// stop closes the userfaultfd object which removes the write protection of the registered memory.
+func (p *writeProtector) stop() {
+	_ = unix.Close(p.fd)
+}
@@ -0,0 +1,24 @@
+//go:build !linux || !(386 || amd64 || arm || arm64 || loong64 || riscv64 || s390x)
+
+package mmap
+
+// writeProtector is the stub of the userfaultfd object which is available on Linux only.
+type writeProtector struct{}
+
+// startWriteProtect returns ErrNotSupported since the userfaultfd is available on Linux only.
+func (m *Mapping) startWriteProtect() error {
+	return ErrNotSupported
+}
+
+// writeProtect does nothing since the write protection is never started.
+func (m *Mapping) writeProtect(low, high int) error {
+	return nil
+}
+
+// collectWriteProtected does nothing since the write protection is never started.
+func (m *Mapping) collectWriteProtected() error {
+	return nil
+}
+
+// stop does nothing since the write protection is never started.
+func (p *writeProtector) stop() {}
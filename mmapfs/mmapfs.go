@@ -0,0 +1,237 @@
+// Package mmapfs provides the fs.FS implementation which maps the files under the root directory
+// into the memory on open, so it may replace os.DirFS for the static data without the read system calls.
+package mmapfs
+
+import (
+	"container/list"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/alexeymaximov/go-bio/mmap"
+)
+
+// FS is a file system of the files under the root directory which are mapped into the memory read-only.
+// The mappings of the same file are shared by the opened files with the mapping pool
+// and the mappings of the recently opened files are kept open by the least recently used cache,
+// so the file which is opened again is not mapped again. The file system is safe for concurrent use.
+// The mapping is dropped from the cache when the size or the modification time of the file changes.
+type FS struct {
+	// root specifies the root directory.
+	root string
+	// pool specifies the pool of the mappings.
+	pool *mmap.Pool
+	// mu protects the cache.
+	mu sync.Mutex
+	// capacity specifies the maximal number of the cached mappings.
+	capacity int
+	// lru specifies the cached mappings from the most to the least recently used.
+	lru *list.List
+	// cached specifies the elements of lru by the paths of the files.
+	cached map[string]*list.Element
+}
+
+// cacheEntry is a cached mapping of the file.
+type cacheEntry struct {
+	path    string
+	size    int64
+	modTime time.Time
+	ref     *mmap.PooledMapping
+}
+
+// New returns a new file system of the files under the given root directory
+// which keeps up to the given number of the mappings of the recently opened files.
+func New(root string, capacity int, opts ...mmap.Option) *FS {
+	return &FS{
+		root:     root,
+		pool:     mmap.NewPool(0, opts...),
+		capacity: capacity,
+		lru:      list.New(),
+		cached:   make(map[string]*list.Element),
+	}
+}
+
+// Open opens the file or the directory with the given name which must be valid according to fs.ValidPath.
+// The regular file is mapped into the memory, the directory is opened by os.Open.
+// Open implements the fs.FS interface.
+func (fsys *FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	path := filepath.Join(fsys.root, filepath.FromSlash(name))
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return os.Open(path)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	f := &file{info: info}
+	if info.Size() == 0 {
+		// The empty file can not be mapped.
+		return f, nil
+	}
+	// The cached mapping of the changed file is dropped before the new one is requested.
+	fsys.cache(path, info)
+	f.ref, err = fsys.pool.Open(path, 0, uintptr(info.Size()), mmap.ModeReadOnly)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	f.cursor = f.ref.Mapping().NewCursor()
+	return f, nil
+}
+
+// cache keeps the mapping of the file with the given path and information open
+// and drops the least recently used mappings above the capacity.
+func (fsys *FS) cache(path string, info fs.FileInfo) {
+	if fsys.capacity <= 0 {
+		return
+	}
+	fsys.mu.Lock()
+	defer fsys.mu.Unlock()
+	if element, ok := fsys.cached[path]; ok {
+		e := element.Value.(*cacheEntry)
+		if e.size == info.Size() && e.modTime.Equal(info.ModTime()) {
+			fsys.lru.MoveToFront(element)
+			return
+		}
+		fsys.drop(element)
+	}
+	ref, err := fsys.pool.Open(path, 0, uintptr(info.Size()), mmap.ModeReadOnly)
+	if err != nil {
+		return
+	}
+	e := &cacheEntry{path: path, size: info.Size(), modTime: info.ModTime(), ref: ref}
+	fsys.cached[path] = fsys.lru.PushFront(e)
+	for fsys.lru.Len() > fsys.capacity {
+		fsys.drop(fsys.lru.Back())
+	}
+}
+
+// drop releases the cached mapping of the given element.
+func (fsys *FS) drop(element *list.Element) {
+	e := element.Value.(*cacheEntry)
+	fsys.lru.Remove(element)
+	delete(fsys.cached, e.path)
+	_ = e.ref.Close()
+}
+
+// Close releases all cached mappings. The mappings of the opened files are closed when the files are closed.
+// The file system may be used after that, the mappings are cached again.
+// Close implements the io.Closer interface.
+func (fsys *FS) Close() error {
+	fsys.mu.Lock()
+	defer fsys.mu.Unlock()
+	for fsys.lru.Len() > 0 {
+		fsys.drop(fsys.lru.Back())
+	}
+	return nil
+}
+
+// file is an opened file which is mapped into the memory.
+type file struct {
+	// info specifies the information of the file at the moment of opening.
+	info fs.FileInfo
+	// ref specifies the reference to the shared mapping or nil for the empty file.
+	ref *mmap.PooledMapping
+	// cursor specifies the cursor of the mapping or nil for the empty file.
+	cursor *mmap.Cursor
+	// closed specifies whether the file is closed.
+	closed bool
+}
+
+// Stat returns the information of the file at the moment of opening.
+// Stat implements the fs.File interface.
+func (f *file) Stat() (fs.FileInfo, error) {
+	if f.closed {
+		return nil, fs.ErrClosed
+	}
+	return f.info, nil
+}
+
+// Read reads up to len(buf) bytes from the current position and advances it.
+// Read implements the io.Reader interface.
+func (f *file) Read(buf []byte) (int, error) {
+	if f.closed {
+		return 0, fs.ErrClosed
+	}
+	if f.cursor == nil {
+		return 0, io.EOF
+	}
+	return f.cursor.Read(buf)
+}
+
+// ReadAt reads up to len(buf) bytes at the given offset, io.EOF returns if fewer bytes are read.
+// ReadAt implements the io.ReaderAt interface.
+func (f *file) ReadAt(buf []byte, offset int64) (int, error) {
+	if f.closed {
+		return 0, fs.ErrClosed
+	}
+	if offset < 0 {
+		return 0, mmap.ErrBadOffset
+	}
+	if offset >= f.info.Size() {
+		return 0, io.EOF
+	}
+	n := len(buf)
+	if rest := f.info.Size() - offset; int64(n) > rest {
+		n = int(rest)
+	}
+	if _, err := f.ref.Mapping().ReadAt(buf[:n], offset); err != nil {
+		return 0, err
+	}
+	if n < len(buf) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Seek sets the position for the next Read.
+// Seek implements the io.Seeker interface.
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	if f.closed {
+		return 0, fs.ErrClosed
+	}
+	if f.cursor == nil {
+		if whence < io.SeekStart || whence > io.SeekEnd || offset < 0 {
+			return 0, mmap.ErrBadOffset
+		}
+		return offset, nil
+	}
+	return f.cursor.Seek(offset, whence)
+}
+
+// WriteTo writes the file from the current position to the end into the given writer directly
+// from the mapped memory. WriteTo implements the io.WriterTo interface.
+func (f *file) WriteTo(w io.Writer) (int64, error) {
+	if f.closed {
+		return 0, fs.ErrClosed
+	}
+	if f.cursor == nil {
+		return 0, nil
+	}
+	return f.cursor.WriteTo(w)
+}
+
+// Close releases the mapping of the file.
+// Close implements the fs.File interface.
+func (f *file) Close() error {
+	if f.closed {
+		return fs.ErrClosed
+	}
+	f.closed = true
+	if f.ref == nil {
+		return nil
+	}
+	if err := f.ref.Close(); err != nil && !errors.Is(err, mmap.ErrClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package mmapfs
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+// testData is the non-zero test data.
+var testData = []byte{'H', 'E', 'L', 'L', 'O'}
+
+// createTestTree creates the test directory tree and returns its root.
+func createTestTree(t *testing.T) string {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "dir"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{"hello.txt": testData, "dir/empty.txt": nil} {
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(name)), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+//------------------------------------------- TEST CASES ---------------------------------------------------------------
+
+// TestFS tests the file system by the standard checks.
+// CASE: The file system MUST satisfy fstest.TestFS.
+func TestFS(t *testing.T) {
+	fsys := New(createTestTree(t), 1)
+	defer fsys.Close()
+	if err := fstest.TestFS(fsys, "hello.txt", "dir/empty.txt"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestCache tests the cache of the mappings.
+// CASE 1: The file MUST be read from the cached mapping.
+// CASE 2: The changed file MUST be mapped again.
+// CASE 3: The replaced file of the same size and modification time MUST be mapped again
+// while the file which is opened before keeps the old mapping.
+func TestCache(t *testing.T) {
+	root := createTestTree(t)
+	fsys := New(root, 1)
+	defer fsys.Close()
+	data, err := fs.ReadFile(fsys, "hello.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(testData) {
+		t.Fatalf("data must be %q, %q found", testData, data)
+	}
+	if fsys.lru.Len() != 1 || fsys.pool.Len() != 1 {
+		t.Fatalf("mapping must be cached, %d cached and %d pooled found", fsys.lru.Len(), fsys.pool.Len())
+	}
+	if err := os.WriteFile(filepath.Join(root, "hello.txt"), []byte("HELLO WORLD"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := fsys.Open("hello.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "HELLO WORLD" {
+		t.Fatalf("data must be %q, %q found", "HELLO WORLD", data)
+	}
+	if fsys.pool.Len() != 1 {
+		t.Fatalf("only the new mapping must be pooled, %d found", fsys.pool.Len())
+	}
+	path := filepath.Join(root, "hello.txt")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, err := fsys.Open("hello.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer old.Close()
+	replacement := filepath.Join(root, "replacement.txt")
+	if err := os.WriteFile(replacement, []byte("HELLO AGAIN"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(replacement, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(replacement, path); err != nil {
+		t.Fatal(err)
+	}
+	data, err = fs.ReadFile(fsys, "hello.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "HELLO AGAIN" {
+		t.Fatalf("data must be %q, %q found", "HELLO AGAIN", data)
+	}
+	data, err = io.ReadAll(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "HELLO WORLD" {
+		t.Fatalf("data must be %q, %q found", "HELLO WORLD", data)
+	}
+	if err := old.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if fsys.pool.Len() != 0 {
+		t.Fatalf("pool must be empty, %d mappings found", fsys.pool.Len())
+	}
+}
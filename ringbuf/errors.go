@@ -0,0 +1,12 @@
+package ringbuf
+
+import "fmt"
+
+// ErrBadLength is the error which returns when the given capacity is not valid.
+var ErrBadLength = fmt.Errorf("ringbuf: bad length")
+
+// ErrEmpty is the error which returns when tries to consume more bytes than the buffer contains.
+var ErrEmpty = fmt.Errorf("ringbuf: not enough data")
+
+// ErrFull is the error which returns when tries to produce more bytes than the buffer has free space for.
+var ErrFull = fmt.Errorf("ringbuf: not enough space")
@@ -0,0 +1,221 @@
+// Package ringbuf provides the ring buffer which memory is mapped twice back-to-back,
+// so the data which wraps around the end of the buffer is still contiguous in the memory
+// and any part of the buffer up to its capacity may be accessed as a single slice.
+package ringbuf
+
+import (
+	"errors"
+	"io"
+	"unsafe"
+
+	"github.com/alexeymaximov/go-bio/internal/race"
+	"github.com/alexeymaximov/go-bio/mmap"
+)
+
+// placementAttempts is the number of attempts to place the views back-to-back,
+// the reserved address range may be taken concurrently by another mapping between the attempts.
+const placementAttempts = 16
+
+// Buffer is a ring buffer backed by the temporary file which is mapped twice back-to-back.
+// The buffer is not safe for concurrent use.
+type Buffer struct {
+	// store specifies the mapping which owns the temporary file.
+	store *mmap.Mapping
+	// views specifies the back-to-back mappings of the temporary file.
+	views [2]*mmap.Mapping
+	// memory specifies the memory of both views which is twice longer than the capacity.
+	memory []byte
+	// size specifies the capacity of the buffer.
+	size uint64
+	// read specifies the total number of the consumed bytes.
+	read uint64
+	// write specifies the total number of the produced bytes.
+	write uint64
+}
+
+// New returns a new empty ring buffer of the given capacity.
+// The capacity must be a multiple of mmap.Granularity, i.e. of the memory page size,
+// or of the allocation granularity on Windows.
+// The mmap.ErrNotSupported returns on platforms which do not provide the memory mapping at the fixed address.
+func New(size uintptr) (*Buffer, error) {
+	granularity := uintptr(mmap.Granularity())
+	if size == 0 || size%granularity != 0 || size > uintptr(mmap.MaxInt)/2 {
+		return nil, ErrBadLength
+	}
+	store, err := mmap.OpenTemp("", size)
+	if err != nil {
+		return nil, err
+	}
+	b := &Buffer{store: store, size: uint64(size)}
+	for i := 0; ; i++ {
+		err = b.place(size)
+		if err == nil {
+			return b, nil
+		}
+		if err != mmap.ErrAddressUnavailable || i == placementAttempts-1 {
+			_ = store.Close()
+			return nil, err
+		}
+	}
+}
+
+// place reserves the address range for both views and maps the temporary file at its halves.
+func (b *Buffer) place(size uintptr) error {
+	reserved, err := mmap.OpenAnonymous(2*size, mmap.ModeReadOnly, 0)
+	if err != nil {
+		return err
+	}
+	address := reserved.Address()
+	if err := reserved.Close(); err != nil {
+		return err
+	}
+	for i := range b.views {
+		view, err := mmap.Open(b.store.Fd(), 0, size, mmap.ModeReadWrite, mmap.FlagFixed, mmap.WithAddress(address+uintptr(i)*size))
+		if err != nil {
+			if i > 0 {
+				_ = b.views[0].Close()
+				b.views[0] = nil
+			}
+			return err
+		}
+		b.views[i] = view
+	}
+	b.memory = unsafe.Slice(&b.views[0].Memory()[0], 2*size)
+	return nil
+}
+
+// Cap returns the capacity of the buffer in bytes.
+func (b *Buffer) Cap() int {
+	return int(b.size)
+}
+
+// Len returns the number of bytes which are produced but not consumed yet.
+func (b *Buffer) Len() int {
+	return int(b.write - b.read)
+}
+
+// Free returns the number of bytes which may be produced before the buffer is full.
+func (b *Buffer) Free() int {
+	return int(b.size - (b.write - b.read))
+}
+
+// Peek returns the contiguous slice of the next n bytes which are not consumed yet without consuming them.
+// The slice is valid until the bytes are consumed and the buffer is closed.
+// The ErrEmpty returns if the buffer contains less than n bytes.
+func (b *Buffer) Peek(n int) ([]byte, error) {
+	if n < 0 || n > b.Len() {
+		return nil, ErrEmpty
+	}
+	start := b.read % b.size
+	b.annotate(start, uint64(n), race.ReadRange)
+	return b.memory[start : start+uint64(n) : start+uint64(n)], nil
+}
+
+// Discard consumes the next n bytes, so their space may be produced again.
+// The ErrEmpty returns if the buffer contains less than n bytes.
+func (b *Buffer) Discard(n int) error {
+	if n < 0 || n > b.Len() {
+		return ErrEmpty
+	}
+	b.read += uint64(n)
+	return nil
+}
+
+// Reserve returns the contiguous slice of the free space of n bytes to fill which follows the produced bytes.
+// The filled bytes become available for consuming only after Commit.
+// The ErrFull returns if the buffer has less than n free bytes.
+func (b *Buffer) Reserve(n int) ([]byte, error) {
+	if n < 0 || n > b.Free() {
+		return nil, ErrFull
+	}
+	start := b.write % b.size
+	b.annotate(start, uint64(n), race.WriteRange)
+	return b.memory[start : start+uint64(n) : start+uint64(n)], nil
+}
+
+// Commit produces the next n bytes of the free space which are filled after Reserve.
+// The ErrFull returns if the buffer has less than n free bytes.
+func (b *Buffer) Commit(n int) error {
+	if n < 0 || n > b.Free() {
+		return ErrFull
+	}
+	b.write += uint64(n)
+	return nil
+}
+
+// Read consumes up to len(buf) bytes into the given buffer.
+// The io.EOF error will be returned if the buffer is empty.
+// Read implements the io.Reader interface.
+func (b *Buffer) Read(buf []byte) (int, error) {
+	if b.Len() == 0 {
+		if len(buf) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	n := len(buf)
+	if n > b.Len() {
+		n = b.Len()
+	}
+	data, _ := b.Peek(n)
+	copy(buf, data)
+	b.read += uint64(n)
+	return n, nil
+}
+
+// Write produces len(buf) bytes from the given buffer.
+// The buffer can not grow, so if there are not enough free space to write all given bytes
+// the fitting part is written and the ErrFull error will be returned.
+// Write implements the io.Writer interface.
+func (b *Buffer) Write(buf []byte) (int, error) {
+	n := len(buf)
+	if n > b.Free() {
+		n = b.Free()
+	}
+	space, _ := b.Reserve(n)
+	copy(space, buf)
+	b.write += uint64(n)
+	if n < len(buf) {
+		return n, ErrFull
+	}
+	return n, nil
+}
+
+// Reset discards all bytes which are not consumed yet.
+func (b *Buffer) Reset() {
+	b.read = b.write
+}
+
+// annotate annotates the access to the given range of the doubled memory by the given annotation function
+// against the addresses of the first view, so the accesses to the same bytes through both views are tracked together.
+func (b *Buffer) annotate(start, length uint64, access func(address, length uintptr)) {
+	if !race.Enabled || length == 0 {
+		return
+	}
+	address := b.views[0].Address()
+	if end := start + length; end > b.size {
+		access(address+uintptr(start), uintptr(b.size-start))
+		access(address, uintptr(end-b.size))
+		return
+	}
+	access(address+uintptr(start), uintptr(length))
+}
+
+// Close closes both views and the temporary file, so the buffer must not be used after that.
+// Close implements the io.Closer interface.
+func (b *Buffer) Close() error {
+	var errs []error
+	for i, view := range b.views {
+		if view != nil {
+			if err := view.Close(); err != nil {
+				errs = append(errs, err)
+			}
+			b.views[i] = nil
+		}
+	}
+	if err := b.store.Close(); err != nil && err != mmap.ErrClosed {
+		errs = append(errs, err)
+	}
+	b.memory = nil
+	return errors.Join(errs...)
+}
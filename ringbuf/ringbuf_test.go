@@ -0,0 +1,105 @@
+package ringbuf
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/alexeymaximov/go-bio/mmap"
+)
+
+// openTestBuffer opens and returns a new ring buffer of the mapping granularity.
+func openTestBuffer(t *testing.T) *Buffer {
+	b, err := New(uintptr(mmap.Granularity()))
+	if err == mmap.ErrNotSupported {
+		t.Skip("double mapping is not supported")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+//------------------------------------------- TEST CASES ---------------------------------------------------------------
+
+// TestWrap tests the data which wraps around the end of the buffer.
+// CASE 1: The wrapped data MUST be contiguous.
+// CASE 2: The bytes written through the second view MUST be visible through the first one.
+func TestWrap(t *testing.T) {
+	b := openTestBuffer(t)
+	defer b.Close()
+	size := b.Cap()
+	if _, err := b.Write(make([]byte, size-3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Discard(size - 3); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("HELLO WORLD")
+	if _, err := b.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := b.Peek(len(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, data) {
+		t.Fatalf("data must be %q, %q found", data, buf)
+	}
+	if !bytes.Equal(b.memory[:len(data)-3], data[3:]) {
+		t.Fatalf("wrapped data must be %q, %q found", data[3:], b.memory[:len(data)-3])
+	}
+	out, err := io.ReadAll(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, data) {
+		t.Fatalf("read data must be %q, %q found", data, out)
+	}
+}
+
+// TestBounds tests the bounds of the buffer.
+// CASE 1: The partial write MUST return ErrFull.
+// CASE 2: The reservation and the peeking beyond the bounds MUST fail.
+// CASE 3: The empty buffer MUST return io.EOF.
+func TestBounds(t *testing.T) {
+	if _, err := New(1); err != ErrBadLength {
+		t.Fatalf("expected ErrBadLength, [%v] error found", err)
+	}
+	b := openTestBuffer(t)
+	defer b.Close()
+	size := b.Cap()
+	if n, err := b.Write(make([]byte, size+1)); n != size || err != ErrFull {
+		t.Fatalf("expected %d bytes and ErrFull, %d bytes and [%v] error found", size, n, err)
+	}
+	if _, err := b.Reserve(1); err != ErrFull {
+		t.Fatalf("expected ErrFull, [%v] error found", err)
+	}
+	if _, err := b.Peek(size + 1); err != ErrEmpty {
+		t.Fatalf("expected ErrEmpty, [%v] error found", err)
+	}
+	b.Reset()
+	if b.Len() != 0 || b.Free() != size {
+		t.Fatalf("buffer must be empty, %d bytes found", b.Len())
+	}
+	if _, err := b.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("expected io.EOF, [%v] error found", err)
+	}
+	space, err := b.Reserve(size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	space[size-1] = 1
+	if err := b.Commit(size); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Discard(size + 1); err != ErrEmpty {
+		t.Fatalf("expected ErrEmpty, [%v] error found", err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,224 @@
+package segment
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// arenaMagic is the signature of the arena ("bioarena" in the little-endian byte order).
+const arenaMagic = 0x616e6572616f6962
+
+// Layout of the arena metadata which is encoded in the little-endian byte order.
+const (
+	// arenaMagicOffset is the offset of the signature in the arena header.
+	arenaMagicOffset = 0
+	// arenaTopOffset is the offset of the bump pointer in the arena header.
+	arenaTopOffset = 8
+	// arenaFreeOffset is the offset of the head of the free list in the arena header.
+	arenaFreeOffset = 16
+	// arenaHeaderSize is the size of the arena header.
+	arenaHeaderSize = 32
+	// blockCapacityOffset is the offset of the capacity in the block header.
+	blockCapacityOffset = 0
+	// blockStateOffset is the offset of the length of the allocated block or of the link of the free one
+	// in the block header.
+	blockStateOffset = 8
+	// blockHeaderSize is the size of the block header which precedes the block.
+	blockHeaderSize = 16
+	// blockAlign is the minimal alignment of the blocks.
+	blockAlign = 8
+	// blockFree is the flag of the free block which is combined with the offset of the next free block header.
+	blockFree = 1 << 63
+)
+
+// Arena is the allocator which hands out the blocks of the segment as the child segments.
+// The blocks are taken from the list of the freed ones by the first fit or bumped from the unused space.
+// All the metadata of the arena, i.e. the header at start of the segment and the header of each block,
+// resides within the segment itself, so the arena survives the remapping and is reopened by OpenArena.
+// The arena is not safe for concurrent use.
+type Arena struct {
+	// seg specifies the segment which this arena allocates from.
+	seg *Segment
+}
+
+// NewArena formats the given segment as the empty arena and returns it.
+// The ErrOutOfBounds returns if the segment is too small for the arena header.
+func NewArena(seg *Segment) (*Arena, error) {
+	if seg.size() < arenaHeaderSize {
+		return nil, ErrOutOfBounds
+	}
+	a := &Arena{seg: seg}
+	if err := a.put(arenaTopOffset, arenaHeaderSize); err != nil {
+		return nil, err
+	}
+	if err := a.put(arenaFreeOffset, 0); err != nil {
+		return nil, err
+	}
+	if err := a.put(arenaMagicOffset, arenaMagic); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// OpenArena returns the arena which was formatted in the given segment by NewArena.
+// The ErrBadValue returns if the segment does not contain the valid arena.
+func OpenArena(seg *Segment) (*Arena, error) {
+	a := &Arena{seg: seg}
+	magic, err := a.get(arenaMagicOffset)
+	if err != nil || magic != arenaMagic {
+		return nil, ErrBadValue
+	}
+	top, err := a.get(arenaTopOffset)
+	if err != nil || top < arenaHeaderSize || top > uint64(seg.size()) {
+		return nil, ErrBadValue
+	}
+	return a, nil
+}
+
+// Alloc allocates the block of the given non-zero size which offset from start of the segment is aligned
+// by the given power of two (at least 8) and returns it as the child segment, see Slice.
+// The block is not zeroed. The offset of the block is persistent, see Block and Free.
+// The ErrBadValue returns if the size or the alignment is not valid,
+// the ErrNoSpace returns if the arena has not enough space.
+func (a *Arena) Alloc(size, align uintptr) (*Segment, error) {
+	if size == 0 || uint64(size) > math.MaxInt64-blockAlign || align == 0 || align&(align-1) != 0 {
+		return nil, ErrBadValue
+	}
+	if align < blockAlign {
+		align = blockAlign
+	}
+	capacity := (uint64(size) + blockAlign - 1) &^ (blockAlign - 1)
+	// The first fit of the free blocks is reused.
+	var prev uint64
+	next, err := a.get(arenaFreeOffset)
+	if err != nil {
+		return nil, err
+	}
+	for next != 0 {
+		header := next
+		blockCapacity, state, err := a.header(header)
+		if err != nil {
+			return nil, err
+		}
+		next = state &^ blockFree
+		if blockCapacity < capacity || (header+blockHeaderSize)%uint64(align) != 0 {
+			prev = header
+			continue
+		}
+		if prev == 0 {
+			err = a.put(arenaFreeOffset, next)
+		} else {
+			err = a.put(int64(prev+blockStateOffset), blockFree|next)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return a.use(header, uint64(size))
+	}
+	// The new block is bumped from the unused space.
+	top, err := a.get(arenaTopOffset)
+	if err != nil {
+		return nil, err
+	}
+	start := (top + blockHeaderSize + uint64(align) - 1) &^ (uint64(align) - 1)
+	if start < top || start > uint64(a.seg.size()) || capacity > uint64(a.seg.size())-start {
+		return nil, ErrNoSpace
+	}
+	header := start - blockHeaderSize
+	if err := a.put(int64(header+blockCapacityOffset), capacity); err != nil {
+		return nil, err
+	}
+	if err := a.put(arenaTopOffset, start+capacity); err != nil {
+		return nil, err
+	}
+	return a.use(header, uint64(size))
+}
+
+// Free releases the block at the given offset which was allocated by Alloc, so it may be reused.
+// The ErrBadOffset returns if there is no allocated block at the given offset.
+func (a *Arena) Free(offset int64) error {
+	header, _, err := a.block(offset)
+	if err != nil {
+		return err
+	}
+	free, err := a.get(arenaFreeOffset)
+	if err != nil {
+		return err
+	}
+	if err := a.put(int64(header+blockStateOffset), blockFree|free); err != nil {
+		return err
+	}
+	return a.put(arenaFreeOffset, header)
+}
+
+// Block returns the block at the given offset which was allocated by Alloc as the child segment,
+// e.g. after the arena is reopened.
+// The ErrBadOffset returns if there is no allocated block at the given offset.
+func (a *Arena) Block(offset int64) (*Segment, error) {
+	_, length, err := a.block(offset)
+	if err != nil {
+		return nil, err
+	}
+	return a.seg.Slice(offset, uintptr(length))
+}
+
+// block returns the offset of the header and the length of the allocated block at the given offset.
+func (a *Arena) block(offset int64) (uint64, uint64, error) {
+	top, err := a.get(arenaTopOffset)
+	if err != nil {
+		return 0, 0, err
+	}
+	start := offset - a.seg.offset
+	if offset < a.seg.offset || start < arenaHeaderSize+blockHeaderSize || uint64(start) >= top || start%blockAlign != 0 {
+		return 0, 0, ErrBadOffset
+	}
+	header := uint64(start) - blockHeaderSize
+	capacity, state, err := a.header(header)
+	if err != nil {
+		return 0, 0, err
+	}
+	if state&blockFree != 0 || state > capacity || capacity > top-uint64(start) {
+		return 0, 0, ErrBadOffset
+	}
+	return header, state, nil
+}
+
+// use marks the block of the given header as allocated of the given length and returns it as the child segment.
+func (a *Arena) use(header, length uint64) (*Segment, error) {
+	if err := a.put(int64(header+blockStateOffset), length); err != nil {
+		return nil, err
+	}
+	return a.seg.Slice(a.seg.offset+int64(header+blockHeaderSize), uintptr(length))
+}
+
+// header returns the capacity and the state of the block of the given header.
+func (a *Arena) header(header uint64) (uint64, uint64, error) {
+	capacity, err := a.get(int64(header + blockCapacityOffset))
+	if err != nil {
+		return 0, 0, err
+	}
+	state, err := a.get(int64(header + blockStateOffset))
+	if err != nil {
+		return 0, 0, err
+	}
+	return capacity, state, nil
+}
+
+// get returns the metadata word at the given offset from start of the segment.
+func (a *Arena) get(offset int64) (uint64, error) {
+	data, err := a.seg.read(offset, Uint64Size)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(data), nil
+}
+
+// put writes the metadata word at the given offset from start of the segment.
+func (a *Arena) put(offset int64, v uint64) error {
+	data, err := a.seg.write(offset, Uint64Size)
+	if err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint64(data, v)
+	return nil
+}
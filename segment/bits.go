@@ -0,0 +1,117 @@
+package segment
+
+import "math"
+
+// BitOrder is the order of the bits within the bytes.
+type BitOrder int
+
+const (
+	// LSBFirst numbers the bits from the least significant bit of the byte,
+	// the first bit of the field is the least significant bit of its value, e.g. in DEFLATE.
+	LSBFirst BitOrder = iota
+	// MSBFirst numbers the bits from the most significant bit of the byte,
+	// the first bit of the field is the most significant bit of its value, e.g. in JPEG and the network protocols.
+	MSBFirst
+)
+
+// BitOrder returns the order of the bits which are accessed by this segment.
+func (seg *Segment) BitOrder() BitOrder {
+	return seg.bitOrder
+}
+
+// SetBitOrder sets the order of the bits which are accessed by this segment, LSBFirst is the default.
+func (seg *Segment) SetBitOrder(order BitOrder) {
+	seg.bitOrder = order
+}
+
+// Bit returns the bit of the given number which is counted from start of the byte at the given offset
+// from this segment, so the bit may reside in the following bytes.
+func (seg *Segment) Bit(offset int64, bit uint) (bool, error) {
+	v, err := seg.Bits(offset, bit, 1)
+	return v != 0, err
+}
+
+// SetBit sets the bit of the given number which is counted like by Bit to the given value.
+func (seg *Segment) SetBit(offset int64, bit uint, v bool) error {
+	var u uint64
+	if v {
+		u = 1
+	}
+	return seg.SetBits(offset, bit, 1, u)
+}
+
+// Bits returns the bit field of the given width from 1 to 64 which starts at the given bit
+// counted from start of the byte at the given offset from this segment in the bit order of this segment.
+// The field may span across the bytes.
+func (seg *Segment) Bits(offset int64, firstBit, width uint) (uint64, error) {
+	return seg.bits(offset, firstBit, width, seg.bitOrder)
+}
+
+// bits returns the bit field like Bits in the given bit order.
+func (seg *Segment) bits(offset int64, firstBit, width uint, order BitOrder) (uint64, error) {
+	data, shift, err := seg.bitSpan(offset, firstBit, width, false)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for i := uint(0); i < width; i++ {
+		pos := shift + i
+		if order == MSBFirst {
+			v = v<<1 | uint64(data[pos/8]>>(7-pos%8)&1)
+		} else {
+			v |= uint64(data[pos/8]>>(pos%8)&1) << i
+		}
+	}
+	return v, nil
+}
+
+// SetBits sets the bit field which is addressed like by Bits to the given value truncated to the width.
+// The other bits of the spanned bytes are left intact.
+func (seg *Segment) SetBits(offset int64, firstBit, width uint, v uint64) error {
+	return seg.setBits(offset, firstBit, width, v, seg.bitOrder)
+}
+
+// setBits sets the bit field like SetBits in the given bit order.
+func (seg *Segment) setBits(offset int64, firstBit, width uint, v uint64, order BitOrder) error {
+	data, shift, err := seg.bitSpan(offset, firstBit, width, true)
+	if err != nil {
+		return err
+	}
+	for i := uint(0); i < width; i++ {
+		pos := shift + i
+		var bit uint64
+		var mask byte
+		if order == MSBFirst {
+			bit = v >> (width - 1 - i) & 1
+			mask = 1 << (7 - pos%8)
+		} else {
+			bit = v >> i & 1
+			mask = 1 << (pos % 8)
+		}
+		if bit != 0 {
+			data[pos/8] |= mask
+		} else {
+			data[pos/8] &^= mask
+		}
+	}
+	return nil
+}
+
+// bitSpan returns the bytes which are spanned by the given bit field and the shift of its first bit within them.
+func (seg *Segment) bitSpan(offset int64, firstBit, width uint, write bool) ([]byte, uint, error) {
+	if width == 0 || width > 64 {
+		return nil, 0, ErrBadValue
+	}
+	if offset < seg.offset || uint64(firstBit/8) > math.MaxInt64-uint64(offset-seg.offset) {
+		return nil, 0, ErrOutOfBounds
+	}
+	offset += int64(firstBit/8) - seg.offset
+	shift := firstBit % 8
+	length := int64((shift + width + 7) / 8)
+	if write {
+		data, err := seg.write(offset, length)
+		return data, shift, err
+	}
+	data, err := seg.read(offset, length)
+	return data, shift, err
+}
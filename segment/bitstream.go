@@ -0,0 +1,140 @@
+package segment
+
+import "io"
+
+// bitCursor is the position of the bit stream.
+type bitCursor struct {
+	// seg specifies the segment of the stream.
+	seg *Segment
+	// offset specifies the offset of the next whole byte from start of the segment.
+	offset int64
+	// bit specifies the number of the next bit within the byte at offset.
+	bit uint
+	// order specifies the bit order of the stream.
+	order BitOrder
+}
+
+// Offset returns the offset of the byte which contains the next bit.
+func (c *bitCursor) Offset() int64 {
+	return c.offset
+}
+
+// Bit returns the number of the next bit within the byte at Offset.
+func (c *bitCursor) Bit() uint {
+	return c.bit
+}
+
+// advance advances the position by the given number of bits.
+func (c *bitCursor) advance(width uint) {
+	c.bit += width
+	c.offset += int64(c.bit / 8)
+	c.bit %= 8
+}
+
+// check returns io.EOF if there are no bits remaining, ErrOutOfBounds if there are fewer bits than the given width
+// or ErrBadValue if the given width is not from 1 to 64.
+func (c *bitCursor) check(width uint) error {
+	if width == 0 || width > 64 {
+		return ErrBadValue
+	}
+	end := c.seg.offset + c.seg.size()
+	if c.offset >= end {
+		return io.EOF
+	}
+	if uint64(end-c.offset)*8-uint64(c.bit) < uint64(width) {
+		return ErrOutOfBounds
+	}
+	return nil
+}
+
+// BitReader reads the bit fields of arbitrary width sequentially from the segment.
+type BitReader struct {
+	bitCursor
+}
+
+// NewBitReader returns a new bit reader of the given segment which starts at the byte at the given offset
+// and reads the bits in the given order.
+func NewBitReader(seg *Segment, offset int64, order BitOrder) *BitReader {
+	return &BitReader{bitCursor{seg: seg, offset: offset, order: order}}
+}
+
+// ReadBits reads the bit field of the given width from 1 to 64.
+// In the LSBFirst order the first bit read is the least significant bit of the value,
+// in the MSBFirst order it is the most significant one.
+// The io.EOF error returns if there are no bits remaining,
+// the ErrOutOfBounds returns and the position is not advanced if the field is partially beyond the segment.
+func (r *BitReader) ReadBits(width uint) (uint64, error) {
+	if err := r.check(width); err != nil {
+		return 0, err
+	}
+	v, err := r.seg.bits(r.offset, r.bit, width, r.order)
+	if err != nil {
+		return 0, err
+	}
+	r.advance(width)
+	return v, nil
+}
+
+// ReadBit reads the single bit.
+func (r *BitReader) ReadBit() (bool, error) {
+	v, err := r.ReadBits(1)
+	return v != 0, err
+}
+
+// Skip skips the given number of bits.
+func (r *BitReader) Skip(n uint) {
+	r.advance(n)
+}
+
+// Align skips the rest of the current byte, so the next bit is the first bit of the next byte.
+func (r *BitReader) Align() {
+	if r.bit != 0 {
+		r.advance(8 - r.bit)
+	}
+}
+
+// BitWriter writes the bit fields of arbitrary width sequentially to the segment.
+// The bits are written in place, so there is nothing to flush.
+type BitWriter struct {
+	bitCursor
+}
+
+// NewBitWriter returns a new bit writer of the given segment which starts at the byte at the given offset
+// and writes the bits in the given order.
+func NewBitWriter(seg *Segment, offset int64, order BitOrder) *BitWriter {
+	return &BitWriter{bitCursor{seg: seg, offset: offset, order: order}}
+}
+
+// WriteBits writes the given value as the bit field of the given width from 1 to 64 in the order of the writer,
+// the value is truncated to the width.
+// The ErrOutOfBounds returns and nothing is written if the field does not fit the segment.
+func (w *BitWriter) WriteBits(v uint64, width uint) error {
+	if err := w.check(width); err != nil {
+		if err == io.EOF {
+			err = ErrOutOfBounds
+		}
+		return err
+	}
+	if err := w.seg.setBits(w.offset, w.bit, width, v, w.order); err != nil {
+		return err
+	}
+	w.advance(width)
+	return nil
+}
+
+// WriteBit writes the single bit.
+func (w *BitWriter) WriteBit(v bool) error {
+	var u uint64
+	if v {
+		u = 1
+	}
+	return w.WriteBits(u, 1)
+}
+
+// Align pads the rest of the current byte with zero bits, so the next bit is the first bit of the next byte.
+func (w *BitWriter) Align() error {
+	if w.bit == 0 {
+		return nil
+	}
+	return w.WriteBits(0, 8-w.bit)
+}
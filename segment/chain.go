@@ -0,0 +1,73 @@
+package segment
+
+import "sort"
+
+// chain is the list of the byte slices which are chained into the contiguous offset space.
+type chain struct {
+	// slices specifies the non-empty chained slices.
+	slices [][]byte
+	// ends specifies the offset of the end of each slice from start of the chain.
+	ends []int64
+}
+
+// NewChain returns a new data segment of the given byte slices which are chained into the contiguous offset space,
+// so the data split across several mappings, e.g. the chunks of the file, may be accessed as one segment.
+// The first byte of each slice follows the last byte of the previous one, the empty slices are skipped.
+// The data is read, written and filled across the seams between the slices, e.g. by the Scan methods,
+// ReadAt, WriteAt and the cursor, whereas the methods which hand out the memory or modify the value in place,
+// e.g. the typed pointers, Bytes, View and the Put methods, require the value to reside within a single slice,
+// the ErrSeam returns or the panic occurs otherwise.
+// The multi-byte values are encoded in the little-endian byte order, see SetByteOrder.
+func NewChain(offset int64, slices ...[]byte) *Segment {
+	c := &chain{}
+	var end int64
+	for _, s := range slices {
+		if len(s) == 0 {
+			continue
+		}
+		end += int64(len(s))
+		c.slices = append(c.slices, s)
+		c.ends = append(c.ends, end)
+	}
+	seg := New(offset, nil)
+	switch len(c.slices) {
+	case 0:
+	case 1:
+		seg.data = c.slices[0]
+	default:
+		seg.chain = c
+	}
+	return seg
+}
+
+// size returns the length of this chain in bytes.
+func (c *chain) size() int64 {
+	return c.ends[len(c.ends)-1]
+}
+
+// locate returns the index of the slice which contains the byte at the given offset from start of this chain
+// and the offset of the byte within that slice.
+func (c *chain) locate(offset int64) (int, int64) {
+	i := sort.Search(len(c.ends), func(i int) bool {
+		return c.ends[i] > offset
+	})
+	return i, offset - c.ends[i] + int64(len(c.slices[i]))
+}
+
+// pieces returns the parts of the slices which hold the given number of bytes at the given offset
+// from start of this chain, the range must be in bounds.
+func (c *chain) pieces(offset, length int64) [][]byte {
+	if length == 0 {
+		return nil
+	}
+	var pieces [][]byte
+	for i, at := c.locate(offset); length > 0; i, at = i+1, 0 {
+		n := int64(len(c.slices[i])) - at
+		if n > length {
+			n = length
+		}
+		pieces = append(pieces, c.slices[i][at:at+n:at+n])
+		length -= n
+	}
+	return pieces
+}
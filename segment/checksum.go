@@ -0,0 +1,40 @@
+package segment
+
+import (
+	"hash"
+	"hash/crc32"
+)
+
+// hashChunk is the number of bytes which are fed to the hash at once.
+const hashChunk = 1 << 20
+
+// CRC32 returns the IEEE CRC-32 checksum of the given number of bytes at the given offset from this segment.
+// The ErrOutOfBounds returns if the range is out of the available bounds.
+func (seg *Segment) CRC32(offset int64, length uintptr) (uint32, error) {
+	h := crc32.NewIEEE()
+	if err := seg.Hash(offset, length, h); err != nil {
+		return 0, err
+	}
+	return h.Sum32(), nil
+}
+
+// Hash feeds the given number of bytes at the given offset from this segment to the given hash in large chunks
+// directly from the segment memory, so the pages and records of the mappings and the transactions
+// are checksummed uniformly. The hash is not reset, so the ranges may be hashed successively.
+// The ErrOutOfBounds returns and nothing is fed if the range is out of the available bounds.
+func (seg *Segment) Hash(offset int64, length uintptr, h hash.Hash) error {
+	data, err := seg.bytes(offset, length, false)
+	if err != nil {
+		return err
+	}
+	for len(data) > 0 {
+		n := len(data)
+		if n > hashChunk {
+			n = hashChunk
+		}
+		// The hash.Hash writer never returns an error.
+		_, _ = h.Write(data[:n])
+		data = data[n:]
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package segment
+
+import "bytes"
+
+// Equal reports whether the bytes at the given offset from this segment are equal to the given bytes.
+// The false returns if the range is out of the available bounds.
+func (seg *Segment) Equal(offset int64, b []byte) bool {
+	data, err := seg.bytes(offset, uintptr(len(b)), false)
+	return err == nil && bytes.Equal(data, b)
+}
+
+// Compare compares the len(b) bytes at the given offset from this segment with the given bytes lexicographically
+// and returns the result like bytes.Compare and the offset of the first divergent byte
+// or the offset of the end of the range if they are equal.
+// The ErrOutOfBounds returns if the range is out of the available bounds.
+func (seg *Segment) Compare(offset int64, b []byte) (int, int64, error) {
+	data, err := seg.bytes(offset, uintptr(len(b)), false)
+	if err != nil {
+		return 0, 0, err
+	}
+	i := 0
+	// The common prefix is skipped by the chunks which are compared by the optimized bytes.Equal.
+	for chunk := 64; i+chunk <= len(b) && bytes.Equal(data[i:i+chunk], b[i:i+chunk]); {
+		i += chunk
+	}
+	for i < len(b) && data[i] == b[i] {
+		i++
+	}
+	if i == len(b) {
+		return 0, offset + int64(i), nil
+	}
+	if data[i] < b[i] {
+		return -1, offset + int64(i), nil
+	}
+	return 1, offset + int64(i), nil
+}
@@ -0,0 +1,141 @@
+package segment
+
+import (
+	"io"
+	"math"
+)
+
+// Cursor is a stream-oriented view of the segment with its own position,
+// so the stream-style parsers may be written without threading the offsets through every call.
+// The position is the offset in the same space as the offsets of the segment.
+// The cursor is not safe for concurrent use.
+type Cursor struct {
+	// seg specifies the segment which this cursor belongs to.
+	seg *Segment
+	// position specifies the current position of this cursor.
+	position int64
+}
+
+// Cursor returns a new cursor of this segment positioned at the given offset.
+func (seg *Segment) Cursor(start int64) *Cursor {
+	return &Cursor{seg: seg, position: start}
+}
+
+// Position returns the current position of this cursor.
+func (c *Cursor) Position() int64 {
+	return c.position
+}
+
+// end returns the offset of the end of the segment.
+func (c *Cursor) end() int64 {
+	return c.seg.offset + c.seg.size()
+}
+
+// Read reads up to len(buf) bytes from the current position and advances it.
+// The io.EOF error will be returned if the position is at or beyond the end of the segment
+// and the ErrOutOfBounds error will be returned if it is before the start.
+// Read implements the io.Reader interface.
+func (c *Cursor) Read(buf []byte) (int, error) {
+	if c.position < c.seg.offset {
+		return 0, ErrOutOfBounds
+	}
+	if c.position >= c.end() {
+		return 0, io.EOF
+	}
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	n := int64(len(buf))
+	if rest := c.end() - c.position; n > rest {
+		n = rest
+	}
+	data, err := c.seg.read(c.position-c.seg.offset, n)
+	if err != nil {
+		return 0, err
+	}
+	c.position += n
+	return copy(buf, data), nil
+}
+
+// ReadByte reads and returns the byte at the current position and advances it.
+// ReadByte implements the io.ByteReader interface.
+func (c *Cursor) ReadByte() (byte, error) {
+	var buf [1]byte
+	if _, err := c.Read(buf[:]); err != nil {
+		return 0, err
+	}
+	return buf[0], nil
+}
+
+// Write writes len(buf) bytes at the current position and advances it.
+// The segment can not grow, so if there are not enough space to write all given bytes
+// the fitting part is written and the ErrOutOfBounds error will be returned.
+// Write implements the io.Writer interface.
+func (c *Cursor) Write(buf []byte) (int, error) {
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	if c.position < c.seg.offset || c.position >= c.end() {
+		return 0, ErrOutOfBounds
+	}
+	n := int64(len(buf))
+	if rest := c.end() - c.position; n > rest {
+		n = rest
+	}
+	if _, err := c.seg.WriteAt(buf[:n], c.position); err != nil {
+		return 0, err
+	}
+	c.position += n
+	if n < int64(len(buf)) {
+		return int(n), ErrOutOfBounds
+	}
+	return int(n), nil
+}
+
+// WriteByte writes the given byte at the current position and advances it.
+// WriteByte implements the io.ByteWriter interface.
+func (c *Cursor) WriteByte(b byte) error {
+	_, err := c.Write([]byte{b})
+	return err
+}
+
+// Seek sets the position for the next Read or Write to the given offset,
+// interpreted according to whence: io.SeekStart, io.SeekCurrent or io.SeekEnd.
+// The io.SeekStart offset is in the same space as the offsets of the segment.
+// The position may be set beyond the end of the segment,
+// but the ErrBadOffset error will be returned for the negative one.
+// Seek implements the io.Seeker interface.
+func (c *Cursor) Seek(offset int64, whence int) (int64, error) {
+	var base int64
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		base = c.position
+	case io.SeekEnd:
+		base = c.end()
+	default:
+		return 0, ErrBadOffset
+	}
+	if (offset > 0 && base > math.MaxInt64-offset) || base+offset < 0 {
+		return 0, ErrBadOffset
+	}
+	c.position = base + offset
+	return c.position, nil
+}
+
+// Skip advances the current position by the given number of bytes.
+func (c *Cursor) Skip(n int64) error {
+	_, err := c.Seek(n, io.SeekCurrent)
+	return err
+}
+
+// Align advances the current position to the nearest multiple of the given positive alignment.
+func (c *Cursor) Align(alignment int64) error {
+	if alignment <= 0 {
+		return ErrBadOffset
+	}
+	if rem := c.position % alignment; rem != 0 {
+		return c.Skip(alignment - rem)
+	}
+	return nil
+}
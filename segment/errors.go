@@ -10,3 +10,44 @@ var ErrOutOfBounds = fmt.Errorf("segment: out of bounds")
 
 // Fault is the access violation error.
 var Fault = fmt.Errorf("segmentation fault")
+
+// ErrOverflow is the error which returns when the encoded value overflows the 64-bit integer.
+var ErrOverflow = fmt.Errorf("segment: value overflow")
+
+// ErrMisaligned is the error which returns when the given offset is not aligned as required by the value type.
+var ErrMisaligned = fmt.Errorf("segment: misaligned offset")
+
+// ErrBadOffset is the error which returns when the given position or alignment is not valid.
+var ErrBadOffset = fmt.Errorf("segment: bad offset")
+
+// FaultError is the access violation error which describes the requested value and the bounds of the segment.
+// It matches Fault.
+type FaultError struct {
+	// Offset specifies the requested offset.
+	Offset int64
+	// Length specifies the requested length in bytes.
+	Length uintptr
+	// SegmentOffset specifies the base offset of the segment.
+	SegmentOffset int64
+	// SegmentLen specifies the length of the segment in bytes.
+	SegmentLen int
+}
+
+// Error returns the description of the access violation.
+// Error implements the error interface.
+func (err *FaultError) Error() string {
+	return fmt.Sprintf("%v: %d bytes at offset %d are out of the segment of %d bytes at offset %d",
+		Fault, err.Length, err.Offset, err.SegmentLen, err.SegmentOffset)
+}
+
+// Unwrap returns Fault.
+func (err *FaultError) Unwrap() error {
+	return Fault
+}
+
+// ErrSeam is the error which returns when the value which must reside in a single slice of the chained segment
+// crosses the seam between the slices, see NewChain.
+var ErrSeam = fmt.Errorf("segment: value crosses the seam")
+
+// ErrNoSpace is the error which returns when the arena has not enough space for the allocation.
+var ErrNoSpace = fmt.Errorf("segment: no space left in arena")
@@ -0,0 +1,85 @@
+package segment
+
+import "math"
+
+// Fixed32 reads the signed 32-bit fixed-point number with the given number of fractional bits
+// at the given offset from this segment and returns its value, i.e. the integer scaled by 2^-fracBits.
+// The number is encoded in the byte order of this segment.
+// The ErrBadValue returns if the number of fractional bits is greater than 31.
+func (seg *Segment) Fixed32(offset int64, fracBits uint) (float64, error) {
+	if fracBits > 31 {
+		return 0, ErrBadValue
+	}
+	if offset < seg.offset {
+		return 0, ErrOutOfBounds
+	}
+	data, err := seg.read(offset-seg.offset, Int32Size)
+	if err != nil {
+		return 0, err
+	}
+	return math.Ldexp(float64(int32(seg.order.Uint32(data))), -int(fracBits)), nil
+}
+
+// Fixed64 reads the signed 64-bit fixed-point number with the given number of fractional bits
+// at the given offset from this segment and returns its value, see Fixed32.
+// The value is rounded to the nearest float64 if the integer has more than 53 significant bits.
+// The ErrBadValue returns if the number of fractional bits is greater than 63.
+func (seg *Segment) Fixed64(offset int64, fracBits uint) (float64, error) {
+	if fracBits > 63 {
+		return 0, ErrBadValue
+	}
+	if offset < seg.offset {
+		return 0, ErrOutOfBounds
+	}
+	data, err := seg.read(offset-seg.offset, Int64Size)
+	if err != nil {
+		return 0, err
+	}
+	return math.Ldexp(float64(int64(seg.order.Uint64(data))), -int(fracBits)), nil
+}
+
+// PutFixed32 writes the given value as the signed 32-bit fixed-point number with the given number of fractional bits
+// at the given offset to this segment, the value is rounded to the nearest representable number.
+// The ErrBadValue returns if the number of fractional bits is greater than 31,
+// the ErrOverflow returns and nothing is written if the value is not representable.
+func (seg *Segment) PutFixed32(offset int64, v float64, fracBits uint) error {
+	if fracBits > 31 {
+		return ErrBadValue
+	}
+	raw := math.Round(math.Ldexp(v, int(fracBits)))
+	if math.IsNaN(raw) || raw < math.MinInt32 || raw > math.MaxInt32 {
+		return ErrOverflow
+	}
+	if offset < seg.offset {
+		return ErrOutOfBounds
+	}
+	data, err := seg.write(offset-seg.offset, Int32Size)
+	if err != nil {
+		return err
+	}
+	seg.order.PutUint32(data, uint32(int32(raw)))
+	return nil
+}
+
+// PutFixed64 writes the given value as the signed 64-bit fixed-point number with the given number of fractional bits
+// at the given offset to this segment, see PutFixed32.
+// The ErrBadValue returns if the number of fractional bits is greater than 63,
+// the ErrOverflow returns and nothing is written if the value is not representable.
+func (seg *Segment) PutFixed64(offset int64, v float64, fracBits uint) error {
+	if fracBits > 63 {
+		return ErrBadValue
+	}
+	raw := math.Round(math.Ldexp(v, int(fracBits)))
+	if math.IsNaN(raw) || raw < math.MinInt64 || raw >= -math.MinInt64 {
+		return ErrOverflow
+	}
+	if offset < seg.offset {
+		return ErrOutOfBounds
+	}
+	data, err := seg.write(offset-seg.offset, Int64Size)
+	if err != nil {
+		return err
+	}
+	seg.order.PutUint64(data, uint64(int64(raw)))
+	return nil
+}
@@ -0,0 +1,102 @@
+package segment
+
+import (
+	"encoding/binary"
+	"reflect"
+	"unsafe"
+)
+
+// Number is the constraint of the fixed-size numeric types which are accessed by Get and Put.
+type Number interface {
+	~int8 | ~int16 | ~int32 | ~int64 |
+		~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64 |
+		~complex64 | ~complex128
+}
+
+// Get returns the value of the given numeric type at the given offset from the given segment.
+// The value is decoded in the byte order of the segment like by ScanUint, ScanFloat and ScanComplex.
+func Get[T Number](seg *Segment, offset int64) (T, error) {
+	var v T
+	size := int64(unsafe.Sizeof(v))
+	if offset < seg.offset {
+		return v, ErrOutOfBounds
+	}
+	data, err := seg.read(offset-seg.offset, size)
+	if err != nil {
+		return v, err
+	}
+	ptr := unsafe.Pointer(&v)
+	if isComplex[T]() {
+		decodeBits(seg.order, data[:size/2], ptr)
+		decodeBits(seg.order, data[size/2:], unsafe.Add(ptr, size/2))
+	} else {
+		decodeBits(seg.order, data, ptr)
+	}
+	return v, nil
+}
+
+// Put writes the given value of the numeric type at the given offset to the given segment.
+// The value is encoded in the byte order of the segment like by PutUint, PutFloat and PutComplex.
+func Put[T Number](seg *Segment, offset int64, v T) error {
+	size := int64(unsafe.Sizeof(v))
+	if offset < seg.offset {
+		return ErrOutOfBounds
+	}
+	data, err := seg.write(offset-seg.offset, size)
+	if err != nil {
+		return err
+	}
+	ptr := unsafe.Pointer(&v)
+	if isComplex[T]() {
+		encodeBits(seg.order, data[:size/2], ptr)
+		encodeBits(seg.order, data[size/2:], unsafe.Add(ptr, size/2))
+	} else {
+		encodeBits(seg.order, data, ptr)
+	}
+	return nil
+}
+
+// isComplex returns whether the given numeric type is the complex one
+// which parts are encoded separately.
+func isComplex[T Number]() bool {
+	var v T
+	switch any(v).(type) {
+	case complex64, complex128:
+		return true
+	case int8, int16, int32, int64, uint8, uint16, uint32, uint64, float32, float64:
+		return false
+	}
+	kind := reflect.TypeOf(v).Kind()
+	return kind == reflect.Complex64 || kind == reflect.Complex128
+}
+
+// decodeBits decodes the given data of 1, 2, 4 or 8 bytes in the given byte order into the value
+// pointed by ptr of the same size.
+func decodeBits(order binary.ByteOrder, data []byte, ptr unsafe.Pointer) {
+	switch len(data) {
+	case Uint8Size:
+		*(*uint8)(ptr) = data[0]
+	case Uint16Size:
+		*(*uint16)(ptr) = order.Uint16(data)
+	case Uint32Size:
+		*(*uint32)(ptr) = order.Uint32(data)
+	case Uint64Size:
+		*(*uint64)(ptr) = order.Uint64(data)
+	}
+}
+
+// encodeBits encodes the value pointed by ptr of 1, 2, 4 or 8 bytes in the given byte order into the given data
+// of the same size.
+func encodeBits(order binary.ByteOrder, data []byte, ptr unsafe.Pointer) {
+	switch len(data) {
+	case Uint8Size:
+		data[0] = *(*uint8)(ptr)
+	case Uint16Size:
+		order.PutUint16(data, *(*uint16)(ptr))
+	case Uint32Size:
+		order.PutUint32(data, *(*uint32)(ptr))
+	case Uint64Size:
+		order.PutUint64(data, *(*uint64)(ptr))
+	}
+}
@@ -0,0 +1,37 @@
+package segment
+
+import "math"
+
+// Records returns the iterator over the contiguous array of count records of the given stride
+// at the given offset from this segment. The iterator calls yield with the index and the child segment
+// of each record, see Slice, in order until yield returns false.
+// It has the shape of the range function, so the records may be ranged over with Go 1.23 or later:
+//
+//	records, err := seg.Records(offset, stride, count)
+//	...
+//	for i, rec := range records {
+//		...
+//	}
+//
+// The ErrBadValue returns if the stride is zero or the count is negative,
+// the ErrOutOfBounds returns if the array does not fit this segment.
+func (seg *Segment) Records(offset int64, stride uintptr, count int) (func(yield func(int, *Segment) bool), error) {
+	if stride == 0 || count < 0 {
+		return nil, ErrBadValue
+	}
+	if uint64(count) > math.MaxInt64/uint64(stride) {
+		return nil, ErrOutOfBounds
+	}
+	if length := int64(uint64(count) * uint64(stride)); offset < seg.offset || offset-seg.offset > seg.size()-length {
+		return nil, ErrOutOfBounds
+	}
+	return func(yield func(int, *Segment) bool) {
+		for i := 0; i < count; i++ {
+			start := offset + int64(i)*int64(stride)
+			rec, err := seg.Slice(start, stride)
+			if err != nil || !yield(i, rec) {
+				return
+			}
+		}
+	}, nil
+}
@@ -0,0 +1,354 @@
+package segment
+
+import (
+	"encoding/binary"
+	"math"
+	"unsafe"
+)
+
+// AutoOffset is the field offset which places the field after the previous one at its natural alignment.
+const AutoOffset = -1
+
+// FieldType is the type of the schema field.
+type FieldType int
+
+// Types of the schema fields.
+const (
+	FieldBool FieldType = iota
+	FieldInt8
+	FieldInt16
+	FieldInt32
+	FieldInt64
+	FieldUint8
+	FieldUint16
+	FieldUint32
+	FieldUint64
+	FieldFloat32
+	FieldFloat64
+	FieldComplex64
+	FieldComplex128
+	// FieldBytes is the fixed-length byte array, see Field.Length.
+	FieldBytes
+)
+
+// fieldTypeNames specifies the names of the field types.
+var fieldTypeNames = [...]string{
+	FieldBool:       "bool",
+	FieldInt8:       "int8",
+	FieldInt16:      "int16",
+	FieldInt32:      "int32",
+	FieldInt64:      "int64",
+	FieldUint8:      "uint8",
+	FieldUint16:     "uint16",
+	FieldUint32:     "uint32",
+	FieldUint64:     "uint64",
+	FieldFloat32:    "float32",
+	FieldFloat64:    "float64",
+	FieldComplex64:  "complex64",
+	FieldComplex128: "complex128",
+	FieldBytes:      "bytes",
+}
+
+// String returns the name of the field type.
+// String implements the fmt.Stringer interface.
+func (typ FieldType) String() string {
+	if typ < 0 || int(typ) >= len(fieldTypeNames) {
+		return "invalid"
+	}
+	return fieldTypeNames[typ]
+}
+
+// Size returns the size of the value of the field type in bytes or zero for FieldBytes.
+func (typ FieldType) Size() int64 {
+	switch typ {
+	case FieldBool, FieldInt8, FieldUint8:
+		return 1
+	case FieldInt16, FieldUint16:
+		return 2
+	case FieldInt32, FieldUint32, FieldFloat32:
+		return 4
+	case FieldInt64, FieldUint64, FieldFloat64, FieldComplex64:
+		return 8
+	case FieldComplex128:
+		return 16
+	}
+	return 0
+}
+
+// Align returns the natural alignment of the value of the field type in bytes.
+func (typ FieldType) Align() int64 {
+	switch typ {
+	case FieldComplex64, FieldComplex128:
+		return typ.Size() / 2
+	case FieldBytes:
+		return 1
+	}
+	return typ.Size()
+}
+
+// Field is the definition of the schema field.
+type Field struct {
+	// Name specifies the unique name of the field.
+	Name string
+	// Type specifies the type of the field.
+	Type FieldType
+	// Offset specifies the offset of the field in bytes from start of the record or AutoOffset.
+	Offset int64
+	// Length specifies the length of the FieldBytes field in bytes, it is ignored for other types.
+	Length int64
+	// Order specifies the byte order of the field or nil for the byte order of the segment.
+	Order binary.ByteOrder
+}
+
+// size returns the size of the field in bytes.
+func (f *Field) size() int64 {
+	if f.Type == FieldBytes {
+		return f.Length
+	}
+	return f.Type.Size()
+}
+
+// Schema is the layout of the fixed-size record which fields are accessed by name,
+// so the generic tooling such as inspectors and migrators may be built on top of the mapped records.
+type Schema struct {
+	// fields specifies the fields with the resolved offsets.
+	fields []Field
+	// index specifies the indices of the fields by name.
+	index map[string]int
+	// size specifies the size of the record in bytes.
+	size int64
+	// align specifies the alignment of the record in bytes.
+	align int64
+}
+
+// NewSchema returns a new schema of the given fields.
+// The fields with AutoOffset are placed after the previous field at their natural alignment,
+// the size of the record is the end of the last field rounded up to the maximal alignment of the fields.
+// The ErrBadValue returns if the field names are not unique or the field is not valid.
+func NewSchema(fields ...Field) (*Schema, error) {
+	s := &Schema{
+		fields: make([]Field, len(fields)),
+		index:  make(map[string]int, len(fields)),
+		align:  1,
+	}
+	var next int64
+	for i, f := range fields {
+		if _, ok := s.index[f.Name]; ok || f.Name == "" {
+			return nil, ErrBadValue
+		}
+		if f.Type < 0 || f.Type > FieldBytes || (f.Type == FieldBytes && f.Length < 0) {
+			return nil, ErrBadValue
+		}
+		align := f.Type.Align()
+		if f.Offset == AutoOffset {
+			f.Offset = (next + align - 1) &^ (align - 1)
+		}
+		size := f.size()
+		if f.Offset < 0 || f.Offset > math.MaxInt64-size-align {
+			return nil, ErrBadValue
+		}
+		next = f.Offset + size
+		if next > s.size {
+			s.size = next
+		}
+		if align > s.align {
+			s.align = align
+		}
+		s.fields[i] = f
+		s.index[f.Name] = i
+	}
+	s.size = (s.size + s.align - 1) &^ (s.align - 1)
+	return s, nil
+}
+
+// Size returns the size of the record in bytes.
+func (s *Schema) Size() int64 {
+	return s.size
+}
+
+// Align returns the alignment of the record in bytes.
+func (s *Schema) Align() int64 {
+	return s.align
+}
+
+// Fields returns the fields of the schema with the resolved offsets in order of the definition.
+func (s *Schema) Fields() []Field {
+	fields := make([]Field, len(s.fields))
+	copy(fields, s.fields)
+	return fields
+}
+
+// Field returns the field of the given name with the resolved offset and whether it exists.
+func (s *Schema) Field(name string) (Field, bool) {
+	i, ok := s.index[name]
+	if !ok {
+		return Field{}, false
+	}
+	return s.fields[i], true
+}
+
+// Get returns the value of the named field of the record at the given offset from the given segment.
+// The value is of the Go type named by the field type, the FieldBytes value is the copy of the data.
+// The ErrBadValue returns if the field does not exist.
+func (s *Schema) Get(seg *Segment, offset int64, name string) (interface{}, error) {
+	f, data, err := s.field(seg, offset, name, false)
+	if err != nil {
+		return nil, err
+	}
+	order := seg.order
+	if f.Order != nil {
+		order = f.Order
+	}
+	switch f.Type {
+	case FieldBool:
+		return data[0] != 0, nil
+	case FieldInt8:
+		return int8(data[0]), nil
+	case FieldInt16:
+		return int16(order.Uint16(data)), nil
+	case FieldInt32:
+		return int32(order.Uint32(data)), nil
+	case FieldInt64:
+		return int64(order.Uint64(data)), nil
+	case FieldUint8:
+		return data[0], nil
+	case FieldUint16:
+		return order.Uint16(data), nil
+	case FieldUint32:
+		return order.Uint32(data), nil
+	case FieldUint64:
+		return order.Uint64(data), nil
+	case FieldFloat32:
+		return math.Float32frombits(order.Uint32(data)), nil
+	case FieldFloat64:
+		return math.Float64frombits(order.Uint64(data)), nil
+	case FieldComplex64:
+		var v complex64
+		decodeBits(order, data[:Float32Size], unsafe.Pointer(&v))
+		decodeBits(order, data[Float32Size:], unsafe.Add(unsafe.Pointer(&v), Float32Size))
+		return v, nil
+	case FieldComplex128:
+		var v complex128
+		decodeBits(order, data[:Float64Size], unsafe.Pointer(&v))
+		decodeBits(order, data[Float64Size:], unsafe.Add(unsafe.Pointer(&v), Float64Size))
+		return v, nil
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// Set writes the given value of the named field of the record at the given offset to the given segment.
+// The value must be of the Go type named by the field type, the FieldBytes value must be the byte slice
+// which is not longer than the field and the rest of the field is zeroed.
+// The ErrBadValue returns and nothing is written if the field does not exist or the value is of another type.
+func (s *Schema) Set(seg *Segment, offset int64, name string, v interface{}) error {
+	f, ok := s.Field(name)
+	if !ok {
+		return ErrBadValue
+	}
+	ok = false
+	switch f.Type {
+	case FieldBool:
+		_, ok = v.(bool)
+	case FieldInt8:
+		_, ok = v.(int8)
+	case FieldInt16:
+		_, ok = v.(int16)
+	case FieldInt32:
+		_, ok = v.(int32)
+	case FieldInt64:
+		_, ok = v.(int64)
+	case FieldUint8:
+		_, ok = v.(uint8)
+	case FieldUint16:
+		_, ok = v.(uint16)
+	case FieldUint32:
+		_, ok = v.(uint32)
+	case FieldUint64:
+		_, ok = v.(uint64)
+	case FieldFloat32:
+		_, ok = v.(float32)
+	case FieldFloat64:
+		_, ok = v.(float64)
+	case FieldComplex64:
+		_, ok = v.(complex64)
+	case FieldComplex128:
+		_, ok = v.(complex128)
+	case FieldBytes:
+		b, isBytes := v.([]byte)
+		ok = isBytes && int64(len(b)) <= f.Length
+	}
+	if !ok {
+		return ErrBadValue
+	}
+	_, data, err := s.field(seg, offset, name, true)
+	if err != nil {
+		return err
+	}
+	order := seg.order
+	if f.Order != nil {
+		order = f.Order
+	}
+	switch value := v.(type) {
+	case bool:
+		data[0] = 0
+		if value {
+			data[0] = 1
+		}
+	case int8:
+		data[0] = uint8(value)
+	case int16:
+		order.PutUint16(data, uint16(value))
+	case int32:
+		order.PutUint32(data, uint32(value))
+	case int64:
+		order.PutUint64(data, uint64(value))
+	case uint8:
+		data[0] = value
+	case uint16:
+		order.PutUint16(data, value)
+	case uint32:
+		order.PutUint32(data, value)
+	case uint64:
+		order.PutUint64(data, value)
+	case float32:
+		order.PutUint32(data, math.Float32bits(value))
+	case float64:
+		order.PutUint64(data, math.Float64bits(value))
+	case complex64:
+		encodeBits(order, data[:Float32Size], unsafe.Pointer(&value))
+		encodeBits(order, data[Float32Size:], unsafe.Add(unsafe.Pointer(&value), Float32Size))
+	case complex128:
+		encodeBits(order, data[:Float64Size], unsafe.Pointer(&value))
+		encodeBits(order, data[Float64Size:], unsafe.Add(unsafe.Pointer(&value), Float64Size))
+	case []byte:
+		for i := copy(data, value); i < len(data); i++ {
+			data[i] = 0
+		}
+	}
+	return nil
+}
+
+// field returns the named field and its data in the record at the given offset from the given segment.
+func (s *Schema) field(seg *Segment, offset int64, name string, write bool) (Field, []byte, error) {
+	f, ok := s.Field(name)
+	if !ok {
+		return f, nil, ErrBadValue
+	}
+	if offset < seg.offset || offset-seg.offset > math.MaxInt64-f.Offset {
+		return f, nil, ErrOutOfBounds
+	}
+	offset += f.Offset - seg.offset
+	size := f.size()
+	if size == 0 {
+		if offset > seg.size() {
+			return f, nil, ErrOutOfBounds
+		}
+		return f, nil, nil
+	}
+	if write {
+		data, err := seg.write(offset, size)
+		return f, data, err
+	}
+	data, err := seg.read(offset, size)
+	return f, data, err
+}
@@ -0,0 +1,68 @@
+package segment
+
+import "bytes"
+
+// IndexByte returns the offset of the first instance of the given byte within the given number of bytes
+// at the given offset from this segment or -1 if it is not present.
+// The ErrOutOfBounds returns if the range is out of the available bounds.
+func (seg *Segment) IndexByte(offset int64, length uintptr, c byte) (int64, error) {
+	data, err := seg.bytes(offset, length, false)
+	if err != nil {
+		return -1, err
+	}
+	if i := bytes.IndexByte(data, c); i >= 0 {
+		return offset + int64(i), nil
+	}
+	return -1, nil
+}
+
+// Index returns the offset of the first instance of the given pattern which lies entirely within the given number
+// of bytes at the given offset from this segment or -1 if it is not present.
+// The ErrOutOfBounds returns if the range is out of the available bounds.
+func (seg *Segment) Index(offset int64, length uintptr, pattern []byte) (int64, error) {
+	data, err := seg.bytes(offset, length, false)
+	if err != nil {
+		return -1, err
+	}
+	if i := bytes.Index(data, pattern); i >= 0 {
+		return offset + int64(i), nil
+	}
+	return -1, nil
+}
+
+// Search performs the binary search over the sorted contiguous array of count records of the given size
+// at start of this segment. The given function is called with the child segment of the probed record,
+// see Slice, and returns a negative number if the record precedes the target, zero if it matches the target
+// and a positive number if it follows the target. The child segment is valid only during the call.
+// Search returns the index of the first record which does not precede the target and whether it matches,
+// so the index is where the target would be inserted if it is not found.
+// The ErrBadValue returns if the record size is zero or the count is negative,
+// the ErrOutOfBounds returns if the array does not fit this segment.
+func (seg *Segment) Search(recordSize uintptr, count int, cmp func(rec *Segment) int) (int, bool, error) {
+	if recordSize == 0 || count < 0 {
+		return 0, false, ErrBadValue
+	}
+	if uint64(count) > uint64(seg.size())/uint64(recordSize) {
+		return 0, false, ErrOutOfBounds
+	}
+	rec := &Segment{order: seg.order, bitOrder: seg.bitOrder, strict: seg.strict}
+	low, high := 0, count
+	found := false
+	for low < high {
+		middle := int(uint(low+high) >> 1)
+		start := int64(middle) * int64(recordSize)
+		if seg.chain != nil {
+			rec, _ = seg.Slice(seg.offset+start, recordSize)
+		} else {
+			rec.offset = seg.offset + start
+			rec.data = seg.data[start : start+int64(recordSize) : start+int64(recordSize)]
+		}
+		if c := cmp(rec); c < 0 {
+			low = middle + 1
+		} else {
+			high = middle
+			found = c == 0
+		}
+	}
+	return low, found, nil
+}
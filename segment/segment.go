@@ -2,10 +2,15 @@
 package segment
 
 import (
+	"bytes"
 	"encoding/binary"
+	"fmt"
 	"math"
-	"reflect"
+	"strings"
+	"unicode/utf16"
 	"unsafe"
+
+	"github.com/alexeymaximov/go-bio/internal/race"
 )
 
 // MaxUintptr is the maximum platform dependent unsigned integer
@@ -30,80 +35,275 @@ const (
 
 // Segment is a data segment.
 // See https://golang.org/ref/spec#Numeric_types for details.
+//
+// The accesses to the mapped memory are reported to the race detector by the methods of the segment.
+// The typed pointer accessors, Pointer and View report only the handout of the pointer, as the read access,
+// since the accesses through the pointer are invisible to the race detector.
+// So the concurrent writes through such pointers are not detected as the races,
+// use WriteAt and the Put methods for the writes which must be checked.
 type Segment struct {
 	// offset specifies the offset of this segment.
 	offset int64
-	// data specifies the descriptor of the raw byte data associated with this segment.
-	// TODO: Choose the correct type for this field and it's initialization mechanism.
-	data reflect.SliceHeader
+	// data specifies the raw byte data associated with this segment.
+	data []byte
+	// chain specifies the chained slices which hold the data of this segment instead of data, see NewChain.
+	chain *chain
+	// order specifies the byte order of the encoded multi-byte values.
+	order binary.ByteOrder
+	// bitOrder specifies the order of the bits within the bytes.
+	bitOrder BitOrder
+	// strict specifies whether the typed pointers must be naturally aligned.
+	strict bool
 }
 
 // New returns a new data segment.
+// The multi-byte values are encoded in the little-endian byte order, see SetByteOrder.
 func New(offset int64, data []byte) *Segment {
 	return &Segment{
 		offset: offset,
-		data:   *(*reflect.SliceHeader)(unsafe.Pointer(&data)),
+		data:   data,
+		order:  binary.LittleEndian,
+	}
+}
+
+// ByteOrder returns the byte order of the values which are encoded by this segment.
+func (seg *Segment) ByteOrder() binary.ByteOrder {
+	return seg.order
+}
+
+// SetByteOrder sets the byte order of the values which are encoded by this segment,
+// e.g. binary.BigEndian for the network formats.
+// It does not affect the typed pointers which address the values in the native byte order.
+func (seg *Segment) SetByteOrder(order binary.ByteOrder) {
+	seg.order = order
+}
+
+// Strict returns whether this segment is in the strict mode, see SetStrict.
+func (seg *Segment) Strict() bool {
+	return seg.strict
+}
+
+// SetStrict sets whether this segment is in the strict mode.
+// In the strict mode the typed pointer accessors such as Uint64 panic with the error which wraps ErrMisaligned
+// if the address of the value is not aligned by its size (by the size of its part for the complex numbers),
+// since the misaligned atomic operations and the misaligned access on some architectures
+// fault at runtime in a confusing way.
+func (seg *Segment) SetStrict(strict bool) {
+	seg.strict = strict
+}
+
+// Offset returns the base offset of this segment which addresses its first byte.
+func (seg *Segment) Offset() int64 {
+	return seg.offset
+}
+
+// Len returns the length of this segment in bytes, so its offsets range from Offset to Offset+Len.
+func (seg *Segment) Len() int64 {
+	return seg.size()
+}
+
+// Contains returns whether the given number of bytes at the given offset are within this segment,
+// so the bounds may be validated up front instead of probing with the accessors which panic.
+func (seg *Segment) Contains(offset int64, length uintptr) bool {
+	if offset < seg.offset || uint64(length) > math.MaxInt64 {
+		return false
 	}
+	return offset-seg.offset <= seg.size()-int64(length)
 }
 
 // Pointer returns an untyped pointer to the value from this segment or panics at the access violation.
+// The handout of the pointer is reported to the race detector as the read access
+// to the pointed memory if it belongs to the mapped memory, the writes through the pointer are not seen.
 func (seg *Segment) Pointer(offset int64, length uintptr) uintptr {
-	if offset < seg.offset || length > math.MaxInt64 {
-		panic(Fault)
+	data := seg.slice(offset, length)
+	address := *(*uintptr)(unsafe.Pointer(&data))
+	race.ReadRange(address, length)
+	return address
+}
+
+// Slice returns the child segment of the given length at the given offset from this segment or ErrOutOfBounds.
+// The child segment shares the data with this one, its base offset is the given offset,
+// so the values are addressed by the same offsets as in this segment but can not be accessed outside the child.
+// The byte and bit orders and the strict mode are inherited.
+func (seg *Segment) Slice(offset int64, length uintptr) (*Segment, error) {
+	data, err := seg.Bytes(offset, length)
+	var child *Segment
+	switch {
+	case err == ErrSeam:
+		pieces := seg.chain.pieces(offset-seg.offset, int64(length))
+		for _, piece := range pieces {
+			race.ReadRange(uintptr(unsafe.Pointer(&piece[0])), uintptr(len(piece)))
+		}
+		child = NewChain(offset, pieces...)
+	case err != nil:
+		return nil, err
+	default:
+		child = New(offset, data[:length:length])
+	}
+	child.order = seg.order
+	child.bitOrder = seg.bitOrder
+	child.strict = seg.strict
+	return child, nil
+}
+
+// ReadAt reads len(buf) bytes at the given offset from this segment.
+// If the given offset is out of the available bounds or there are not enough bytes to read
+// the ErrOutOfBounds error will be returned. Otherwise len(buf) will be returned with no errors.
+// ReadAt implements the io.ReaderAt interface.
+func (seg *Segment) ReadAt(buf []byte, offset int64) (int, error) {
+	data, err := seg.bytes(offset, uintptr(len(buf)), false)
+	if err != nil {
+		return 0, err
+	}
+	return copy(buf, data), nil
+}
+
+// WriteAt writes len(buf) bytes at the given offset to this segment.
+// If the given offset is out of the available bounds or there are not enough space to write all given bytes
+// the ErrOutOfBounds error will be returned. Otherwise len(buf) will be returned with no errors.
+// WriteAt implements the io.WriterAt interface.
+func (seg *Segment) WriteAt(buf []byte, offset int64) (int, error) {
+	if offset < seg.offset {
+		return 0, ErrOutOfBounds
+	}
+	if len(buf) == 0 {
+		if offset-seg.offset > seg.size() {
+			return 0, ErrOutOfBounds
+		}
+		return 0, nil
+	}
+	data, err := seg.write(offset-seg.offset, int64(len(buf)))
+	if err == ErrSeam {
+		n := 0
+		for _, piece := range seg.chain.pieces(offset-seg.offset, int64(len(buf))) {
+			race.WriteRange(uintptr(unsafe.Pointer(&piece[0])), uintptr(len(piece)))
+			n += copy(piece, buf[n:])
+		}
+		return n, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return copy(data, buf), nil
+}
+
+// Bytes returns the live byte slice of the given length at the given offset from this segment
+// or ErrOutOfBounds, so the data may be read and written in place without the copying.
+// The handout of the slice is reported to the race detector as the read access to its memory.
+// The ErrSeam returns if the range crosses the seam of the chained segment, see NewChain.
+func (seg *Segment) Bytes(offset int64, length uintptr) ([]byte, error) {
+	return seg.bytes(offset, length, true)
+}
+
+// bytes returns the byte slice of the given length at the given offset from this segment like Bytes
+// if live is true or like read otherwise, so the data which crosses the seam of the chained segment is copied.
+func (seg *Segment) bytes(offset int64, length uintptr, live bool) ([]byte, error) {
+	if offset < seg.offset || uint64(length) > math.MaxInt64 {
+		return nil, ErrOutOfBounds
 	}
 	offset -= seg.offset
-	if offset > math.MaxInt64-int64(length) || offset+int64(length) > int64(seg.data.Len) {
-		panic(Fault)
+	if length == 0 {
+		if offset > seg.size() {
+			return nil, ErrOutOfBounds
+		}
+		if seg.chain != nil {
+			return []byte{}, nil
+		}
+		return seg.data[offset:offset], nil
+	}
+	if !live {
+		return seg.read(offset, int64(length))
 	}
-	if uint64(offset) > uint64(MaxUintptr-seg.data.Data) {
-		panic(Fault)
+	data, err := seg.span(offset, int64(length))
+	if err != nil {
+		return nil, err
+	}
+	race.ReadRange(uintptr(unsafe.Pointer(&data[0])), uintptr(length))
+	return data, nil
+}
+
+// pointer returns an unsafe pointer to the value of the non-zero length from this segment
+// or panics at the access violation or at the misalignment by the given alignment in the strict mode.
+func (seg *Segment) pointer(offset int64, length, align uintptr) unsafe.Pointer {
+	data := seg.slice(offset, length)
+	address := uintptr(unsafe.Pointer(&data[0]))
+	if seg.strict && address%align != 0 {
+		panic(fmt.Errorf("%w: %d-byte value at offset %d (address %#x) is not aligned by %d",
+			ErrMisaligned, length, offset, address, align))
+	}
+	race.ReadRange(address, length)
+	return unsafe.Pointer(&data[0])
+}
+
+// slice returns the byte slice of the given length at the given offset from this segment
+// or panics with the FaultError at the access violation.
+func (seg *Segment) slice(offset int64, length uintptr) []byte {
+	if offset < seg.offset || uint64(length) > math.MaxInt64 {
+		panic(seg.fault(offset, length))
+	}
+	data, err := seg.span(offset-seg.offset, int64(length))
+	switch err {
+	case nil:
+		return data
+	case ErrSeam:
+		panic(fmt.Errorf("%w: %d bytes at offset %d", ErrSeam, length, offset))
+	}
+	panic(seg.fault(offset, length))
+}
+
+// fault returns the access violation error of the value of the given length at the given offset from this segment.
+func (seg *Segment) fault(offset int64, length uintptr) *FaultError {
+	return &FaultError{
+		Offset:        offset,
+		Length:        length,
+		SegmentOffset: seg.offset,
+		SegmentLen:    int(seg.size()),
 	}
-	return seg.data.Data + uintptr(offset)
 }
 
 // Int8 returns a pointer to the signed 8-bit integer from this segment or panics at the access violation.
 func (seg *Segment) Int8(offset int64) *int8 {
-	return (*int8)(unsafe.Pointer(seg.Pointer(offset, Int8Size)))
+	return (*int8)(seg.pointer(offset, Int8Size, Int8Size))
 }
 
 // Int16 returns a pointer to the signed 16-bit integer from this segment or panics at the access violation.
 func (seg *Segment) Int16(offset int64) *int16 {
-	return (*int16)(unsafe.Pointer(seg.Pointer(offset, Int16Size)))
+	return (*int16)(seg.pointer(offset, Int16Size, Int16Size))
 }
 
 // Int32 returns a pointer to the signed 32-bit integer from this segment or panics at the access violation.
 func (seg *Segment) Int32(offset int64) *int32 {
-	return (*int32)(unsafe.Pointer(seg.Pointer(offset, Int32Size)))
+	return (*int32)(seg.pointer(offset, Int32Size, Int32Size))
 }
 
 // Int64 returns a pointer to the signed 64-bit integer from this segment or panics at the access violation.
 func (seg *Segment) Int64(offset int64) *int64 {
-	return (*int64)(unsafe.Pointer(seg.Pointer(offset, Int64Size)))
+	return (*int64)(seg.pointer(offset, Int64Size, Int64Size))
 }
 
 // Uint8 returns a pointer to the unsigned 8-bit integer from this segment or panics at the access violation.
 func (seg *Segment) Uint8(offset int64) *uint8 {
-	return (*uint8)(unsafe.Pointer(seg.Pointer(offset, Uint8Size)))
+	return (*uint8)(seg.pointer(offset, Uint8Size, Uint8Size))
 }
 
 // Uint16 returns a pointer to the unsigned 16-bit integer from this segment or panics at the access violation.
 func (seg *Segment) Uint16(offset int64) *uint16 {
-	return (*uint16)(unsafe.Pointer(seg.Pointer(offset, Uint16Size)))
+	return (*uint16)(seg.pointer(offset, Uint16Size, Uint16Size))
 }
 
 // Uint32 returns a pointer to the unsigned 32-bit integer from this segment or panics at the access violation.
 func (seg *Segment) Uint32(offset int64) *uint32 {
-	return (*uint32)(unsafe.Pointer(seg.Pointer(offset, Uint32Size)))
+	return (*uint32)(seg.pointer(offset, Uint32Size, Uint32Size))
 }
 
-// Uint16 returns a pointer to the unsigned 64-bit integer from this segment or panics at the access violation.
+// Uint64 returns a pointer to the unsigned 64-bit integer from this segment or panics at the access violation.
 func (seg *Segment) Uint64(offset int64) *uint64 {
-	return (*uint64)(unsafe.Pointer(seg.Pointer(offset, Uint64Size)))
+	return (*uint64)(seg.pointer(offset, Uint64Size, Uint64Size))
 }
 
 // ScanUint sequentially reads the data into the unsigned integers pointed by v starting from the given offset.
+// The multi-byte integers are decoded in the byte order of this segment.
 func (seg *Segment) ScanUint(offset int64, v ...interface{}) error {
-	data := *(*[]byte)(unsafe.Pointer(&seg.data))
 	if offset < seg.offset {
 		return ErrOutOfBounds
 	}
@@ -113,54 +313,527 @@ func (seg *Segment) ScanUint(offset int64, v ...interface{}) error {
 		default:
 			return ErrBadValue
 		case *uint8:
-			if offset < 0 || offset > math.MaxInt64-Uint8Size || offset+Uint8Size > int64(len(data)) {
-				return ErrOutOfBounds
+			data, err := seg.read(offset, Uint8Size)
+			if err != nil {
+				return err
 			}
-			*value = data[offset:][0]
+			*value = data[0]
 			offset += Uint8Size
 		case *uint16:
-			if offset < 0 || offset > math.MaxInt64-Uint16Size || offset+Uint16Size > int64(len(data)) {
-				return ErrOutOfBounds
+			data, err := seg.read(offset, Uint16Size)
+			if err != nil {
+				return err
 			}
-			*value = binary.LittleEndian.Uint16(data[offset : offset+Uint16Size])
+			*value = seg.order.Uint16(data)
 			offset += Uint16Size
 		case *uint32:
-			if offset < 0 || offset > math.MaxInt64-Uint32Size || offset+Uint32Size > int64(len(data)) {
-				return ErrOutOfBounds
+			data, err := seg.read(offset, Uint32Size)
+			if err != nil {
+				return err
 			}
-			*value = binary.LittleEndian.Uint32(data[offset : offset+Uint32Size])
+			*value = seg.order.Uint32(data)
 			offset += Uint32Size
 		case *uint64:
-			if offset < 0 || offset > math.MaxInt64-Uint64Size || offset+Uint64Size > int64(len(data)) {
-				return ErrOutOfBounds
+			data, err := seg.read(offset, Uint64Size)
+			if err != nil {
+				return err
+			}
+			*value = seg.order.Uint64(data)
+			offset += Uint64Size
+		}
+	}
+	return nil
+}
+
+// PutUint sequentially writes the unsigned integers v starting from the given offset.
+// The values must be of the uint8, uint16, uint32 or uint64 types,
+// the multi-byte integers are encoded in the byte order of this segment.
+// The values are written up to the first one which does not fit this segment.
+func (seg *Segment) PutUint(offset int64, v ...interface{}) error {
+	if offset < seg.offset {
+		return ErrOutOfBounds
+	}
+	offset -= seg.offset
+	for _, val := range v {
+		switch value := val.(type) {
+		default:
+			return ErrBadValue
+		case uint8:
+			data, err := seg.write(offset, Uint8Size)
+			if err != nil {
+				return err
+			}
+			data[0] = value
+			offset += Uint8Size
+		case uint16:
+			data, err := seg.write(offset, Uint16Size)
+			if err != nil {
+				return err
+			}
+			seg.order.PutUint16(data, value)
+			offset += Uint16Size
+		case uint32:
+			data, err := seg.write(offset, Uint32Size)
+			if err != nil {
+				return err
+			}
+			seg.order.PutUint32(data, value)
+			offset += Uint32Size
+		case uint64:
+			data, err := seg.write(offset, Uint64Size)
+			if err != nil {
+				return err
 			}
-			*value = binary.LittleEndian.Uint64(data[offset : offset+Uint64Size])
+			seg.order.PutUint64(data, value)
 			offset += Uint64Size
 		}
 	}
 	return nil
 }
 
+// ScanFloat sequentially reads the data into the IEEE-754 floating-point numbers pointed by v
+// starting from the given offset.
+// The values are decoded from their bits in the byte order of this segment.
+func (seg *Segment) ScanFloat(offset int64, v ...interface{}) error {
+	if offset < seg.offset {
+		return ErrOutOfBounds
+	}
+	offset -= seg.offset
+	for _, val := range v {
+		switch value := val.(type) {
+		default:
+			return ErrBadValue
+		case *float32:
+			data, err := seg.read(offset, Float32Size)
+			if err != nil {
+				return err
+			}
+			*value = math.Float32frombits(seg.order.Uint32(data))
+			offset += Float32Size
+		case *float64:
+			data, err := seg.read(offset, Float64Size)
+			if err != nil {
+				return err
+			}
+			*value = math.Float64frombits(seg.order.Uint64(data))
+			offset += Float64Size
+		}
+	}
+	return nil
+}
+
+// PutFloat sequentially writes the IEEE-754 floating-point numbers v starting from the given offset.
+// The values must be of the float32 or float64 types, their bits are encoded in the byte order of this segment.
+// The values are written up to the first one which does not fit this segment.
+func (seg *Segment) PutFloat(offset int64, v ...interface{}) error {
+	if offset < seg.offset {
+		return ErrOutOfBounds
+	}
+	offset -= seg.offset
+	for _, val := range v {
+		switch value := val.(type) {
+		default:
+			return ErrBadValue
+		case float32:
+			data, err := seg.write(offset, Float32Size)
+			if err != nil {
+				return err
+			}
+			seg.order.PutUint32(data, math.Float32bits(value))
+			offset += Float32Size
+		case float64:
+			data, err := seg.write(offset, Float64Size)
+			if err != nil {
+				return err
+			}
+			seg.order.PutUint64(data, math.Float64bits(value))
+			offset += Float64Size
+		}
+	}
+	return nil
+}
+
+// ScanComplex sequentially reads the data into the complex numbers pointed by v starting from the given offset.
+// Each value is read as the interleaved pair of the IEEE-754 real and imaginary parts
+// which bits are decoded in the byte order of this segment.
+func (seg *Segment) ScanComplex(offset int64, v ...interface{}) error {
+	if offset < seg.offset {
+		return ErrOutOfBounds
+	}
+	offset -= seg.offset
+	for _, val := range v {
+		switch value := val.(type) {
+		default:
+			return ErrBadValue
+		case *complex64:
+			data, err := seg.read(offset, Complex64Size)
+			if err != nil {
+				return err
+			}
+			*value = complex(
+				math.Float32frombits(seg.order.Uint32(data)),
+				math.Float32frombits(seg.order.Uint32(data[Float32Size:])),
+			)
+			offset += Complex64Size
+		case *complex128:
+			data, err := seg.read(offset, Complex128Size)
+			if err != nil {
+				return err
+			}
+			*value = complex(
+				math.Float64frombits(seg.order.Uint64(data)),
+				math.Float64frombits(seg.order.Uint64(data[Float64Size:])),
+			)
+			offset += Complex128Size
+		}
+	}
+	return nil
+}
+
+// PutComplex sequentially writes the complex numbers v starting from the given offset.
+// The values must be of the complex64 or complex128 types, each one is written as the interleaved pair
+// of the IEEE-754 real and imaginary parts which bits are encoded in the byte order of this segment.
+// The values are written up to the first one which does not fit this segment.
+func (seg *Segment) PutComplex(offset int64, v ...interface{}) error {
+	if offset < seg.offset {
+		return ErrOutOfBounds
+	}
+	offset -= seg.offset
+	for _, val := range v {
+		switch value := val.(type) {
+		default:
+			return ErrBadValue
+		case complex64:
+			data, err := seg.write(offset, Complex64Size)
+			if err != nil {
+				return err
+			}
+			seg.order.PutUint32(data, math.Float32bits(real(value)))
+			seg.order.PutUint32(data[Float32Size:], math.Float32bits(imag(value)))
+			offset += Complex64Size
+		case complex128:
+			data, err := seg.write(offset, Complex128Size)
+			if err != nil {
+				return err
+			}
+			seg.order.PutUint64(data, math.Float64bits(real(value)))
+			seg.order.PutUint64(data[Float64Size:], math.Float64bits(imag(value)))
+			offset += Complex128Size
+		}
+	}
+	return nil
+}
+
+// Uvarint decodes the unsigned variable-length integer at the given offset from this segment
+// and returns it with the number of bytes consumed.
+// The ErrOutOfBounds returns if the integer is not terminated within this segment,
+// the ErrOverflow returns if it does not fit the 64-bit integer.
+func (seg *Segment) Uvarint(offset int64) (uint64, int, error) {
+	if offset < seg.offset || offset-seg.offset >= seg.size() {
+		return 0, 0, ErrOutOfBounds
+	}
+	offset -= seg.offset
+	length := seg.size() - offset
+	if length > binary.MaxVarintLen64 {
+		length = binary.MaxVarintLen64
+	}
+	v, n := binary.Uvarint(seg.gather(offset, length))
+	switch {
+	case n < 0, n == 0 && length == binary.MaxVarintLen64:
+		return 0, 0, ErrOverflow
+	case n == 0:
+		return 0, 0, ErrOutOfBounds
+	}
+	seg.reportRead(offset, int64(n))
+	return v, n, nil
+}
+
+// Varint decodes the signed zig-zag variable-length integer at the given offset from this segment
+// and returns it with the number of bytes consumed like Uvarint.
+func (seg *Segment) Varint(offset int64) (int64, int, error) {
+	u, n, err := seg.Uvarint(offset)
+	if err != nil {
+		return 0, 0, err
+	}
+	v := int64(u >> 1)
+	if u&1 != 0 {
+		v = ^v
+	}
+	return v, n, nil
+}
+
+// PutUvarint encodes the given unsigned integer as the variable-length one at the given offset
+// to this segment and returns the number of bytes written.
+// The ErrOutOfBounds returns and nothing is written if the encoded integer does not fit this segment.
+func (seg *Segment) PutUvarint(offset int64, v uint64) (int, error) {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	if offset < seg.offset {
+		return 0, ErrOutOfBounds
+	}
+	data, err := seg.write(offset-seg.offset, int64(n))
+	if err != nil {
+		return 0, err
+	}
+	copy(data, buf[:n])
+	return n, nil
+}
+
+// PutVarint encodes the given signed integer as the zig-zag variable-length one at the given offset
+// to this segment and returns the number of bytes written like PutUvarint.
+func (seg *Segment) PutVarint(offset int64, v int64) (int, error) {
+	u := uint64(v) << 1
+	if v < 0 {
+		u = ^u
+	}
+	return seg.PutUvarint(offset, u)
+}
+
+// CString returns the null-terminated string at the given offset from this segment.
+// It scans at most maxLen bytes for the terminating null byte, so the string of maxLen bytes is returned
+// if it is not found, e.g. for the fixed-size character fields which are entirely filled.
+// The ErrOutOfBounds returns if the segment ends before the null byte and maxLen bytes.
+func (seg *Segment) CString(offset int64, maxLen uintptr) (string, error) {
+	if offset < seg.offset || offset-seg.offset > seg.size() {
+		return "", ErrOutOfBounds
+	}
+	offset -= seg.offset
+	length := seg.size() - offset
+	limited := uint64(maxLen) <= uint64(length)
+	if limited {
+		length = int64(maxLen)
+	}
+	n := seg.indexByte(offset, length, 0)
+	if n < 0 {
+		if !limited {
+			return "", ErrOutOfBounds
+		}
+		n = length
+	}
+	if n == 0 {
+		return "", nil
+	}
+	data, err := seg.read(offset, n)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// PutCString writes the given string followed by the null byte at the given offset to this segment
+// and returns the number of bytes written.
+// The ErrBadValue returns if the string contains the null byte,
+// the ErrOutOfBounds returns and nothing is written if the string does not fit this segment.
+func (seg *Segment) PutCString(offset int64, s string) (int, error) {
+	if strings.IndexByte(s, 0) >= 0 {
+		return 0, ErrBadValue
+	}
+	if offset < seg.offset {
+		return 0, ErrOutOfBounds
+	}
+	data, err := seg.write(offset-seg.offset, int64(len(s))+1)
+	if err != nil {
+		return 0, err
+	}
+	data[copy(data, s)] = 0
+	return len(data), nil
+}
+
+// UTF16String returns the string of the given number of the UTF-16 code units at the given offset from this segment.
+// The code units are decoded in the byte order of this segment, the invalid surrogates are replaced by U+FFFD.
+func (seg *Segment) UTF16String(offset int64, chars int) (string, error) {
+	if offset < seg.offset || chars < 0 || int64(chars) > math.MaxInt64/Uint16Size {
+		return "", ErrOutOfBounds
+	}
+	if chars == 0 {
+		return "", nil
+	}
+	data, err := seg.read(offset-seg.offset, int64(chars)*Uint16Size)
+	if err != nil {
+		return "", err
+	}
+	units := make([]uint16, chars)
+	for i := range units {
+		units[i] = seg.order.Uint16(data[i*Uint16Size:])
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+// PutUTF16String writes the given string as the UTF-16 code units at the given offset to this segment
+// and returns the number of the code units written. The terminating null code unit is not written.
+// The code units are encoded in the byte order of this segment.
+// The ErrOutOfBounds returns and nothing is written if the string does not fit this segment.
+func (seg *Segment) PutUTF16String(offset int64, s string) (int, error) {
+	if offset < seg.offset {
+		return 0, ErrOutOfBounds
+	}
+	units := utf16.Encode([]rune(s))
+	if len(units) == 0 {
+		return 0, nil
+	}
+	data, err := seg.write(offset-seg.offset, int64(len(units))*Uint16Size)
+	if err != nil {
+		return 0, err
+	}
+	for i, u := range units {
+		seg.order.PutUint16(data[i*Uint16Size:], u)
+	}
+	return len(units), nil
+}
+
+// Zero zeroes the given number of bytes at the given offset from this segment.
+// The ErrOutOfBounds returns and nothing is zeroed if the range is out of the available bounds.
+func (seg *Segment) Zero(offset int64, length uintptr) error {
+	return seg.Fill(offset, length, 0)
+}
+
+// Fill fills the given number of bytes at the given offset from this segment with the given byte.
+// The ErrOutOfBounds returns and nothing is filled if the range is out of the available bounds.
+func (seg *Segment) Fill(offset int64, length uintptr, b byte) error {
+	if offset < seg.offset || uint64(length) > math.MaxInt64 {
+		return ErrOutOfBounds
+	}
+	if length == 0 {
+		if offset-seg.offset > seg.size() {
+			return ErrOutOfBounds
+		}
+		return nil
+	}
+	data, err := seg.write(offset-seg.offset, int64(length))
+	if err == ErrSeam {
+		for _, piece := range seg.chain.pieces(offset-seg.offset, int64(length)) {
+			race.WriteRange(uintptr(unsafe.Pointer(&piece[0])), uintptr(len(piece)))
+			fill(piece, b)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fill(data, b)
+	return nil
+}
+
+// fill fills the given non-empty data with the given byte.
+func fill(data []byte, b byte) {
+	// The filled prefix is copied doubling its length each time.
+	data[0] = b
+	for n := 1; n < len(data); n *= 2 {
+		copy(data[n:], data[:n])
+	}
+}
+
+// size returns the length of the data of this segment in bytes.
+func (seg *Segment) size() int64 {
+	if seg.chain != nil {
+		return seg.chain.size()
+	}
+	return int64(len(seg.data))
+}
+
+// span returns the byte slice of the given non-zero length at the given offset from start of the data
+// or ErrOutOfBounds. The ErrSeam returns if the range crosses the seam of the chained segment.
+func (seg *Segment) span(offset, length int64) ([]byte, error) {
+	if offset < 0 || offset > math.MaxInt64-length || offset+length > seg.size() {
+		return nil, ErrOutOfBounds
+	}
+	if seg.chain != nil {
+		i, at := seg.chain.locate(offset)
+		if at+length > int64(len(seg.chain.slices[i])) {
+			return nil, ErrSeam
+		}
+		return seg.chain.slices[i][at : at+length], nil
+	}
+	return seg.data[offset : offset+length], nil
+}
+
+// gather returns the byte slice of the given non-zero length at the given offset from start of the data
+// which must be in bounds. The data which crosses the seam of the chained segment is copied.
+func (seg *Segment) gather(offset, length int64) []byte {
+	data, err := seg.span(offset, length)
+	if err == nil {
+		return data
+	}
+	data = make([]byte, 0, length)
+	for _, piece := range seg.chain.pieces(offset, length) {
+		data = append(data, piece...)
+	}
+	return data
+}
+
+// indexByte returns the index of the first instance of the given byte within the given number of bytes
+// at the given offset from start of the data which must be in bounds or -1 if it is not present.
+func (seg *Segment) indexByte(offset, length int64, c byte) int64 {
+	if seg.chain == nil {
+		return int64(bytes.IndexByte(seg.data[offset:offset+length], c))
+	}
+	var n int64
+	for _, piece := range seg.chain.pieces(offset, length) {
+		if i := bytes.IndexByte(piece, c); i >= 0 {
+			return n + int64(i)
+		}
+		n += int64(len(piece))
+	}
+	return -1
+}
+
+// reportRead reports the given non-zero number of bytes at the given offset from start of the data
+// to the race detector as the read access.
+func (seg *Segment) reportRead(offset, length int64) {
+	if seg.chain == nil {
+		race.ReadRange(uintptr(unsafe.Pointer(&seg.data[offset])), uintptr(length))
+		return
+	}
+	for _, piece := range seg.chain.pieces(offset, length) {
+		race.ReadRange(uintptr(unsafe.Pointer(&piece[0])), uintptr(len(piece)))
+	}
+}
+
+// read returns the byte slice like span and reports it to the race detector as the read access.
+// The data which crosses the seam of the chained segment is copied.
+func (seg *Segment) read(offset, length int64) ([]byte, error) {
+	data, err := seg.span(offset, length)
+	if err == ErrSeam {
+		seg.reportRead(offset, length)
+		return seg.gather(offset, length), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	race.ReadRange(uintptr(unsafe.Pointer(&data[0])), uintptr(length))
+	return data, nil
+}
+
+// write returns the byte slice like span and reports it to the race detector as the write access.
+func (seg *Segment) write(offset, length int64) ([]byte, error) {
+	data, err := seg.span(offset, length)
+	if err != nil {
+		return nil, err
+	}
+	race.WriteRange(uintptr(unsafe.Pointer(&data[0])), uintptr(length))
+	return data, nil
+}
+
 // Float32 returns a pointer to the IEEE-754 32-bit floating-point number from this segment
 // or panics at the access violation.
 func (seg *Segment) Float32(offset int64) *float32 {
-	return (*float32)(unsafe.Pointer(seg.Pointer(offset, Float32Size)))
+	return (*float32)(seg.pointer(offset, Float32Size, Float32Size))
 }
 
 // Float64 returns a pointer to the IEEE-754 64-bit floating-point number from this segment
 // or panics at the access violation.
 func (seg *Segment) Float64(offset int64) *float64 {
-	return (*float64)(unsafe.Pointer(seg.Pointer(offset, Float64Size)))
+	return (*float64)(seg.pointer(offset, Float64Size, Float64Size))
 }
 
 // Complex64 returns a pointer to the complex number with float32 real and imaginary parts from this segment
 // or panics at the access violation.
 func (seg *Segment) Complex64(offset int64) *complex64 {
-	return (*complex64)(unsafe.Pointer(seg.Pointer(offset, Complex64Size)))
+	return (*complex64)(seg.pointer(offset, Complex64Size, Float32Size))
 }
 
 // Complex128 returns a pointer to the complex number with float64 real and imaginary parts from this segment
 // or panics at the access violation.
 func (seg *Segment) Complex128(offset int64) *complex128 {
-	return (*complex128)(unsafe.Pointer(seg.Pointer(offset, Complex128Size)))
+	return (*complex128)(seg.pointer(offset, Complex128Size, Float64Size))
 }
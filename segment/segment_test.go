@@ -1,8 +1,17 @@
 package segment
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
 	"math"
+	"strings"
 	"testing"
+	"time"
+	"unsafe"
 )
 
 // Maximal values of the unsigned integer types.
@@ -29,13 +38,14 @@ func TestOffset(t *testing.T) {
 // TestScanUint tests the unsigned integers scanning.
 // CASE: The read values MUST be exactly the same as the previously written.
 func TestScanUint(t *testing.T) {
-	seg := New(0, make([]byte, 16))
+	data := make([]byte, 16)
+	seg := New(0, data)
 	off := int64(1)
 	in8, in16, in32, in64 := maxUint8-1, maxUint16-200, maxUint32-3_000, maxUint64-40_000
-	*seg.Uint8(off) = in8
-	*seg.Uint16(off + Uint8Size) = in16
-	*seg.Uint32(off + Uint8Size + Uint16Size) = in32
-	*seg.Uint64(off + Uint8Size + Uint16Size + Uint32Size) = in64
+	data[off] = in8
+	binary.LittleEndian.PutUint16(data[off+Uint8Size:], in16)
+	binary.LittleEndian.PutUint32(data[off+Uint8Size+Uint16Size:], in32)
+	binary.LittleEndian.PutUint64(data[off+Uint8Size+Uint16Size+Uint32Size:], in64)
 	out8, out16, out32, out64 := uint8(1), uint16(1), uint32(1), uint64(1)
 	if err := seg.ScanUint(off, &out8, &out16, &out32, &out64); err != nil {
 		t.Fatal(err)
@@ -53,3 +63,1179 @@ func TestScanUint(t *testing.T) {
 		t.Fatalf("uint64 value must be %d, %d found", in64, out64)
 	}
 }
+
+// TestPutUint tests the unsigned integers writing.
+// CASE 1: The written values MUST be encoded in the byte order of the segment.
+// CASE 2: The written values MUST be exactly the same as the scanned ones.
+// CASE 3: The value which does not fit the segment MUST NOT be written.
+func TestPutUint(t *testing.T) {
+	data := make([]byte, 16)
+	seg := New(1, data[1:])
+	seg.SetByteOrder(binary.BigEndian)
+	off := int64(2)
+	in8, in16, in32, in64 := maxUint8-1, maxUint16-200, maxUint32-3_000, maxUint64-40_000
+	if err := seg.PutUint(off, in8, in16, in32); err != nil {
+		t.Fatal(err)
+	}
+	if v := binary.BigEndian.Uint32(data[off+Uint8Size+Uint16Size:]); v != in32 {
+		t.Fatalf("big-endian uint32 value must be %d, %d found", in32, v)
+	}
+	out8, out16, out32 := uint8(1), uint16(1), uint32(1)
+	if err := seg.ScanUint(off, &out8, &out16, &out32); err != nil {
+		t.Fatal(err)
+	}
+	if in8 != out8 || in16 != out16 || in32 != out32 {
+		t.Fatalf("values must be %d %d %d, %d %d %d found", in8, in16, in32, out8, out16, out32)
+	}
+	if err := seg.PutUint(9, in64); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds value must not be written, %v found", err)
+	}
+	if err := seg.PutUint(8, in64); err != nil {
+		t.Fatal(err)
+	}
+	if err := seg.PutUint(off, 1); err != ErrBadValue {
+		t.Fatalf("untyped value must not be written, %v found", err)
+	}
+}
+
+// TestFloat tests the floating-point numbers scanning and writing.
+// CASE 1: The read values MUST be exactly the same as the previously written.
+// CASE 2: The values MUST be encoded as the IEEE-754 bits.
+func TestFloat(t *testing.T) {
+	data := make([]byte, 16)
+	seg := New(0, data)
+	in32, in64 := float32(math.Pi), math.Inf(-1)
+	if err := seg.PutFloat(2, in32, in64); err != nil {
+		t.Fatal(err)
+	}
+	if bits := binary.LittleEndian.Uint64(data[2+Float32Size:]); bits != math.Float64bits(in64) {
+		t.Fatalf("float64 bits must be %#x, %#x found", math.Float64bits(in64), bits)
+	}
+	out32, out64 := float32(0), float64(0)
+	if err := seg.ScanFloat(2, &out32, &out64); err != nil {
+		t.Fatal(err)
+	}
+	if in32 != out32 || in64 != out64 {
+		t.Fatalf("values must be %v %v, %v %v found", in32, in64, out32, out64)
+	}
+	if err := seg.ScanFloat(10, &out64); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds value must not be read, %v found", err)
+	}
+	if err := seg.PutFloat(0, 1.0, 1); err != ErrBadValue {
+		t.Fatalf("untyped integer value must not be written, %v found", err)
+	}
+}
+
+// TestComplex tests the complex numbers scanning and writing.
+// CASE 1: The read values MUST be exactly the same as the previously written.
+// CASE 2: The real part MUST precede the imaginary one.
+func TestComplex(t *testing.T) {
+	data := make([]byte, 32)
+	seg := New(0, data)
+	seg.SetByteOrder(binary.BigEndian)
+	in64, in128 := complex64(complex(1.5, -2)), complex(math.MaxFloat64, math.SmallestNonzeroFloat64)
+	if err := seg.PutComplex(1, in64, in128); err != nil {
+		t.Fatal(err)
+	}
+	if bits := binary.BigEndian.Uint32(data[1+Float32Size:]); bits != math.Float32bits(-2) {
+		t.Fatalf("imaginary part bits must be %#x, %#x found", math.Float32bits(-2), bits)
+	}
+	out64, out128 := complex64(0), complex128(0)
+	if err := seg.ScanComplex(1, &out64, &out128); err != nil {
+		t.Fatal(err)
+	}
+	if in64 != out64 || in128 != out128 {
+		t.Fatalf("values must be %v %v, %v %v found", in64, in128, out64, out128)
+	}
+	if err := seg.ScanComplex(17, &out128); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds value must not be read, %v found", err)
+	}
+}
+
+// TestVarint tests the variable-length integers decoding and encoding.
+// CASE 1: The decoded values MUST be exactly the same as the previously encoded.
+// CASE 2: The number of bytes consumed MUST be the same as the number of bytes written.
+// CASE 3: The integer which is not terminated within the segment MUST NOT be decoded.
+// CASE 4: The integer which does not fit the segment MUST NOT be encoded.
+func TestVarint(t *testing.T) {
+	data := make([]byte, 16)
+	seg := New(4, data)
+	n, err := seg.PutUvarint(4, maxUint64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := seg.PutVarint(4+int64(n), -300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, un, err := seg.Uvarint(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u != maxUint64 || un != n {
+		t.Fatalf("unsigned value must be %d of %d bytes, %d of %d bytes found", maxUint64, n, u, un)
+	}
+	v, vn, err := seg.Varint(4 + int64(n))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != -300 || vn != k {
+		t.Fatalf("signed value must be %d of %d bytes, %d of %d bytes found", -300, k, v, vn)
+	}
+	data[15] = 0x80
+	if _, _, err := seg.Uvarint(19); err != ErrOutOfBounds {
+		t.Fatalf("unterminated value must not be decoded, %v found", err)
+	}
+	if _, err := seg.PutUvarint(19, 128); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds value must not be encoded, %v found", err)
+	}
+	if data[15] != 0x80 {
+		t.Fatal("out of bounds value must not be partially encoded")
+	}
+	for i := range data {
+		data[i] = 0xff
+	}
+	if _, _, err := seg.Uvarint(4); err != ErrOverflow {
+		t.Fatalf("overflowing value must not be decoded, %v found", err)
+	}
+}
+
+// TestCString tests the null-terminated strings.
+// CASE 1: The read string MUST be exactly the same as the previously written.
+// CASE 2: The string which fills the maximal length MUST be read entirely.
+// CASE 3: The string which is not terminated within the segment MUST NOT be read.
+func TestCString(t *testing.T) {
+	data := make([]byte, 16)
+	seg := New(0, data)
+	if n, err := seg.PutCString(2, "HELLO"); err != nil || n != 6 {
+		t.Fatalf("string must be written with %d bytes, %d bytes and %v found", 6, n, err)
+	}
+	if s, err := seg.CString(2, 16); err != nil || s != "HELLO" {
+		t.Fatalf("string must be %q, %q and %v found", "HELLO", s, err)
+	}
+	if s, err := seg.CString(2, 3); err != nil || s != "HEL" {
+		t.Fatalf("string must be %q, %q and %v found", "HEL", s, err)
+	}
+	if _, err := seg.PutCString(8, "WORLD\x00"); err != ErrBadValue {
+		t.Fatalf("string with the null byte must not be written, %v found", err)
+	}
+	if _, err := seg.PutCString(8, "WORLDWORLD"); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds string must not be written, %v found", err)
+	}
+	copy(data[8:], "WORLDWOR")
+	if _, err := seg.CString(8, 100); err != ErrOutOfBounds {
+		t.Fatalf("unterminated string must not be read, %v found", err)
+	}
+}
+
+// TestUTF16String tests the UTF-16 strings.
+// CASE 1: The read string MUST be exactly the same as the previously written.
+// CASE 2: The code units MUST be encoded in the byte order of the segment.
+// CASE 3: The string which does not fit the segment MUST NOT be written.
+func TestUTF16String(t *testing.T) {
+	data := make([]byte, 16)
+	seg := New(0, data)
+	in := "Ж€𝄞"
+	n, err := seg.PutUTF16String(2, in)
+	if err != nil || n != 4 {
+		t.Fatalf("string must be written with %d code units, %d code units and %v found", 4, n, err)
+	}
+	if data[2] != 0x16 || data[3] != 0x04 {
+		t.Fatalf("first code unit must be little-endian, %#x %#x found", data[2], data[3])
+	}
+	if out, err := seg.UTF16String(2, n); err != nil || out != in {
+		t.Fatalf("string must be %q, %q and %v found", in, out, err)
+	}
+	seg.SetByteOrder(binary.BigEndian)
+	if _, err := seg.PutUTF16String(0, "Ж"); err != nil {
+		t.Fatal(err)
+	}
+	if data[0] != 0x04 || data[1] != 0x16 {
+		t.Fatalf("code unit must be big-endian, %#x %#x found", data[0], data[1])
+	}
+	if _, err := seg.PutUTF16String(10, "WORLD"); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds string must not be written, %v found", err)
+	}
+}
+
+// TestTime tests the timestamps.
+// CASE 1: The read timestamp MUST be the same as the previously written one truncated to the encoding precision.
+// CASE 2: The timestamp which does not fit the encoding MUST NOT be written.
+func TestTime(t *testing.T) {
+	data := make([]byte, 16)
+	seg := New(0, data)
+	in := time.Date(2024, 2, 29, 12, 30, 45, 123_456_789, time.UTC)
+	for enc, precision := range map[TimeEncoding]time.Duration{
+		TimeUnix:      time.Second,
+		TimeUnixMilli: time.Millisecond,
+		TimeUnixMicro: time.Microsecond,
+		TimeUnixNano:  time.Nanosecond,
+	} {
+		if err := seg.PutTime(8, in, enc); err != nil {
+			t.Fatal(err)
+		}
+		out, err := seg.Time(8, enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := in.Truncate(precision); !out.Equal(expected) {
+			t.Fatalf("timestamp must be %v, %v found", expected, out)
+		}
+	}
+	if err := seg.PutTime(0, time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC), TimeUnixNano); err != ErrOverflow {
+		t.Fatalf("overflowing timestamp must not be written, %v found", err)
+	}
+	if err := seg.PutTime(9, in, TimeUnix); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds timestamp must not be written, %v found", err)
+	}
+}
+
+// TestUUID tests the UUIDs.
+// CASE 1: The read UUID MUST be exactly the same as the previously written.
+// CASE 2: The GUID MUST be stored in the mixed-endian layout.
+func TestUUID(t *testing.T) {
+	data := make([]byte, 40)
+	seg := New(0, data)
+	// 00112233-4455-6677-8899-aabbccddeeff
+	in := [UUIDSize]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}
+	if err := seg.PutUUID(1, in); err != nil {
+		t.Fatal(err)
+	}
+	if err := seg.PutGUID(20, in); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := seg.UUID(1); err != nil || out != in {
+		t.Fatalf("UUID must be %x, %x and %v found", in, out, err)
+	}
+	if out, err := seg.GUID(20); err != nil || out != in {
+		t.Fatalf("GUID must be %x, %x and %v found", in, out, err)
+	}
+	mixed := []byte{0x33, 0x22, 0x11, 0x00, 0x55, 0x44, 0x77, 0x66, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}
+	if string(data[20:36]) != string(mixed) {
+		t.Fatalf("GUID must be stored as %x, %x found", mixed, data[20:36])
+	}
+	if _, err := seg.UUID(30); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds UUID must not be read, %v found", err)
+	}
+}
+
+// TestGeneric tests the generic accessors.
+// CASE 1: The read values MUST be exactly the same as the previously written.
+// CASE 2: The values MUST be encoded like by the sequential accessors.
+// CASE 3: The value of the named type MUST be accessed like the one of its underlying type.
+func TestGeneric(t *testing.T) {
+	type celsius float32
+	data := make([]byte, 32)
+	seg := New(0, data)
+	seg.SetByteOrder(binary.BigEndian)
+	if err := Put(seg, 0, int16(-2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := Put(seg, 2, complex64(complex(1, -1))); err != nil {
+		t.Fatal(err)
+	}
+	if err := Put(seg, 10, celsius(36.6)); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := Get[int16](seg, 0); err != nil || v != -2 {
+		t.Fatalf("int16 value must be %d, %d and %v found", -2, v, err)
+	}
+	var c complex64
+	if err := seg.ScanComplex(2, &c); err != nil || c != complex(1, -1) {
+		t.Fatalf("complex64 value must be %v, %v and %v found", complex(1, -1), c, err)
+	}
+	if v, err := Get[complex64](seg, 2); err != nil || v != c {
+		t.Fatalf("complex64 value must be %v, %v and %v found", c, v, err)
+	}
+	if v, err := Get[celsius](seg, 10); err != nil || v != 36.6 {
+		t.Fatalf("celsius value must be %v, %v and %v found", 36.6, v, err)
+	}
+	if err := Put(seg, 24, uint64(1)); err != nil {
+		t.Fatal(err)
+	}
+	if data[31] != 1 {
+		t.Fatal("uint64 value must be big-endian")
+	}
+	if _, err := Get[complex128](seg, 24); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds value must not be read, %v found", err)
+	}
+}
+
+// TestView tests the typed views.
+// CASE 1: The changes through the view MUST be visible in the segment data and vice versa.
+// CASE 2: The misaligned view MUST NOT be returned.
+// CASE 3: The view of the type with pointers MUST NOT be returned.
+func TestView(t *testing.T) {
+	type header struct {
+		Magic   [4]byte
+		Version uint16
+		Flags   uint16
+		Length  uint64
+	}
+	data := make([]uint64, 4)
+	seg := New(8, unsafe.Slice((*byte)(unsafe.Pointer(&data[0])), 32))
+	h, err := View[header](seg, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Length = math.MaxUint64
+	if data[2] != math.MaxUint64 {
+		t.Fatalf("length must be %d, %d found", uint64(math.MaxUint64), data[2])
+	}
+	data[1] = 0
+	if h.Magic != [4]byte{} {
+		t.Fatalf("magic must be zero, %v found", h.Magic)
+	}
+	if _, err := View[header](seg, 32); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds view must not be returned, %v found", err)
+	}
+	if _, err := View[header](seg, 12); err != ErrMisaligned {
+		t.Fatalf("misaligned view must not be returned, %v found", err)
+	}
+	if _, err := View[struct{ Name string }](seg, 8); err != ErrBadValue {
+		t.Fatalf("view with pointers must not be returned, %v found", err)
+	}
+}
+
+// TestStruct tests the reflection-based struct reading and writing.
+// CASE 1: The read struct MUST be exactly the same as the previously written.
+// CASE 2: The struct MUST be packed in the byte order of the segment.
+// CASE 3: The bytes of the blank fields MUST be left intact.
+// CASE 4: The struct with the unexported or the variable-size fields MUST NOT be accessed.
+func TestStruct(t *testing.T) {
+	type point struct {
+		X, Y int16
+	}
+	type record struct {
+		Tag    [3]byte
+		Valid  bool
+		_      [2]byte
+		Points [2]point
+		Scale  float64
+	}
+	data := make([]byte, 24)
+	seg := New(0, data)
+	seg.SetByteOrder(binary.BigEndian)
+	data[4], data[5] = 0xaa, 0xbb
+	in := record{Tag: [3]byte{'a', 'b', 'c'}, Valid: true, Points: [2]point{{1, -1}, {2, -2}}, Scale: 0.5}
+	n, err := seg.WriteStruct(0, in)
+	if err != nil || n != 22 {
+		t.Fatalf("struct must be written with %d bytes, %d bytes and %v found", 22, n, err)
+	}
+	if data[4] != 0xaa || data[5] != 0xbb {
+		t.Fatal("blank field must be left intact")
+	}
+	if v := binary.BigEndian.Uint16(data[12:]); v != 0xfffe {
+		t.Fatalf("int16 value must be big-endian %#x, %#x found", 0xfffe, v)
+	}
+	var out record
+	if n, err := seg.ReadStruct(0, &out); err != nil || n != 22 || out != in {
+		t.Fatalf("struct must be %+v of %d bytes, %+v of %d bytes and %v found", in, 22, out, n, err)
+	}
+	if _, err := seg.ReadStruct(4, &out); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds struct must not be read, %v found", err)
+	}
+	if _, err := seg.ReadStruct(0, out); err != ErrBadValue {
+		t.Fatalf("struct must not be read by value, %v found", err)
+	}
+	if _, err := seg.WriteStruct(0, struct{ x int32 }{}); err != ErrBadValue {
+		t.Fatalf("struct with unexported field must not be written, %v found", err)
+	}
+	if _, err := seg.WriteStruct(0, struct{ N int }{}); err != ErrBadValue {
+		t.Fatalf("struct with variable-size field must not be written, %v found", err)
+	}
+}
+
+// TestStructTags tests the struct layout declared by the tags.
+// CASE 1: The fields MUST be placed at the declared offsets with the gaps left intact.
+// CASE 2: The fields MUST be encoded in the declared byte order and length.
+// CASE 3: The excluded field MUST NOT be accessed.
+// CASE 4: The struct with the bad tag MUST NOT be accessed.
+func TestStructTags(t *testing.T) {
+	type header struct {
+		Magic   [2]byte `bio:"off=0"`
+		Version uint16
+		Length  int32   `bio:"off=8,len=3,be"`
+		Flags   uint16  `bio:"le"`
+		Name    [4]byte `bio:"len=6"`
+		cache   string  `bio:"-"`
+	}
+	data := make([]byte, 24)
+	for i := range data {
+		data[i] = 0xee
+	}
+	seg := New(0, data)
+	seg.SetByteOrder(binary.BigEndian)
+	in := header{Magic: [2]byte{'B', 'M'}, Version: 2, Length: -2, Flags: 1, Name: [4]byte{'n', 'a', 'm', 'e'}, cache: "x"}
+	n, err := seg.WriteStruct(0, &in)
+	if err != nil || n != 19 {
+		t.Fatalf("struct must be written with %d bytes, %d bytes and %v found", 19, n, err)
+	}
+	expected := []byte{'B', 'M', 0, 2, 0xee, 0xee, 0xee, 0xee, 0xff, 0xff, 0xfe, 1, 0, 'n', 'a', 'm', 'e', 0xee, 0xee, 0xee}
+	if !bytes.Equal(data[:20], expected) {
+		t.Fatalf("data must be %x, %x found", expected, data[:20])
+	}
+	var out header
+	if _, err := seg.ReadStruct(0, &out); err != nil {
+		t.Fatal(err)
+	}
+	in.cache = ""
+	if out != in {
+		t.Fatalf("struct must be %+v, %+v found", in, out)
+	}
+	if _, err := seg.ReadStruct(0, &struct {
+		Length uint32 `bio:"len=9"`
+	}{}); err != ErrBadValue {
+		t.Fatalf("struct with bad tag must not be read, %v found", err)
+	}
+}
+
+// TestSchema tests the schema.
+// CASE 1: The auto-placed fields MUST be naturally aligned and the size MUST be rounded up to the alignment.
+// CASE 2: The value read by name MUST be exactly the same as the previously written.
+// CASE 3: The value of another type MUST NOT be written.
+func TestSchema(t *testing.T) {
+	schema, err := NewSchema(
+		Field{Name: "kind", Type: FieldUint8, Offset: AutoOffset},
+		Field{Name: "count", Type: FieldUint32, Offset: AutoOffset, Order: binary.BigEndian},
+		Field{Name: "name", Type: FieldBytes, Offset: AutoOffset, Length: 5},
+		Field{Name: "value", Type: FieldFloat64, Offset: AutoOffset},
+		Field{Name: "alias", Type: FieldUint16, Offset: 4},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, _ := schema.Field("value"); f.Offset != 16 {
+		t.Fatalf("value offset must be %d, %d found", 16, f.Offset)
+	}
+	if schema.Size() != 24 || schema.Align() != 8 {
+		t.Fatalf("size and alignment must be %d and %d, %d and %d found", 24, 8, schema.Size(), schema.Align())
+	}
+	data := make([]byte, 2*schema.Size())
+	seg := New(0, data)
+	if err := schema.Set(seg, schema.Size(), "count", uint32(0x01020304)); err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.Set(seg, schema.Size(), "name", []byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := schema.Get(seg, schema.Size(), "count"); err != nil || v != uint32(0x01020304) {
+		t.Fatalf("count must be %#x, %v and %v found", 0x01020304, v, err)
+	}
+	if v, err := schema.Get(seg, schema.Size(), "alias"); err != nil || v != uint16(0x0201) {
+		t.Fatalf("alias must be %#x, %v and %v found", 0x0201, v, err)
+	}
+	if v, err := schema.Get(seg, schema.Size(), "name"); err != nil || string(v.([]byte)) != "abc\x00\x00" {
+		t.Fatalf("name must be %q, %q and %v found", "abc\x00\x00", v, err)
+	}
+	if err := schema.Set(seg, 0, "value", float32(1)); err != ErrBadValue {
+		t.Fatalf("value of another type must not be written, %v found", err)
+	}
+	if _, err := schema.Get(seg, schema.Size()+1, "value"); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds value must not be read, %v found", err)
+	}
+	if _, err := NewSchema(Field{Name: "a"}, Field{Name: "a"}); err != ErrBadValue {
+		t.Fatalf("schema with duplicate names must not be created, %v found", err)
+	}
+}
+
+// TestBytes tests the live byte slices.
+// CASE 1: The changes through the slice MUST be visible in the segment data.
+// CASE 2: The out of bounds slice MUST NOT be returned.
+func TestBytes(t *testing.T) {
+	data := make([]byte, 8)
+	seg := New(8, data)
+	b, err := seg.Bytes(10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[1] = 1
+	if data[3] != 1 {
+		t.Fatal("changes through the slice must be visible in the segment data")
+	}
+	if _, err := seg.Bytes(16, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := seg.Bytes(15, 2); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds slice must not be returned, %v found", err)
+	}
+}
+
+// TestBits tests the bit fields.
+// CASE 1: The bit field which spans across the bytes MUST be read and written in both bit orders.
+// CASE 2: The other bits of the spanned bytes MUST be left intact.
+func TestBits(t *testing.T) {
+	data := []byte{0xff, 0x00, 0xff}
+	seg := New(0, data)
+	if err := seg.SetBits(0, 6, 5, 0b10100); err != nil {
+		t.Fatal(err)
+	}
+	// LSB first: the bits 6..10 are 0, 0, 1, 0, 1.
+	if data[0] != 0x3f || data[1] != 0x05 || data[2] != 0xff {
+		t.Fatalf("data must be %x, %x found", []byte{0x3f, 0x05, 0xff}, data)
+	}
+	if v, err := seg.Bits(0, 6, 5); err != nil || v != 0b10100 {
+		t.Fatalf("bit field must be %b, %b and %v found", 0b10100, v, err)
+	}
+	seg.SetBitOrder(MSBFirst)
+	if err := seg.SetBits(1, 6, 4, 0b1001); err != nil {
+		t.Fatal(err)
+	}
+	// MSB first: the bits 6..9 of the second byte are 1, 0, 0, 1.
+	if data[1] != 0x06 || data[2] != 0x7f {
+		t.Fatalf("data must be %x, %x found", []byte{0x06, 0x7f}, data[1:])
+	}
+	if v, err := seg.Bits(1, 6, 4); err != nil || v != 0b1001 {
+		t.Fatalf("bit field must be %b, %b and %v found", 0b1001, v, err)
+	}
+	if err := seg.SetBit(2, 0, false); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := seg.Bit(0, 16); err != nil || v || data[2] != 0x7f {
+		t.Fatalf("bit must be cleared, %v %x and %v found", v, data[2], err)
+	}
+	if _, err := seg.Bits(2, 4, 8); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds bit field must not be read, %v found", err)
+	}
+	if _, err := seg.Bits(0, 0, 65); err != ErrBadValue {
+		t.Fatalf("too wide bit field must not be read, %v found", err)
+	}
+}
+
+// TestBitStream tests the bit stream reading and writing.
+// CASE 1: The read bit fields MUST be exactly the same as the previously written in both bit orders.
+// CASE 2: The alignment MUST pad the rest of the byte with zero bits.
+// CASE 3: The reading beyond the end of the segment MUST return io.EOF.
+func TestBitStream(t *testing.T) {
+	for _, order := range []BitOrder{LSBFirst, MSBFirst} {
+		data := make([]byte, 4)
+		for i := range data {
+			data[i] = 0xff
+		}
+		seg := New(0, data)
+		w := NewBitWriter(seg, 0, order)
+		if err := w.WriteBits(0b101, 3); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WriteBits(0x1234, 13); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WriteBit(false); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Align(); err != nil {
+			t.Fatal(err)
+		}
+		if w.Offset() != 3 || w.Bit() != 0 {
+			t.Fatalf("position must be %d:%d, %d:%d found", 3, 0, w.Offset(), w.Bit())
+		}
+		if data[2] != 0 {
+			t.Fatalf("padding must be zero, %08b found", data[2])
+		}
+		r := NewBitReader(seg, 0, order)
+		if v, err := r.ReadBits(3); err != nil || v != 0b101 {
+			t.Fatalf("bit field must be %b, %b and %v found", 0b101, v, err)
+		}
+		if v, err := r.ReadBits(13); err != nil || v != 0x1234 {
+			t.Fatalf("bit field must be %x, %x and %v found", 0x1234, v, err)
+		}
+		r.Align()
+		r.Skip(8)
+		if v, err := r.ReadBits(8); err != nil || v != 0xff {
+			t.Fatalf("bit field must be %x, %x and %v found", 0xff, v, err)
+		}
+		if _, err := r.ReadBit(); err != io.EOF {
+			t.Fatalf("reading beyond the end must return io.EOF, %v found", err)
+		}
+		if err := w.WriteBits(0, 9); err != ErrOutOfBounds {
+			t.Fatalf("out of bounds bit field must not be written, %v found", err)
+		}
+	}
+}
+
+// TestSlice tests the child segments.
+// CASE 1: The child segment MUST address the values by the same offsets as the parent one.
+// CASE 2: The child segment MUST NOT access the data outside its bounds.
+// CASE 3: The byte order MUST be inherited.
+func TestSlice(t *testing.T) {
+	data := make([]byte, 16)
+	seg := New(100, data)
+	seg.SetByteOrder(binary.BigEndian)
+	child, err := seg.Slice(104, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := child.PutUint(104, uint32(1)); err != nil {
+		t.Fatal(err)
+	}
+	if data[7] != 1 {
+		t.Fatal("child segment must write big-endian value at the same offset")
+	}
+	if err := child.PutUint(106, uint32(1)); err != ErrOutOfBounds {
+		t.Fatalf("child segment must not write outside its bounds, %v found", err)
+	}
+	if _, err := child.Bytes(100, 1); err != ErrOutOfBounds {
+		t.Fatalf("child segment must not read before its bounds, %v found", err)
+	}
+	if _, err := seg.Slice(112, 5); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds child segment must not be returned, %v found", err)
+	}
+}
+
+// TestReadWriteAt tests the segment as io.ReaderAt and io.WriterAt.
+// CASE 1: The read bytes MUST be exactly the same as the previously written.
+// CASE 2: The access beyond the bounds MUST return ErrOutOfBounds and MUST NOT transfer any bytes.
+func TestReadWriteAt(t *testing.T) {
+	data := make([]byte, 8)
+	var seg interface {
+		io.ReaderAt
+		io.WriterAt
+	} = New(8, data)
+	if n, err := seg.WriteAt([]byte("HELLO"), 10); err != nil || n != 5 {
+		t.Fatalf("%d bytes must be written, %d bytes and %v found", 5, n, err)
+	}
+	buf := make([]byte, 5)
+	if n, err := seg.ReadAt(buf, 10); err != nil || n != 5 || string(buf) != "HELLO" {
+		t.Fatalf("%q must be read, %q of %d bytes and %v found", "HELLO", buf, n, err)
+	}
+	if n, err := seg.WriteAt([]byte("WORLD"), 12); err != ErrOutOfBounds || n != 0 || data[7] != 0 {
+		t.Fatalf("out of bounds bytes must not be written, %d bytes and %v found", n, err)
+	}
+	if n, err := seg.ReadAt(buf, 4); err != ErrOutOfBounds || n != 0 {
+		t.Fatalf("out of bounds bytes must not be read, %d bytes and %v found", n, err)
+	}
+}
+
+// TestCursor tests the sequential cursor.
+// CASE 1: The bytes written through the cursor MUST be read back from the same positions.
+// CASE 2: The partial write at the end of the segment MUST return ErrOutOfBounds.
+// CASE 3: The reading at the end of the segment MUST return io.EOF.
+func TestCursor(t *testing.T) {
+	data := make([]byte, 8)
+	seg := New(10, data)
+	c := seg.Cursor(10)
+	if err := c.WriteByte(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Align(4); err != nil {
+		t.Fatal(err)
+	}
+	if c.Position() != 12 {
+		t.Fatalf("position must be %d, %d found", 12, c.Position())
+	}
+	if n, err := c.Write([]byte("HELLO WORLD")); err != ErrOutOfBounds || n != 6 {
+		t.Fatalf("%d bytes must be written with ErrOutOfBounds, %d bytes and %v found", 6, n, err)
+	}
+	if _, err := c.Seek(10, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := c.ReadByte(); err != nil || b != 1 {
+		t.Fatalf("byte must be %d, %d and %v found", 1, b, err)
+	}
+	if err := c.Skip(1); err != nil {
+		t.Fatal(err)
+	}
+	out, err := io.ReadAll(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "HELLO " {
+		t.Fatalf("data must be %q, %q found", "HELLO ", out)
+	}
+	if _, err := c.Seek(-1, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Seek(-100, io.SeekCurrent); err != ErrBadOffset {
+		t.Fatalf("negative position must not be set, %v found", err)
+	}
+}
+
+// TestFill tests the filling and zeroing.
+// CASE 1: The range MUST be filled exactly.
+// CASE 2: The out of bounds range MUST NOT be filled.
+func TestFill(t *testing.T) {
+	data := bytes.Repeat([]byte{0xee}, 100)
+	seg := New(0, data)
+	if err := seg.Fill(3, 90, 0xaa); err != nil {
+		t.Fatal(err)
+	}
+	if data[2] != 0xee || data[93] != 0xee || !bytes.Equal(data[3:93], bytes.Repeat([]byte{0xaa}, 90)) {
+		t.Fatalf("range must be filled exactly, %x found", data)
+	}
+	if err := seg.Zero(10, 5); err != nil {
+		t.Fatal(err)
+	}
+	if data[9] != 0xaa || data[15] != 0xaa || !bytes.Equal(data[10:15], make([]byte, 5)) {
+		t.Fatalf("range must be zeroed exactly, %x found", data)
+	}
+	if err := seg.Zero(90, 11); err != ErrOutOfBounds || data[90] != 0xaa {
+		t.Fatalf("out of bounds range must not be zeroed, %v found", err)
+	}
+}
+
+// TestCompare tests the comparison.
+// CASE 1: The equal bytes MUST be reported as equal with the end offset.
+// CASE 2: The first divergent offset MUST be reported with the comparison result.
+// CASE 3: The out of bounds range MUST NOT be equal.
+func TestCompare(t *testing.T) {
+	data := bytes.Repeat([]byte("abcdefgh"), 20)
+	seg := New(10, data)
+	key := append([]byte(nil), data[5:155]...)
+	if !seg.Equal(15, key) {
+		t.Fatal("equal bytes must be reported as equal")
+	}
+	if c, off, err := seg.Compare(15, key); err != nil || c != 0 || off != 165 {
+		t.Fatalf("comparison must be %d at %d, %d at %d and %v found", 0, 165, c, off, err)
+	}
+	key[100] = 'z'
+	if c, off, err := seg.Compare(15, key); err != nil || c != -1 || off != 115 {
+		t.Fatalf("comparison must be %d at %d, %d at %d and %v found", -1, 115, c, off, err)
+	}
+	if seg.Equal(15, key) {
+		t.Fatal("divergent bytes must not be reported as equal")
+	}
+	if seg.Equal(160, []byte("abcdefghijk")) {
+		t.Fatal("out of bounds bytes must not be reported as equal")
+	}
+	if _, _, err := seg.Compare(9, []byte("a")); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds bytes must not be compared, %v found", err)
+	}
+}
+
+// TestIndex tests the byte search.
+// CASE 1: The offset of the first instance MUST be returned.
+// CASE 2: The instance outside the range MUST NOT be found.
+func TestIndex(t *testing.T) {
+	seg := New(100, []byte("GET / HTTP/1.1\r\nHost: x\r\n\r\n"))
+	if i, err := seg.IndexByte(100, 27, '\n'); err != nil || i != 115 {
+		t.Fatalf("offset must be %d, %d and %v found", 115, i, err)
+	}
+	if i, err := seg.Index(116, 11, []byte("\r\n\r\n")); err != nil || i != 123 {
+		t.Fatalf("offset must be %d, %d and %v found", 123, i, err)
+	}
+	if i, err := seg.Index(116, 10, []byte("\r\n\r\n")); err != nil || i != -1 {
+		t.Fatalf("pattern outside the range must not be found, %d and %v found", i, err)
+	}
+	if _, err := seg.IndexByte(120, 8, 'x'); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds range must not be searched, %v found", err)
+	}
+}
+
+// TestSearch tests the binary search over the records.
+// CASE 1: The index of the matching record MUST be found.
+// CASE 2: The insertion index of the missing record MUST be returned.
+// CASE 3: The array which does not fit the segment MUST NOT be searched.
+func TestSearch(t *testing.T) {
+	const recordSize = 6
+	data := make([]byte, 10*recordSize+3)
+	seg := New(1000, data)
+	for i := 0; i < 10; i++ {
+		if err := seg.PutUint(1000+int64(i*recordSize), uint32(i*10), uint16(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	search := func(key uint32) (int, bool, error) {
+		return seg.Search(recordSize, 10, func(rec *Segment) int {
+			var v uint32
+			if err := rec.ScanUint(rec.Offset(), &v); err != nil {
+				t.Fatal(err)
+			}
+			return int(int64(v) - int64(key))
+		})
+	}
+	if i, found, err := search(70); err != nil || i != 7 || !found {
+		t.Fatalf("record must be found at %d, %d %v and %v found", 7, i, found, err)
+	}
+	if i, found, err := search(75); err != nil || i != 8 || found {
+		t.Fatalf("record must be inserted at %d, %d %v and %v found", 8, i, found, err)
+	}
+	if i, found, err := search(1000); err != nil || i != 10 || found {
+		t.Fatalf("record must be inserted at %d, %d %v and %v found", 10, i, found, err)
+	}
+	if _, _, err := seg.Search(recordSize, 11, nil); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds array must not be searched, %v found", err)
+	}
+}
+
+// TestChecksum tests the checksums.
+// CASE 1: The checksum MUST be the same as the one of the copied bytes.
+// CASE 2: The out of bounds range MUST NOT be checksummed.
+func TestChecksum(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 300_000)
+	seg := New(0, data)
+	sum, err := seg.CRC32(7, uintptr(len(data)-10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := crc32.ChecksumIEEE(data[7 : len(data)-3]); sum != expected {
+		t.Fatalf("checksum must be %#x, %#x found", expected, sum)
+	}
+	h := sha256.New()
+	if err := seg.Hash(0, 10, h); err != nil {
+		t.Fatal(err)
+	}
+	if expected := sha256.Sum256([]byte("0123456789")); !bytes.Equal(h.Sum(nil), expected[:]) {
+		t.Fatalf("hash must be %x, %x found", expected, h.Sum(nil))
+	}
+	if _, err := seg.CRC32(1, uintptr(len(data))); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds range must not be checksummed, %v found", err)
+	}
+}
+
+// TestRecords tests the record iterator.
+// CASE 1: Every record MUST be yielded in order with its own bounds.
+// CASE 2: The iteration MUST stop when yield returns false.
+// CASE 3: The array which does not fit the segment MUST NOT be iterated.
+func TestRecords(t *testing.T) {
+	data := []byte("aaaBBBcccDDD")
+	seg := New(2, data)
+	records, err := seg.Records(5, 3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	records(func(i int, rec *Segment) bool {
+		buf := make([]byte, 3)
+		if _, err := rec.ReadAt(buf, 5+int64(i)*3); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rec.ReadAt(buf[:1], 4+int64(i)*3); err != ErrOutOfBounds {
+			t.Fatalf("record must not be accessed outside its bounds, %v found", err)
+		}
+		out = append(out, string(buf))
+		return i < 1
+	})
+	if strings.Join(out, ",") != "BBB,ccc" {
+		t.Fatalf("records must be %q, %q found", "BBB,ccc", strings.Join(out, ","))
+	}
+	if _, err := seg.Records(5, 3, 4); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds array must not be iterated, %v found", err)
+	}
+}
+
+// TestStrict tests the strict mode.
+// CASE 1: The misaligned typed pointer MUST be handed out out of the strict mode.
+// CASE 2: The misaligned typed pointer MUST panic with ErrMisaligned in the strict mode.
+// CASE 3: The aligned typed pointer MUST be handed out in the strict mode.
+// CASE 4: The strict mode MUST be inherited by the child segment.
+func TestStrict(t *testing.T) {
+	words := make([]uint64, 4)
+	data := unsafe.Slice((*byte)(unsafe.Pointer(&words[0])), len(words)*Uint64Size)
+	seg := New(0, data)
+	misaligned := func(seg *Segment, f func()) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				var ok bool
+				if err, ok = r.(error); !ok {
+					t.Fatalf("panic must be an error, %v found", r)
+				}
+			}
+		}()
+		f()
+		return nil
+	}
+	if err := misaligned(seg, func() { *seg.Uint32(2) = 1 }); err != nil {
+		t.Fatalf("misaligned pointer must be handed out out of the strict mode, %v found", err)
+	}
+	seg.SetStrict(true)
+	if !seg.Strict() {
+		t.Fatal("segment must be in the strict mode")
+	}
+	if err := misaligned(seg, func() { seg.Uint64(4) }); !errors.Is(err, ErrMisaligned) {
+		t.Fatalf("misaligned pointer must panic with %v, %v found", ErrMisaligned, err)
+	}
+	if err := misaligned(seg, func() { seg.Complex128(8) }); err != nil {
+		t.Fatalf("aligned pointer must be handed out in the strict mode, %v found", err)
+	}
+	if err := misaligned(seg, func() { seg.Complex64(12) }); err != nil {
+		t.Fatalf("aligned pointer must be handed out in the strict mode, %v found", err)
+	}
+	child, err := seg.Slice(1, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := misaligned(child, func() { child.Uint16(1) }); !errors.Is(err, ErrMisaligned) {
+		t.Fatalf("strict mode must be inherited, %v found", err)
+	}
+}
+
+// TestSwap tests the in-place byte swapping.
+// CASE 1: The bytes of every value MUST be reversed.
+// CASE 2: The bytes outside the array MUST be left intact.
+// CASE 3: The array which does not fit the segment MUST NOT be swapped.
+func TestSwap(t *testing.T) {
+	data := make([]byte, 1+2*Uint64Size)
+	seg := New(10, data)
+	seg.SetByteOrder(binary.BigEndian)
+	if err := seg.PutUint(11, uint64(0x0102030405060708), uint64(0x1112131415161718)); err != nil {
+		t.Fatal(err)
+	}
+	seg.SetByteOrder(binary.LittleEndian)
+	if err := seg.SwapUint64s(11, 2); err != nil {
+		t.Fatal(err)
+	}
+	var a, b uint64
+	if err := seg.ScanUint(11, &a, &b); err != nil {
+		t.Fatal(err)
+	}
+	if a != 0x0102030405060708 || b != 0x1112131415161718 {
+		t.Fatalf("values must be swapped, %#x and %#x found", a, b)
+	}
+	if err := seg.SwapUint32s(11, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := seg.SwapUint16s(11, 8); err != nil {
+		t.Fatal(err)
+	}
+	expected := []byte{0, 0x06, 0x05, 0x08, 0x07, 0x02, 0x01, 0x04, 0x03, 0x16, 0x15, 0x18, 0x17, 0x12, 0x11, 0x14, 0x13}
+	if !bytes.Equal(data, expected) {
+		t.Fatalf("data must be %x, %x found", expected, data)
+	}
+	if err := seg.SwapUint32s(12, 4); err != ErrOutOfBounds {
+		t.Fatalf("out of bounds array must not be swapped, %v found", err)
+	}
+	if !bytes.Equal(data, expected) {
+		t.Fatalf("data must be left intact, %x found", data)
+	}
+}
+
+// TestFixed tests the fixed-point accessors.
+// CASE 1: The written value MUST be read back scaled by the fractional bits.
+// CASE 2: The value MUST be rounded to the nearest representable number.
+// CASE 3: The unrepresentable value MUST NOT be written.
+func TestFixed(t *testing.T) {
+	data := make([]byte, Int32Size+Int64Size)
+	seg := New(0, data)
+	seg.SetByteOrder(binary.BigEndian)
+	if err := seg.PutFixed32(0, -1.75, 16); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data[:Int32Size], []byte{0xff, 0xfe, 0x40, 0x00}) {
+		t.Fatalf("fixed-point number must be encoded, %x found", data[:Int32Size])
+	}
+	if v, err := seg.Fixed32(0, 16); err != nil || v != -1.75 {
+		t.Fatalf("fixed-point number must be -1.75, %v (%v) found", v, err)
+	}
+	if err := seg.PutFixed64(Int32Size, 12.3456, 2); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := seg.Fixed64(Int32Size, 2); err != nil || v != 12.25 {
+		t.Fatalf("fixed-point number must be 12.25, %v (%v) found", v, err)
+	}
+	if err := seg.PutFixed32(0, 32768, 16); err != ErrOverflow {
+		t.Fatalf("unrepresentable value must not be written, %v found", err)
+	}
+	if err := seg.PutFixed64(Int32Size, math.NaN(), 0); err != ErrOverflow {
+		t.Fatalf("unrepresentable value must not be written, %v found", err)
+	}
+	if err := seg.PutFixed64(Int32Size, math.Ldexp(1, 63), 0); err != ErrOverflow {
+		t.Fatalf("unrepresentable value must not be written, %v found", err)
+	}
+	if v, err := seg.Fixed32(0, 16); err != nil || v != -1.75 {
+		t.Fatalf("fixed-point number must be left intact, %v (%v) found", v, err)
+	}
+	if _, err := seg.Fixed32(0, 32); err != ErrBadValue {
+		t.Fatalf("fractional bits must be validated, %v found", err)
+	}
+}
+
+// TestFault tests the access violation.
+// CASE 1: The access violation MUST panic with the FaultError which describes the request and the bounds.
+// CASE 2: The FaultError MUST match Fault.
+func TestFault(t *testing.T) {
+	seg := New(100, make([]byte, 8))
+	err := func() (err error) {
+		defer func() {
+			err, _ = recover().(error)
+		}()
+		seg.Uint32(106)
+		return nil
+	}()
+	var fault *FaultError
+	if !errors.As(err, &fault) {
+		t.Fatalf("access violation must panic with the fault error, %v found", err)
+	}
+	expected := FaultError{Offset: 106, Length: Uint32Size, SegmentOffset: 100, SegmentLen: 8}
+	if *fault != expected {
+		t.Fatalf("fault error must be %+v, %+v found", expected, *fault)
+	}
+	if !errors.Is(err, Fault) {
+		t.Fatalf("fault error must match %v", Fault)
+	}
+}
+
+// TestChain tests the chained segment.
+// CASE 1: The values MUST be scanned across the seam.
+// CASE 2: The cursor MUST read and write across the seam.
+// CASE 3: The values which are modified in place MUST NOT cross the seam.
+// CASE 4: The child segment MUST be sliced across the seam.
+// CASE 5: The data MUST be filled across the seam.
+func TestChain(t *testing.T) {
+	a, b, c := []byte("abc"), []byte("defg"), []byte("hi")
+	seg := NewChain(10, a, nil, b, c)
+	seg.SetByteOrder(binary.BigEndian)
+	var v uint32
+	if err := seg.ScanUint(12, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v != 0x63646566 {
+		t.Fatalf("value must be %#x, %#x found", 0x63646566, v)
+	}
+	if s, err := seg.CString(11, 8); err != nil || s != "bcdefghi" {
+		t.Fatalf("string must be %q, %q (%v) found", "bcdefghi", s, err)
+	}
+	data, err := io.ReadAll(seg.Cursor(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "abcdefghi" {
+		t.Fatalf("data must be %q, %q found", "abcdefghi", data)
+	}
+	cur := seg.Cursor(15)
+	if n, err := cur.Write([]byte("XYZ!")); n != 4 || err != nil {
+		t.Fatalf("cursor must write 4 bytes, %d (%v) found", n, err)
+	}
+	if string(b) != "deXY" || string(c) != "Z!" {
+		t.Fatalf("slices must be written across the seam, %q and %q found", b, c)
+	}
+	if err := seg.PutUint(12, uint16(1)); err != ErrSeam {
+		t.Fatalf("value must not be put across the seam, %v found", err)
+	}
+	if _, err := seg.Bytes(12, 2); err != ErrSeam {
+		t.Fatalf("live slice must not cross the seam, %v found", err)
+	}
+	if err := seg.PutUint(13, uint16(0x3132)); err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "12XY" {
+		t.Fatalf("value must be put within the slice, %q found", b)
+	}
+	err = func() (err error) {
+		defer func() {
+			err, _ = recover().(error)
+		}()
+		seg.Uint32(15)
+		return nil
+	}()
+	if !errors.Is(err, ErrSeam) {
+		t.Fatalf("typed pointer must not cross the seam, %v found", err)
+	}
+	child, err := seg.Slice(12, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err := child.ReadAt(buf, 12); err != nil || string(buf) != "c12X" {
+		t.Fatalf("child data must be %q, %q (%v) found", "c12X", buf, err)
+	}
+	if _, err := child.ReadAt(buf[:1], 16); err != ErrOutOfBounds {
+		t.Fatalf("child must not be accessed outside its bounds, %v found", err)
+	}
+	if err := seg.Fill(11, 7, '.'); err != nil {
+		t.Fatal(err)
+	}
+	if string(a)+string(b)+string(c) != "a.......!" {
+		t.Fatalf("data must be filled across the seam, %q found", string(a)+string(b)+string(c))
+	}
+}
+
+// TestBounds tests the bounds accessors.
+// CASE 1: The offset and the length MUST describe the segment.
+// CASE 2: The ranges within the segment MUST be contained.
+// CASE 3: The ranges outside the segment MUST NOT be contained.
+func TestBounds(t *testing.T) {
+	seg := New(100, make([]byte, 8))
+	if seg.Offset() != 100 || seg.Len() != 8 {
+		t.Fatalf("segment must be of 8 bytes at offset 100, %d bytes at offset %d found", seg.Len(), seg.Offset())
+	}
+	if chain := NewChain(5, make([]byte, 3), make([]byte, 4)); chain.Len() != 7 {
+		t.Fatalf("chained segment must be of 7 bytes, %d found", chain.Len())
+	}
+	for _, c := range []struct {
+		offset   int64
+		length   uintptr
+		expected bool
+	}{
+		{100, 8, true},
+		{104, 4, true},
+		{108, 0, true},
+		{99, 1, false},
+		{105, 4, false},
+		{109, 0, false},
+		{100, MaxUintptr, false},
+	} {
+		if seg.Contains(c.offset, c.length) != c.expected {
+			t.Fatalf("containment of %d bytes at offset %d must be %v", c.length, c.offset, c.expected)
+		}
+	}
+}
+
+// TestArena tests the arena.
+// CASE 1: The allocated blocks MUST be aligned and MUST NOT overlap.
+// CASE 2: The freed block MUST be reused.
+// CASE 3: The arena MUST be reopened with its blocks.
+// CASE 4: The allocation which does not fit the arena MUST fail.
+func TestArena(t *testing.T) {
+	data := make([]byte, 256)
+	if _, err := OpenArena(New(1000, data)); err != ErrBadValue {
+		t.Fatalf("unformatted arena must not be opened, %v found", err)
+	}
+	arena, err := NewArena(New(1000, data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := arena.Alloc(10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := arena.Alloc(20, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Len() != 10 || second.Len() != 20 {
+		t.Fatalf("blocks must be of 10 and 20 bytes, %d and %d found", first.Len(), second.Len())
+	}
+	if (first.Offset()-1000)%8 != 0 || (second.Offset()-1000)%64 != 0 {
+		t.Fatalf("blocks must be aligned, %d and %d found", first.Offset(), second.Offset())
+	}
+	if first.Offset()+first.Len() > second.Offset() {
+		t.Fatalf("blocks must not overlap, %d and %d found", first.Offset(), second.Offset())
+	}
+	if _, err := second.WriteAt([]byte("persistent"), second.Offset()); err != nil {
+		t.Fatal(err)
+	}
+	if err := arena.Free(first.Offset()); err != nil {
+		t.Fatal(err)
+	}
+	if err := arena.Free(first.Offset()); err != ErrBadOffset {
+		t.Fatalf("freed block must not be freed again, %v found", err)
+	}
+	third, err := arena.Alloc(16, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.Offset() != first.Offset() {
+		t.Fatalf("freed block must be reused at offset %d, %d found", first.Offset(), third.Offset())
+	}
+	arena, err = OpenArena(New(1000, data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := arena.Block(second.Offset())
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 10)
+	if _, err := block.ReadAt(buf, second.Offset()); err != nil || string(buf) != "persistent" {
+		t.Fatalf("block data must be %q, %q (%v) found", "persistent", buf, err)
+	}
+	if _, err := arena.Block(second.Offset() + 8); err != ErrBadOffset {
+		t.Fatalf("block must not be found at the wrong offset, %v found", err)
+	}
+	if _, err := arena.Alloc(256, 8); err != ErrNoSpace {
+		t.Fatalf("allocation must not exceed the arena, %v found", err)
+	}
+	if _, err := arena.Alloc(8, 3); err != ErrBadValue {
+		t.Fatalf("alignment must be the power of two, %v found", err)
+	}
+}
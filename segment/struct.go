@@ -0,0 +1,208 @@
+package segment
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+)
+
+// ReadStruct reads the data at the given offset from this segment into the value pointed by v
+// and returns the number of bytes read.
+// Like encoding/binary, the value must be of the fixed-size type: the boolean, the sized number,
+// or the array or struct of them, which is laid out packed without padding in the byte order of this segment.
+// The blank fields of the struct are skipped, the unexported ones are not allowed.
+// The layout of the fields may be declared by the struct tags, see TagName.
+// The ErrBadValue returns if v is not a pointer to the fixed-size value,
+// the ErrOutOfBounds returns and nothing is read if the value does not fit this segment.
+func (seg *Segment) ReadStruct(offset int64, v interface{}) (int, error) {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return 0, ErrBadValue
+	}
+	value = value.Elem()
+	size := dataSize(value.Type())
+	if size < 0 {
+		return 0, ErrBadValue
+	}
+	if offset < seg.offset {
+		return 0, ErrOutOfBounds
+	}
+	if size == 0 {
+		return 0, nil
+	}
+	data, err := seg.read(offset-seg.offset, size)
+	if err != nil {
+		return 0, err
+	}
+	decodeValue(seg.order, data, value)
+	return int(size), nil
+}
+
+// WriteStruct writes the value v at the given offset to this segment and returns the number of bytes written.
+// The value is laid out like by ReadStruct, the bytes of the blank fields and of the gaps are left intact.
+// The ErrBadValue returns if v is not the fixed-size value or a pointer to it,
+// the ErrOutOfBounds returns and nothing is written if the value does not fit this segment.
+func (seg *Segment) WriteStruct(offset int64, v interface{}) (int, error) {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if !value.IsValid() {
+		return 0, ErrBadValue
+	}
+	size := dataSize(value.Type())
+	if size < 0 {
+		return 0, ErrBadValue
+	}
+	if offset < seg.offset {
+		return 0, ErrOutOfBounds
+	}
+	if size == 0 {
+		return 0, nil
+	}
+	data, err := seg.write(offset-seg.offset, size)
+	if err != nil {
+		return 0, err
+	}
+	encodeValue(seg.order, data, value)
+	return int(size), nil
+}
+
+// dataSize returns the size in bytes of the packed value of the given type or -1 if the type is not fixed-size.
+func dataSize(typ reflect.Type) int64 {
+	switch typ.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		return 1
+	case reflect.Int16, reflect.Uint16:
+		return 2
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		return 4
+	case reflect.Int64, reflect.Uint64, reflect.Float64, reflect.Complex64:
+		return 8
+	case reflect.Complex128:
+		return 16
+	case reflect.Array:
+		elem := dataSize(typ.Elem())
+		if elem < 0 || (elem > 0 && int64(typ.Len()) > math.MaxInt64/elem) {
+			return -1
+		}
+		return elem * int64(typ.Len())
+	case reflect.Struct:
+		return layoutOf(typ).size
+	}
+	return -1
+}
+
+// decodeValue decodes the given packed data in the given byte order into the given settable value.
+func decodeValue(order binary.ByteOrder, data []byte, value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Bool:
+		value.SetBool(data[0] != 0)
+	case reflect.Int8:
+		value.SetInt(int64(int8(data[0])))
+	case reflect.Int16:
+		value.SetInt(int64(int16(order.Uint16(data))))
+	case reflect.Int32:
+		value.SetInt(int64(int32(order.Uint32(data))))
+	case reflect.Int64:
+		value.SetInt(int64(order.Uint64(data)))
+	case reflect.Uint8:
+		value.SetUint(uint64(data[0]))
+	case reflect.Uint16:
+		value.SetUint(uint64(order.Uint16(data)))
+	case reflect.Uint32:
+		value.SetUint(uint64(order.Uint32(data)))
+	case reflect.Uint64:
+		value.SetUint(order.Uint64(data))
+	case reflect.Float32:
+		value.SetFloat(float64(math.Float32frombits(order.Uint32(data))))
+	case reflect.Float64:
+		value.SetFloat(math.Float64frombits(order.Uint64(data)))
+	case reflect.Complex64:
+		value.SetComplex(complex(
+			float64(math.Float32frombits(order.Uint32(data))),
+			float64(math.Float32frombits(order.Uint32(data[Float32Size:]))),
+		))
+	case reflect.Complex128:
+		value.SetComplex(complex(
+			math.Float64frombits(order.Uint64(data)),
+			math.Float64frombits(order.Uint64(data[Float64Size:])),
+		))
+	case reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			reflect.Copy(value, reflect.ValueOf(data[:value.Len()]))
+			return
+		}
+		size := dataSize(value.Type().Elem())
+		for i := 0; i < value.Len(); i++ {
+			decodeValue(order, data[int64(i)*size:], value.Index(i))
+		}
+	case reflect.Struct:
+		for _, f := range layoutOf(value.Type()).fields {
+			fieldOrder := order
+			if f.order != nil {
+				fieldOrder = f.order
+			}
+			fieldData := data[f.offset : f.offset+f.size]
+			if f.width != 0 {
+				decodeInt(fieldOrder, fieldData, value.Field(f.index))
+			} else {
+				decodeValue(fieldOrder, fieldData, value.Field(f.index))
+			}
+		}
+	}
+}
+
+// encodeValue encodes the given value into the given packed data in the given byte order.
+func encodeValue(order binary.ByteOrder, data []byte, value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Bool:
+		data[0] = 0
+		if value.Bool() {
+			data[0] = 1
+		}
+	case reflect.Int8, reflect.Uint8:
+		data[0] = uint8(intBits(value))
+	case reflect.Int16, reflect.Uint16:
+		order.PutUint16(data, uint16(intBits(value)))
+	case reflect.Int32, reflect.Uint32:
+		order.PutUint32(data, uint32(intBits(value)))
+	case reflect.Int64, reflect.Uint64:
+		order.PutUint64(data, intBits(value))
+	case reflect.Float32:
+		order.PutUint32(data, math.Float32bits(float32(value.Float())))
+	case reflect.Float64:
+		order.PutUint64(data, math.Float64bits(value.Float()))
+	case reflect.Complex64:
+		c := value.Complex()
+		order.PutUint32(data, math.Float32bits(float32(real(c))))
+		order.PutUint32(data[Float32Size:], math.Float32bits(float32(imag(c))))
+	case reflect.Complex128:
+		c := value.Complex()
+		order.PutUint64(data, math.Float64bits(real(c)))
+		order.PutUint64(data[Float64Size:], math.Float64bits(imag(c)))
+	case reflect.Array:
+		size := dataSize(value.Type().Elem())
+		for i := 0; i < value.Len(); i++ {
+			encodeValue(order, data[int64(i)*size:], value.Index(i))
+		}
+	case reflect.Struct:
+		for _, f := range layoutOf(value.Type()).fields {
+			fieldOrder := order
+			if f.order != nil {
+				fieldOrder = f.order
+			}
+			fieldData := data[f.offset : f.offset+f.size]
+			if f.width != 0 {
+				encodeInt(fieldOrder, fieldData, value.Field(f.index))
+			} else {
+				encodeValue(fieldOrder, fieldData, value.Field(f.index))
+			}
+		}
+	}
+}
+
+// intBits returns the bits of the given signed or unsigned integer value.
+func intBits(value reflect.Value) uint64 {
+	if value.CanInt() {
+		return uint64(value.Int())
+	}
+	return value.Uint()
+}
@@ -0,0 +1,66 @@
+package segment
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+)
+
+// SwapUint16s reverses the bytes of each of count unsigned 16-bit integers in place starting from the given offset,
+// so the array encoded in the foreign byte order may be converted once instead of at every access.
+// The ErrBadValue returns if the count is negative,
+// the ErrOutOfBounds returns and nothing is swapped if the array does not fit this segment.
+func (seg *Segment) SwapUint16s(offset int64, count int) error {
+	data, err := seg.swapped(offset, count, Uint16Size)
+	if err != nil || data == nil {
+		return err
+	}
+	for i := 0; i+Uint16Size <= len(data); i += Uint16Size {
+		binary.LittleEndian.PutUint16(data[i:], bits.ReverseBytes16(binary.LittleEndian.Uint16(data[i:])))
+	}
+	return nil
+}
+
+// SwapUint32s reverses the bytes of each of count unsigned 32-bit integers in place starting from the given offset,
+// see SwapUint16s.
+func (seg *Segment) SwapUint32s(offset int64, count int) error {
+	data, err := seg.swapped(offset, count, Uint32Size)
+	if err != nil || data == nil {
+		return err
+	}
+	for i := 0; i+Uint32Size <= len(data); i += Uint32Size {
+		binary.LittleEndian.PutUint32(data[i:], bits.ReverseBytes32(binary.LittleEndian.Uint32(data[i:])))
+	}
+	return nil
+}
+
+// SwapUint64s reverses the bytes of each of count unsigned 64-bit integers in place starting from the given offset,
+// see SwapUint16s.
+func (seg *Segment) SwapUint64s(offset int64, count int) error {
+	data, err := seg.swapped(offset, count, Uint64Size)
+	if err != nil || data == nil {
+		return err
+	}
+	for i := 0; i+Uint64Size <= len(data); i += Uint64Size {
+		binary.LittleEndian.PutUint64(data[i:], bits.ReverseBytes64(binary.LittleEndian.Uint64(data[i:])))
+	}
+	return nil
+}
+
+// swapped returns the data of count values of the given size at the given offset from this segment to be swapped
+// or nil if the count is zero.
+func (seg *Segment) swapped(offset int64, count int, size int64) ([]byte, error) {
+	if count < 0 {
+		return nil, ErrBadValue
+	}
+	if offset < seg.offset || int64(count) > math.MaxInt64/size {
+		return nil, ErrOutOfBounds
+	}
+	if count == 0 {
+		if offset-seg.offset > seg.size() {
+			return nil, ErrOutOfBounds
+		}
+		return nil, nil
+	}
+	return seg.write(offset-seg.offset, int64(count)*size)
+}
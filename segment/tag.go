@@ -0,0 +1,169 @@
+package segment
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// TagName is the name of the struct tag which declares the layout of the field for ReadStruct and WriteStruct.
+// The tag is the comma-separated list of the options:
+//
+//	off=N  the offset of the field in bytes from start of the struct, the gaps are skipped;
+//	       the untagged fields follow the end of the previous field
+//	len=N  the length of the field in bytes: the integer is encoded in N bytes from 1 to 8,
+//	       truncated on writing and zero or sign extended on reading, other values reserve N bytes at least
+//	be     the field is encoded in the big-endian byte order
+//	le     the field is encoded in the little-endian byte order
+//
+// The tag "-" excludes the field, so it may be unexported. For example:
+//
+//	type header struct {
+//		Magic  [4]byte `bio:"off=0"`
+//		Length uint32  `bio:"off=16,len=3,be"`
+//	}
+const TagName = "bio"
+
+// fieldLayout is the layout of the struct field.
+type fieldLayout struct {
+	// index specifies the index of the field in the struct.
+	index int
+	// offset specifies the offset of the field in bytes from start of the struct.
+	offset int64
+	// size specifies the size of the field in bytes.
+	size int64
+	// width specifies the number of bytes which encode the integer field or zero for the natural size.
+	width int64
+	// order specifies the byte order of the field or nil for the inherited one.
+	order binary.ByteOrder
+}
+
+// structLayout is the layout of the struct.
+type structLayout struct {
+	// fields specifies the layouts of the encoded fields.
+	fields []fieldLayout
+	// size specifies the size of the struct in bytes or -1 if it is not fixed-size.
+	size int64
+}
+
+// structLayouts caches the layouts by the struct types.
+var structLayouts sync.Map
+
+// layoutOf returns the layout of the given struct type.
+func layoutOf(typ reflect.Type) *structLayout {
+	if layout, ok := structLayouts.Load(typ); ok {
+		return layout.(*structLayout)
+	}
+	layout := parseLayout(typ)
+	structLayouts.Store(typ, layout)
+	return layout
+}
+
+// parseLayout parses the layout of the given struct type from its fields and tags.
+func parseLayout(typ reflect.Type) *structLayout {
+	invalid := &structLayout{size: -1}
+	layout := &structLayout{}
+	var next int64
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get(TagName)
+		if tag == "-" {
+			continue
+		}
+		if field.Name != "_" && !field.IsExported() {
+			return invalid
+		}
+		size := dataSize(field.Type)
+		if size < 0 {
+			return invalid
+		}
+		f := fieldLayout{index: i, offset: next, size: size}
+		if tag != "" {
+			for _, option := range strings.Split(tag, ",") {
+				name, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+				switch name {
+				default:
+					return invalid
+				case "be":
+					f.order = binary.BigEndian
+				case "le":
+					f.order = binary.LittleEndian
+				case "off", "len":
+					n, err := strconv.ParseInt(value, 10, 64)
+					if err != nil || n < 0 {
+						return invalid
+					}
+					if name == "off" {
+						f.offset = n
+						continue
+					}
+					switch field.Type.Kind() {
+					case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+						reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+						if n < 1 || n > Uint64Size {
+							return invalid
+						}
+						f.width, f.size = n, n
+					default:
+						if n < size {
+							return invalid
+						}
+						f.size = n
+					}
+				}
+			}
+		}
+		if f.offset > math.MaxInt64-f.size {
+			return invalid
+		}
+		next = f.offset + f.size
+		if next > layout.size {
+			layout.size = next
+		}
+		if field.Name != "_" {
+			layout.fields = append(layout.fields, f)
+		}
+	}
+	return layout
+}
+
+// decodeInt decodes the integer of the given width in the given byte order into the given settable value.
+func decodeInt(order binary.ByteOrder, data []byte, value reflect.Value) {
+	var bits uint64
+	little := littleEndian(order)
+	for i := range data {
+		b := data[i]
+		if little {
+			b = data[len(data)-1-i]
+		}
+		bits = bits<<8 | uint64(b)
+	}
+	if value.CanInt() {
+		shift := 64 - 8*len(data)
+		value.SetInt(int64(bits<<shift) >> shift)
+		return
+	}
+	value.SetUint(bits)
+}
+
+// encodeInt encodes the given integer value in the given byte order into the given data of its width.
+func encodeInt(order binary.ByteOrder, data []byte, value reflect.Value) {
+	bits := intBits(value)
+	little := littleEndian(order)
+	for i := len(data) - 1; i >= 0; i-- {
+		if little {
+			data[len(data)-1-i] = byte(bits)
+		} else {
+			data[i] = byte(bits)
+		}
+		bits >>= 8
+	}
+}
+
+// littleEndian returns whether the given byte order is the little-endian one.
+func littleEndian(order binary.ByteOrder) bool {
+	return order.Uint16([]byte{1, 0}) == 1
+}
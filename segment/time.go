@@ -0,0 +1,84 @@
+package segment
+
+import (
+	"math"
+	"time"
+)
+
+// TimeEncoding is the encoding of the timestamp as the signed 64-bit integer.
+type TimeEncoding int
+
+const (
+	// TimeUnix encodes the timestamp as the number of seconds elapsed since January 1, 1970 UTC.
+	TimeUnix TimeEncoding = iota
+	// TimeUnixMilli encodes the timestamp as the number of milliseconds elapsed since January 1, 1970 UTC.
+	TimeUnixMilli
+	// TimeUnixMicro encodes the timestamp as the number of microseconds elapsed since January 1, 1970 UTC.
+	TimeUnixMicro
+	// TimeUnixNano encodes the timestamp as the number of nanoseconds elapsed since January 1, 1970 UTC.
+	TimeUnixNano
+)
+
+// Time returns the timestamp at the given offset from this segment which is decoded with the given encoding
+// from the signed 64-bit integer in the byte order of this segment. The returned time is in UTC.
+func (seg *Segment) Time(offset int64, enc TimeEncoding) (time.Time, error) {
+	if offset < seg.offset {
+		return time.Time{}, ErrOutOfBounds
+	}
+	data, err := seg.read(offset-seg.offset, Int64Size)
+	if err != nil {
+		return time.Time{}, err
+	}
+	v := int64(seg.order.Uint64(data))
+	switch enc {
+	case TimeUnix:
+		return time.Unix(v, 0).UTC(), nil
+	case TimeUnixMilli:
+		return time.UnixMilli(v).UTC(), nil
+	case TimeUnixMicro:
+		return time.UnixMicro(v).UTC(), nil
+	case TimeUnixNano:
+		return time.Unix(0, v).UTC(), nil
+	}
+	return time.Time{}, ErrBadValue
+}
+
+// PutTime writes the given timestamp at the given offset to this segment which is encoded with the given encoding
+// as the signed 64-bit integer in the byte order of this segment. The time is truncated to the encoding precision.
+// The ErrOverflow returns if the timestamp does not fit the encoding, e.g. the years before 1678 or after 2262
+// in nanoseconds.
+func (seg *Segment) PutTime(offset int64, t time.Time, enc TimeEncoding) error {
+	var v int64
+	switch enc {
+	default:
+		return ErrBadValue
+	case TimeUnix:
+		v = t.Unix()
+	case TimeUnixMilli, TimeUnixMicro, TimeUnixNano:
+		scale := int64(1e9)
+		switch enc {
+		case TimeUnixMilli:
+			scale = 1e3
+		case TimeUnixMicro:
+			scale = 1e6
+		}
+		sec, nsec := t.Unix(), int64(t.Nanosecond())
+		if sec > math.MaxInt64/scale || sec < math.MinInt64/scale {
+			return ErrOverflow
+		}
+		frac := nsec / (1e9 / scale)
+		if sec*scale > math.MaxInt64-frac {
+			return ErrOverflow
+		}
+		v = sec*scale + frac
+	}
+	if offset < seg.offset {
+		return ErrOutOfBounds
+	}
+	data, err := seg.write(offset-seg.offset, Int64Size)
+	if err != nil {
+		return err
+	}
+	seg.order.PutUint64(data, uint64(v))
+	return nil
+}
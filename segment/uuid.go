@@ -0,0 +1,59 @@
+package segment
+
+// UUIDSize is the size of the UUID in bytes.
+const UUIDSize = 16
+
+// UUID returns the UUID at the given offset from this segment which is stored
+// in the RFC 4122 network byte order.
+func (seg *Segment) UUID(offset int64) ([UUIDSize]byte, error) {
+	var uuid [UUIDSize]byte
+	if offset < seg.offset {
+		return uuid, ErrOutOfBounds
+	}
+	data, err := seg.read(offset-seg.offset, UUIDSize)
+	if err != nil {
+		return uuid, err
+	}
+	copy(uuid[:], data)
+	return uuid, nil
+}
+
+// PutUUID writes the given UUID at the given offset to this segment in the RFC 4122 network byte order.
+func (seg *Segment) PutUUID(offset int64, uuid [UUIDSize]byte) error {
+	if offset < seg.offset {
+		return ErrOutOfBounds
+	}
+	data, err := seg.write(offset-seg.offset, UUIDSize)
+	if err != nil {
+		return err
+	}
+	copy(data, uuid[:])
+	return nil
+}
+
+// GUID returns the UUID at the given offset from this segment which is stored in the mixed-endian layout
+// of the Microsoft GUID, where the first three fields are little-endian.
+// The returned UUID is in the RFC 4122 network byte order, so it is comparable with the ones returned by UUID.
+func (seg *Segment) GUID(offset int64) ([UUIDSize]byte, error) {
+	uuid, err := seg.UUID(offset)
+	if err != nil {
+		return uuid, err
+	}
+	swapGUID(&uuid)
+	return uuid, nil
+}
+
+// PutGUID writes the given UUID which is in the RFC 4122 network byte order at the given offset to this segment
+// in the mixed-endian layout of the Microsoft GUID.
+func (seg *Segment) PutGUID(offset int64, uuid [UUIDSize]byte) error {
+	swapGUID(&uuid)
+	return seg.PutUUID(offset, uuid)
+}
+
+// swapGUID converts the given UUID between the network byte order and the mixed-endian layout
+// by reversing the bytes of the 32-bit, the first 16-bit and the second 16-bit fields.
+func swapGUID(uuid *[UUIDSize]byte) {
+	uuid[0], uuid[1], uuid[2], uuid[3] = uuid[3], uuid[2], uuid[1], uuid[0]
+	uuid[4], uuid[5] = uuid[5], uuid[4]
+	uuid[6], uuid[7] = uuid[7], uuid[6]
+}
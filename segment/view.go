@@ -0,0 +1,64 @@
+package segment
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/alexeymaximov/go-bio/internal/race"
+)
+
+// View returns a live pointer to the value of the given fixed-layout type at the given offset from the given segment,
+// so the mapped headers may be manipulated as the Go structs. The value must fit the segment,
+// its address must be aligned by unsafe.Alignof of the type and the type must not contain pointers,
+// i.e. it consists of the booleans, the numbers and the arrays and structs of them.
+// The ErrOutOfBounds, the ErrMisaligned or the ErrBadValue returns otherwise.
+// The fields are accessed in the native byte order and the padding is laid out by the compiler.
+// The handout of the pointer is reported to the race detector as the read access to the viewed memory,
+// the writes through the pointer are not seen.
+func View[T any](seg *Segment, offset int64) (*T, error) {
+	var v T
+	size := int64(unsafe.Sizeof(v))
+	if !plain(reflect.TypeOf(&v).Elem()) {
+		return nil, ErrBadValue
+	}
+	if offset < seg.offset {
+		return nil, ErrOutOfBounds
+	}
+	if size == 0 {
+		if offset-seg.offset > seg.size() {
+			return nil, ErrOutOfBounds
+		}
+		return &v, nil
+	}
+	data, err := seg.span(offset-seg.offset, size)
+	if err != nil {
+		return nil, err
+	}
+	ptr := unsafe.Pointer(&data[0])
+	if uintptr(ptr)%unsafe.Alignof(v) != 0 {
+		return nil, ErrMisaligned
+	}
+	race.ReadRange(uintptr(ptr), uintptr(size))
+	return (*T)(ptr), nil
+}
+
+// plain returns whether the given type does not contain pointers, so its value may reside outside the Go heap.
+func plain(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Array:
+		return plain(typ.Elem())
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			if !plain(typ.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -7,3 +7,6 @@ var ErrClosed = fmt.Errorf("transaction: transaction closed")
 
 // ErrOutOfBounds is the error which returns when tries to accessing the offset which is out of the available bounds.
 var ErrOutOfBounds = fmt.Errorf("transaction: out of bounds")
+
+// ErrBadSavepoint is the error which returns when the given savepoint does not exist in the transaction.
+var ErrBadSavepoint = fmt.Errorf("transaction: bad savepoint")
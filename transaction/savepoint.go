@@ -0,0 +1,101 @@
+package transaction
+
+import "bytes"
+
+// undoBlockSize is the size of the blocks in bytes by which the changes of the snapshot are tracked.
+const undoBlockSize = 64
+
+// SavepointID is the identifier of the savepoint of the transaction.
+type SavepointID int
+
+// savepoint is the savepoint of the transaction.
+type savepoint struct {
+	// id specifies the identifier of this savepoint.
+	id SavepointID
+	// undo specifies the changes which turn the snapshot at this savepoint back to the previous savepoint.
+	undo []change
+}
+
+// change is the range of the snapshot with its data.
+type change struct {
+	// offset specifies the offset of the range from start of the snapshot.
+	offset int
+	// data specifies the data of the range.
+	data []byte
+}
+
+// Savepoint creates the savepoint of the current state of the snapshot and returns its identifier,
+// so the snapshot may be returned to this state by RollbackTo.
+// The first savepoint copies the snapshot, the next ones keep only the blocks changed since the previous one.
+// The zero identifier returns if this transaction is closed.
+func (tx *Tx) Savepoint() SavepointID {
+	if tx.snapshot == nil {
+		return 0
+	}
+	var undo []change
+	if tx.marked == nil {
+		tx.marked = make([]byte, len(tx.snapshot))
+		copy(tx.marked, tx.snapshot)
+	} else {
+		undo = tx.mark()
+	}
+	tx.lastID++
+	tx.savepoints = append(tx.savepoints, savepoint{id: tx.lastID, undo: undo})
+	return tx.lastID
+}
+
+// RollbackTo returns the snapshot to the state of the savepoint of the given identifier.
+// The savepoint is kept and all the savepoints created after it are released.
+// The ErrBadSavepoint returns if there is no such savepoint, e.g. it was released.
+func (tx *Tx) RollbackTo(id SavepointID) error {
+	if tx.snapshot == nil {
+		return ErrClosed
+	}
+	i := len(tx.savepoints) - 1
+	for i >= 0 && tx.savepoints[i].id != id {
+		i--
+	}
+	if i < 0 {
+		return ErrBadSavepoint
+	}
+	for j := len(tx.savepoints) - 1; j > i; j-- {
+		for _, c := range tx.savepoints[j].undo {
+			copy(tx.marked[c.offset:], c.data)
+		}
+	}
+	tx.savepoints = tx.savepoints[:i+1]
+	copy(tx.snapshot, tx.marked)
+	return nil
+}
+
+// mark returns the changes which turn the snapshot back to the copy at the last savepoint
+// and updates the copy to the snapshot.
+func (tx *Tx) mark() []change {
+	var undo []change
+	for start := 0; start < len(tx.snapshot); {
+		end := tx.block(start)
+		if bytes.Equal(tx.marked[start:end], tx.snapshot[start:end]) {
+			start = end
+			continue
+		}
+		for end < len(tx.snapshot) {
+			next := tx.block(end)
+			if bytes.Equal(tx.marked[end:next], tx.snapshot[end:next]) {
+				break
+			}
+			end = next
+		}
+		undo = append(undo, change{offset: start, data: append([]byte(nil), tx.marked[start:end]...)})
+		copy(tx.marked[start:end], tx.snapshot[start:end])
+		start = end
+	}
+	return undo
+}
+
+// block returns the end of the tracked block of the snapshot which starts at the given offset.
+func (tx *Tx) block(start int) int {
+	if end := start + undoBlockSize; end < len(tx.snapshot) {
+		return end
+	}
+	return len(tx.snapshot)
+}
@@ -10,6 +10,8 @@ import (
 
 // Tx is a transaction on the raw byte data.
 type Tx struct {
+	// parent specifies the transaction which this nested transaction belongs to or nil.
+	parent *Tx
 	// original specifies the raw byte data associated with this transaction.
 	original []byte
 	// lowOffset specifies the lowest offset, from start of the original,
@@ -22,13 +24,19 @@ type Tx struct {
 	snapshot []byte
 	// segment specifies the lazily initialized data segment on top of the snapshot.
 	segment *segment.Segment
+	// savepoints specifies the savepoints of this transaction in order of their creation.
+	savepoints []savepoint
+	// marked specifies the copy of the snapshot at the last savepoint.
+	marked []byte
+	// lastID specifies the identifier of the last created savepoint.
+	lastID SavepointID
 }
 
 // Begin starts and returns a new transaction.
 // The given raw byte data starting from the given offset and ends after the given length
 // copies to the snapshot which is allocated into the heap.
 func Begin(data []byte, offset int64, length uintptr) (*Tx, error) {
-	if length == 0 || length > math.MaxInt64 {
+	if length == 0 || uint64(length) > math.MaxInt64 {
 		return nil, ErrOutOfBounds
 	}
 	if offset < 0 || offset >= int64(len(data)) || offset > math.MaxInt64-int64(length) {
@@ -49,6 +57,35 @@ func Begin(data []byte, offset int64, length uintptr) (*Tx, error) {
 	return tx, nil
 }
 
+// Begin starts and returns a new transaction nested into this one.
+// The given range of the snapshot of this transaction starting from the given offset from start of the original
+// and ends after the given length copies to the snapshot of the nested transaction.
+// Committing the nested transaction flushes its snapshot to the snapshot of this one and rolling it back
+// discards it, both leave this transaction open, so the multi-step update may be partially undone.
+// The nested transaction must be finished before this one, otherwise its commit returns ErrClosed.
+func (tx *Tx) Begin(offset int64, length uintptr) (*Tx, error) {
+	if tx.snapshot == nil {
+		return nil, ErrClosed
+	}
+	if length == 0 || uint64(length) > uint64(len(tx.snapshot)) {
+		return nil, ErrOutOfBounds
+	}
+	off, err := tx.offset(offset, int(length))
+	if err != nil {
+		return nil, err
+	}
+	child := &Tx{
+		parent:     tx,
+		original:   tx.snapshot,
+		lowOffset:  offset,
+		highOffset: offset + int64(length),
+		snapshot:   make([]byte, length),
+	}
+	copy(child.snapshot, tx.snapshot[off:])
+	runtime.SetFinalizer(child, (*Tx).Rollback)
+	return child, nil
+}
+
 // Segment returns the data segment on top of the snapshot.
 func (tx *Tx) Segment() *segment.Segment {
 	if tx.segment == nil {
@@ -64,7 +101,7 @@ func (tx *Tx) offset(offset int64, length int) (int64, error) {
 		return 0, ErrOutOfBounds
 	}
 	offset -= tx.lowOffset
-	if offset > math.MaxInt64-int64(length) || offset+int64(length) > tx.highOffset {
+	if offset > math.MaxInt64-int64(length) || offset+int64(length) > tx.highOffset-tx.lowOffset {
 		return 0, ErrOutOfBounds
 	}
 	return offset, nil
@@ -102,12 +139,22 @@ func (tx *Tx) WriteAt(buf []byte, offset int64) (int, error) {
 
 // Commit flushes the snapshot to the original, closes this transaction
 // and frees all resources associated with it.
+// The snapshot of the nested transaction is flushed to the snapshot of its parent,
+// the ErrClosed returns and nothing is flushed if the parent is already closed.
 func (tx *Tx) Commit() error {
 	if tx.snapshot == nil {
 		return ErrClosed
 	}
-	copy(tx.original[tx.lowOffset:tx.highOffset], tx.snapshot)
-	tx.snapshot = nil
+	if tx.parent != nil {
+		if tx.parent.snapshot == nil {
+			tx.close()
+			return ErrClosed
+		}
+		copy(tx.original[tx.lowOffset-tx.parent.lowOffset:], tx.snapshot)
+	} else {
+		copy(tx.original[tx.lowOffset:tx.highOffset], tx.snapshot)
+	}
+	tx.close()
 	return nil
 }
 
@@ -116,6 +163,13 @@ func (tx *Tx) Rollback() error {
 	if tx.snapshot == nil {
 		return ErrClosed
 	}
-	tx.snapshot = nil
+	tx.close()
 	return nil
 }
+
+// close closes this transaction and frees all resources associated with it.
+func (tx *Tx) close() {
+	tx.snapshot = nil
+	tx.savepoints = nil
+	tx.marked = nil
+}
@@ -119,3 +119,135 @@ func TestPartialWrite(t *testing.T) {
 		t.Fatalf("data must be %q, %v found", zeroBuffer, partBuf)
 	}
 }
+
+// TestNested tests the nested transaction.
+// CASE 1: The nested transaction MUST NOT access the data outside its range.
+// CASE 2: The parent snapshot MUST NOT be affected by the rolled back nested transaction.
+// CASE 3: The parent snapshot MUST be affected by the committed nested transaction but the original MUST NOT.
+// CASE 4: The nested transaction MUST NOT be committed after its parent is closed.
+func TestNested(t *testing.T) {
+	data := make([]byte, testBufferLength)
+	tx, err := Begin(data, 0, uintptr(testBufferLength))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.WriteAt(testBuffer, 0); err != nil {
+		t.Fatal(err)
+	}
+	child, err := tx.Begin(1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := child.WriteAt([]byte("abc"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := child.WriteAt([]byte("abc"), 2); err != ErrOutOfBounds {
+		t.Fatalf("expected ErrOutOfBounds, [%v] error found", err)
+	}
+	if err := child.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := make([]byte, testBufferLength)
+	if _, err := tx.ReadAt(snapshot, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(snapshot, testBuffer) != 0 {
+		t.Fatalf("snapshot must be %q, %q found", testBuffer, snapshot)
+	}
+	if child, err = tx.Begin(1, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := child.WriteAt([]byte("abc"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := child.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ReadAt(snapshot, 0); err != nil {
+		t.Fatal(err)
+	}
+	if string(snapshot) != "HabcO" {
+		t.Fatalf("snapshot must be %q, %q found", "HabcO", snapshot)
+	}
+	if bytes.Compare(data, zeroBuffer) != 0 {
+		t.Fatalf("original must be %q, %v found", zeroBuffer, data)
+	}
+	if child, err = tx.Begin(3, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "HabcO" {
+		t.Fatalf("original must be %q, %q found", "HabcO", data)
+	}
+	if err := child.Commit(); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, [%v] error found", err)
+	}
+}
+
+// TestSavepoint tests the savepoints.
+// CASE 1: The snapshot MUST be returned to the state of the savepoint.
+// CASE 2: The savepoints created after the one rolled back to MUST be released.
+// CASE 3: The savepoint MUST be kept after the rollback to it.
+func TestSavepoint(t *testing.T) {
+	data := make([]byte, 200)
+	tx, err := Begin(data, 0, uintptr(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	write := func(offset int64, s string) {
+		if _, err := tx.WriteAt([]byte(s), offset); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expect := func(expected []byte) {
+		snapshot := make([]byte, len(data))
+		if _, err := tx.ReadAt(snapshot, 0); err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Compare(snapshot, expected) != 0 {
+			t.Fatalf("snapshot must be %q, %q found", expected, snapshot)
+		}
+	}
+	write(0, "A")
+	first := tx.Savepoint()
+	expected := make([]byte, len(data))
+	copy(expected, tx.snapshot)
+	write(100, "B")
+	second := tx.Savepoint()
+	write(150, "C")
+	write(0, "Z")
+	if err := tx.RollbackTo(first); err != nil {
+		t.Fatal(err)
+	}
+	expect(expected)
+	if err := tx.RollbackTo(second); err != ErrBadSavepoint {
+		t.Fatalf("expected ErrBadSavepoint, [%v] error found", err)
+	}
+	write(199, "D")
+	third := tx.Savepoint()
+	if third == second {
+		t.Fatal("savepoint identifiers must not be reused")
+	}
+	copy(expected, tx.snapshot)
+	write(64, "E")
+	if err := tx.RollbackTo(third); err != nil {
+		t.Fatal(err)
+	}
+	expect(expected)
+	if err := tx.RollbackTo(first); err != nil {
+		t.Fatal(err)
+	}
+	expected[199] = 0
+	expect(expected)
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if tx.Savepoint() != 0 {
+		t.Fatal("savepoint must not be created in the closed transaction")
+	}
+	if err := tx.RollbackTo(first); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, [%v] error found", err)
+	}
+}